@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"rec-vendor-api/internal/configschema"
+)
+
+func main() {
+	out := flag.String("out", "config-template", "directory of the generated config.schema.json and vendors.schema.json")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: config-schema [-out dir]")
+		fmt.Fprintln(os.Stderr, "Example: config-schema -out config-template")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files, err := configschema.Generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate the schemas: %v\n", err)
+		os.Exit(1)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(*out, name)
+		if err := os.WriteFile(path, files[name], 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %s\n", path)
+	}
+}
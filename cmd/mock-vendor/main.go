@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"rec-vendor-api/internal/mockvendor"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "listen address")
+	products := flag.Int("products", 10, "number of products per response")
+	productBaseURL := flag.String("product-base-url", "https://mock-vendor.local", "base URL of the product links and images")
+	latency := flag.Duration("latency", 0, "default latency of every response")
+	status := flag.Int("status", 0, "default HTTP status of every response, 0 for 200")
+	empty := flag.Bool("empty", false, "serve no products by default")
+	malformed := flag.Bool("malformed", false, "serve malformed bodies by default")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mock-vendor [flags]")
+		fmt.Fprintln(os.Stderr, "Serves /coupang, /adpacker, /replace, /wrapped, /keeta and /adforus.")
+		fmt.Fprintln(os.Stderr, "The Keeta and Replace signatures are verified when MOCK_KEETA_SECRET and MOCK_REPLACE_SECRET are set,")
+		fmt.Fprintln(os.Stderr, "along with the optional MOCK_KEETA_APP and MOCK_REPLACE_ACCESS_KEY.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	server := mockvendor.NewServer(mockvendor.Config{
+		Products:         *products,
+		ProductBaseURL:   *productBaseURL,
+		KeetaApp:         os.Getenv("MOCK_KEETA_APP"),
+		KeetaSecret:      os.Getenv("MOCK_KEETA_SECRET"),
+		ReplaceAccessKey: os.Getenv("MOCK_REPLACE_ACCESS_KEY"),
+		ReplaceSecret:    os.Getenv("MOCK_REPLACE_SECRET"),
+		Default:          mockvendor.Scenario{Latency: *latency, Status: *status, Empty: *empty, Malformed: *malformed},
+	})
+	httpServer := &http.Server{Addr: *addr, Handler: server, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		log.Infof("Mock vendor listening on %s", *addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Mock vendor failed. err: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Errorf("Mock vendor shutdown failed. err: %v", err)
+	}
+}
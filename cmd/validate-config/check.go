@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	urlpkg "net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+	customerrors "rec-vendor-api/internal/controller/errors"
+	"rec-vendor-api/internal/strategy"
+	"rec-vendor-api/internal/strategy/body"
+	"rec-vendor-api/internal/strategy/header"
+	"rec-vendor-api/internal/strategy/url"
+)
+
+// issue is a validation error at a line of the config file, the line is 0 when unknown
+type issue struct {
+	line   int
+	vendor string
+	msg    string
+}
+
+func (i issue) format(path string) string {
+	s := path
+	if i.line > 0 {
+		s = fmt.Sprintf("%s:%d", path, i.line)
+	}
+	if i.vendor != "" {
+		return fmt.Sprintf("%s: vendor %s: %s", s, i.vendor, i.msg)
+	}
+	return fmt.Sprintf("%s: %s", s, i.msg)
+}
+
+var vendorNameRegExp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// landingMacros resolve to the product landing page, one of them must be in the tracking template
+var landingMacros = []string{"{product_url}"}
+
+// validateVendors checks the vendors beyond their validate tags, the issues are sorted by line
+func validateVendors(f *vendorsFile, proxyURL string) []issue {
+	if len(f.cfg.Vendors) == 0 {
+		return []issue{{msg: "no vendors found in configuration"}}
+	}
+
+	issues := validateStruct(f)
+	issues = append(issues, validateNames(f)...)
+	for i, v := range f.cfg.Vendors {
+		c := vendorChecker{file: f, idx: i, vendor: v}
+		c.checkMacros()
+		c.checkURL("request", v.Request, defaultSampleRequest.urlParams())
+		c.checkURL("tracking", v.Tracking, defaultSampleRequest.trackingParams())
+		c.checkLanding()
+		c.checkCredentials()
+		c.checkReferences()
+		c.checkBody()
+		c.checkProxy(proxyURL)
+		issues = append(issues, c.issues...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].line < issues[j].line })
+	return issues
+}
+
+func validateNames(f *vendorsFile) []issue {
+	var issues []issue
+	seen := map[string]int{}
+	for i, v := range f.cfg.Vendors {
+		field := fmt.Sprintf("vendors[%d].name", i)
+		line := f.line(field)
+		if !vendorNameRegExp.MatchString(v.Name) {
+			issues = append(issues, issue{line: line, vendor: v.Name, msg: fmt.Sprintf("invalid name %q, expected lowercase letters, digits and underscores", v.Name)})
+			continue
+		}
+		if first, ok := seen[v.Name]; ok {
+			issues = append(issues, issue{line: line, vendor: v.Name, msg: fmt.Sprintf("duplicate name, first defined at line %d", first)})
+			continue
+		}
+		seen[v.Name] = line
+	}
+	return issues
+}
+
+type vendorChecker struct {
+	file   *vendorsFile
+	idx    int
+	vendor config.Vendor
+	issues []issue
+}
+
+func (c *vendorChecker) addf(field, format string, a ...any) {
+	c.issues = append(c.issues, issue{
+		line:   c.file.line(fmt.Sprintf("vendors[%d].%s", c.idx, field)),
+		vendor: c.vendor.Name,
+		msg:    fmt.Sprintf(format, a...),
+	})
+}
+
+func (c *vendorChecker) checkMacros() {
+	check := func(field, text string) {
+		if strings.HasPrefix(text, "${") {
+			// a reference resolved at load time, checked by checkReferences
+			return
+		}
+		for _, macro := range url.MacroRegExp.FindAllString(text, -1) {
+			// Use the actual URL strategy to validate macros, this ensures consistency with runtime behavior.
+			// Errors other than unknown macros are about missing params, which we don't care about here.
+			if _, err := (&url.Default{}).GetMacroValue(macro, url.Params{}); errors.Is(err, customerrors.ErrUnknownMacro) {
+				c.addf(field, "unsupported macro %s in %s", macro, field)
+			}
+		}
+	}
+	for _, name := range []string{"request", "tracking"} {
+		pattern := c.pattern(name)
+		check(name+".url", pattern.URL)
+		for i, query := range pattern.Queries {
+			check(fmt.Sprintf("%s.queries[%d].value", name, i), query.Value)
+		}
+	}
+}
+
+func (c *vendorChecker) pattern(name string) config.URLPattern {
+	if name == "request" {
+		return c.vendor.Request
+	}
+	return c.vendor.Tracking
+}
+
+// checkURL checks that the URL parses once its macros are substituted with sample values, the tracking
+// URLs only have the params of a product
+func (c *vendorChecker) checkURL(name string, pattern config.URLPattern, params url.Params) {
+	field := name + ".url"
+	if pattern.URL == "" {
+		c.addf(field, "%s is required", field)
+		return
+	}
+	generated, err := (&url.Default{Sealer: previewSealer}).GenerateURL(pattern, params)
+	if errors.Is(err, customerrors.ErrUnknownMacro) {
+		// already reported by checkMacros
+		return
+	}
+	if err != nil {
+		c.addf(field, "%s does not generate a valid URL: %v", field, err)
+		return
+	}
+	parsed, err := urlpkg.Parse(generated)
+	if err != nil {
+		c.addf(field, "%s does not generate a valid URL: %v", field, err)
+		return
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		c.addf(field, "%s generates %q, expected an absolute http(s) URL", field, generated)
+	}
+}
+
+// checkLanding checks that the tracking URL leads to the product
+func (c *vendorChecker) checkLanding() {
+	texts := []string{c.vendor.Tracking.URL}
+	for _, query := range c.vendor.Tracking.Queries {
+		texts = append(texts, query.Value)
+	}
+	for _, text := range texts {
+		for _, macro := range landingMacros {
+			if strings.Contains(text, macro) {
+				return
+			}
+		}
+	}
+	c.addf("tracking", "tracking must contain a landing macro (%s) in its url or queries", strings.Join(landingMacros, ", "))
+}
+
+// checkCredentials checks the credentials required by the header strategy of the vendor
+func (c *vendorChecker) checkCredentials() {
+	var required map[string]string
+	switch strategy.BuildHeader(c.vendor).(type) {
+	case *header.ReplaceHeader:
+		required = map[string]string{"access_key": c.vendor.AccessKey.Value(), "secret_key": c.vendor.SecretKey.Value()}
+	case *header.KeetaHeader:
+		required = map[string]string{"s_ca_app": c.vendor.SCaApp.Value(), "s_ca_secret": c.vendor.SCaSecret.Value()}
+	}
+	keys := make([]string, 0, len(required))
+	for key := range required {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if required[key] == "" {
+			c.addf(key, "%s is required by the %s header strategy", key, c.vendor.Name)
+		}
+	}
+}
+
+// checkReferences checks the syntax of the ${env:...} and ${file:...} references, they are resolved by the
+// service only, where the environment and the secret files are
+func (c *vendorChecker) checkReferences() {
+	values := map[string]string{
+		"access_key":      c.vendor.AccessKey.Value(),
+		"secret_key":      c.vendor.SecretKey.Value(),
+		"channel_token":   c.vendor.ChannelToken.Value(),
+		"s_ca_app":        c.vendor.SCaApp.Value(),
+		"s_ca_secret":     c.vendor.SCaSecret.Value(),
+		"postback.secret": c.vendor.Postback.Secret.Value(),
+	}
+	for _, name := range []string{"request", "tracking"} {
+		for i, query := range c.pattern(name).Queries {
+			values[fmt.Sprintf("%s.queries[%d].value", name, i)] = query.Value
+		}
+	}
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if err := config.CheckReference(values[field]); err != nil {
+			c.addf(field, "%s: %v", field, err)
+		}
+	}
+}
+
+func (c *vendorChecker) checkBody() {
+	if c.vendor.HTTPMethod != "POST" {
+		return
+	}
+	if _, ok := strategy.BuildBody(c.vendor).(*body.NoBody); ok {
+		c.addf("http_method", "POST vendors require a body strategy, none is registered for %s", c.vendor.Name)
+	}
+}
+
+// checkProxy checks that with_proxy vendors have a proxy to go through, from the vendors file or -config
+func (c *vendorChecker) checkProxy(proxyURL string) {
+	if !c.vendor.WithProxy {
+		return
+	}
+	if proxyURL == "" {
+		c.addf("with_proxy", "with_proxy requires vendor_config.proxy_url, set it or pass the rendered config with -config")
+		return
+	}
+	if strings.HasPrefix(proxyURL, "${") {
+		if err := config.CheckReference(proxyURL); err != nil {
+			c.addf("with_proxy", "vendor_config.proxy_url: %v", err)
+		}
+		return
+	}
+	if parsed, err := urlpkg.Parse(proxyURL); err != nil || parsed.Host == "" {
+		c.addf("with_proxy", "with_proxy requires a valid vendor_config.proxy_url")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lineIndex finds the YAML line of a config field from its path, such as vendors[2].request.url. A missing
+// field resolves to the line of its closest parent, e.g. the vendor item for a missing credential.
+type lineIndex struct {
+	root *yaml.Node
+}
+
+func newLineIndex(data []byte) (lineIndex, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return lineIndex{}, err
+	}
+	if len(doc.Content) == 0 {
+		return lineIndex{root: &doc}, nil
+	}
+	return lineIndex{root: doc.Content[0]}, nil
+}
+
+func (l lineIndex) line(path string) int {
+	node := l.root
+	if node == nil {
+		return 0
+	}
+	for _, segment := range splitPath(path) {
+		next := child(node, segment)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node.Line
+}
+
+// splitPath splits vendors[2].request.url into vendors, 2, request and url
+func splitPath(path string) []string {
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	var segments []string
+	for _, s := range strings.Split(path, ".") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+func child(node *yaml.Node, segment string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		idx, err := strconv.Atoi(segment)
+		if err == nil && idx >= 0 && idx < len(node.Content) {
+			return node.Content[idx]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+// VendorsOnlyConfig represents a config with only vendors section
+type VendorsOnlyConfig struct {
+	ProxyURL string          `mapstructure:"proxy_url"`
+	Vendors  []config.Vendor `mapstructure:"vendors" validate:"dive"`
+}
+
+// vendorsFile is a loaded vendors.yaml, or the vendor_config section of a full config, with the lines of
+// its fields
+type vendorsFile struct {
+	path   string
+	prefix string
+	cfg    VendorsOnlyConfig
+	lines  lineIndex
+}
+
+// line returns the line of a field path relative to the vendors section, such as vendors[2].request.url
+func (f *vendorsFile) line(field string) int {
+	return f.lines.line(f.prefix + field)
+}
+
+func loadVendorConfig(configPath string) (*vendorsFile, error) {
+	// Check if file exists
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("config file does not exist: %s", configPath)
+	}
+	if path.Ext(configPath) != ".yaml" {
+		return nil, fmt.Errorf("only accept .yaml file")
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	lines, err := newLineIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	f := &vendorsFile{path: configPath, lines: lines}
+	// a full config has its vendors under vendor_config
+	if v.IsSet("vendor_config") {
+		v = v.Sub("vendor_config")
+		f.prefix = "vendor_config."
+	}
+	if err := v.Unmarshal(&f.cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return f, nil
+}
+
+// loadProxyURL reads vendor_config.proxy_url from a rendered config
+func loadProxyURL(configPath string) (string, error) {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	if err := v.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	return v.GetString("vendor_config.proxy_url"), nil
+}
+
+var indexRegExp = regexp.MustCompile(`^vendors\[(\d+)\]`)
+
+// validateStruct runs the validate tags of the config structs, reporting the errors at their YAML line
+func validateStruct(f *vendorsFile) []issue {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		return name
+	})
+
+	err := validate.Struct(f.cfg)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		if err != nil {
+			return []issue{{msg: err.Error()}}
+		}
+		return nil
+	}
+
+	issues := make([]issue, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		// the namespace starts with the struct name, e.g. VendorsOnlyConfig.vendors[2].http_method
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		is := issue{line: f.line(field), msg: fmt.Sprintf("%s failed on the %q rule", field, fe.Tag())}
+		if m := indexRegExp.FindStringSubmatch(field); m != nil {
+			idx, _ := strconv.Atoi(m[1])
+			is.vendor = f.cfg.Vendors[idx].Name
+		}
+		issues = append(issues, is)
+	}
+	return issues
+}
@@ -1,158 +1,102 @@
 package main
 
 import (
-	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"path"
-	"rec-vendor-api/internal/config"
-	customerrors "rec-vendor-api/internal/controller/errors"
-	"rec-vendor-api/internal/strategy/url"
-	"strings"
-
-	"github.com/go-playground/validator/v10"
-	"github.com/spf13/viper"
+	"time"
 )
 
-// VendorsOnlyConfig represents a config with only vendors section
-type VendorsOnlyConfig struct {
-	Vendors []config.Vendor `mapstructure:"vendors" validate:"dive"`
-}
-
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: validate_config <vendors.yaml>")
-		fmt.Println("Example: validate_config ./deploy/rec-vendor-api/secrets/vendors.yaml")
-		os.Exit(1)
+	configPath := flag.String("config", "", "rendered config.yaml to read vendor_config.proxy_url from, when validating a vendors-only file")
+	previewMode := flag.Bool("preview", false, "print the request URL, headers, body and tracking URLs of every vendor for a sample request")
+	samplePath := flag.String("sample", "", "preview: JSON file of the sample request, e.g. {\"user_id\": \"...\", \"w\": 300}, overridden by the flags")
+	var checks responseChecks
+	flag.Var(&checks, "check-response", "parse a sample response with the unmarshaler of a vendor, as vendor=path.json, repeatable")
+	sample := defaultSampleRequest
+	sample.registerFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Println("Usage: validate_config [-config config.yaml] [-preview [-sample sample.json] [-user_id ...]] [-check-response vendor=response.json] <vendors.yaml>")
+		fmt.Println("Example: validate_config -config ./deploy/rec-vendor-api/secrets/config.yaml ./deploy/rec-vendor-api/secrets/vendors.yaml")
+		flag.PrintDefaults()
 	}
-
-	configPath := os.Args[1]
-
-	cfg := &VendorsOnlyConfig{}
-
-	// Use the existing config loader
-	err := loadVendorConfig(configPath, cfg)
-	if err != nil {
-		fmt.Printf("❌ Configuration validation failed: %v\n", err)
+	flag.Parse()
+	if *samplePath != "" {
+		if err := sample.load(*samplePath); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		// parse again, so that the flags override the sample file
+		_ = flag.CommandLine.Parse(os.Args[1:])
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
 		os.Exit(1)
 	}
 
-	// Validate the loaded configuration for supported macros only
-	err = validateVendors(cfg.Vendors)
+	vendorsPath := flag.Arg(0)
+	f, err := loadVendorConfig(vendorsPath)
 	if err != nil {
-		fmt.Printf("❌ Macro validation failed: %v\n", err)
+		fmt.Printf("❌ Configuration validation failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Macro validation successful!\n")
-	fmt.Printf("📊 Validated %d vendors for supported macros:\n", len(cfg.Vendors))
-	for i, vendor := range cfg.Vendors {
-		fmt.Printf("  %d. %s\n", i+1, vendor.Name)
-	}
-}
-
-func loadVendorConfig(configPath string, cfg *VendorsOnlyConfig) error {
-	// Check if file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return fmt.Errorf("config file does not exist: %s", configPath)
-	}
-
-	// Load vendors-only configuration
-	return loadVendorsOnly(configPath, cfg)
-}
-
-func loadVendorsOnly(configPath string, cfg *VendorsOnlyConfig) error {
-	configName := path.Base(configPath)
-	ext := path.Ext(configPath)
-	dir := path.Dir(configPath)
-
-	if ext != ".yaml" {
-		return fmt.Errorf("only accept .yaml file")
-	}
-
-	v := viper.New()
-	v.SetConfigType("yaml")
-	v.SetConfigName(configName)
-	v.AddConfigPath(dir)
-
-	if err := v.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	}
-
-	// Try to unmarshal vendors directly
-	if err := v.Unmarshal(cfg); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	proxyURL := f.cfg.ProxyURL
+	if *configPath != "" {
+		proxyURL, err = loadProxyURL(*configPath)
+		if err != nil {
+			fmt.Printf("❌ Configuration validation failed: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	validate := validator.New()
-	if err := validate.Struct(cfg); err != nil {
-		return fmt.Errorf("config validation failed: %w", err)
+	issues := validateVendors(f, proxyURL)
+	if len(issues) > 0 {
+		fmt.Printf("❌ Configuration validation failed, found %d errors:\n", len(issues))
+		for _, is := range issues {
+			fmt.Printf("- %s\n", is.format(vendorsPath))
+		}
+		os.Exit(1)
 	}
 
-	return nil
-}
-
-func validateVendors(vendors []config.Vendor) error {
-	if len(vendors) == 0 {
-		return fmt.Errorf("no vendors found in configuration")
+	fmt.Printf("✅ Configuration validation successful!\n")
+	fmt.Printf("📊 Validated %d vendors:\n", len(f.cfg.Vendors))
+	for i, vendor := range f.cfg.Vendors {
+		fmt.Printf("  %d. %s\n", i+1, vendor.Name)
 	}
 
-	var errors []string
-
-	for _, vendor := range vendors {
-		// Only validate URL macros - skip other validations
-		if err := validateMacros(vendor.Request.URL, vendor.Name, "request.url"); err != nil {
-			errors = append(errors, err.Error())
-		}
-
-		if err := validateMacros(vendor.Tracking.URL, vendor.Name, "tracking.url"); err != nil {
-			errors = append(errors, err.Error())
-		}
-
-		// Validate query macros
-		for _, query := range vendor.Request.Queries {
-			if err := validateMacros(query.Value, vendor.Name, fmt.Sprintf("request.queries.%s", query.Key)); err != nil {
-				errors = append(errors, err.Error())
+	if *previewMode {
+		fmt.Printf("\nPreview for user_id=%s click_id=%s subid=%s os=%s, signed at %s, secrets masked\n\n",
+			sample.UserID, sample.ClickID, sample.SubID, sample.OS, previewTime.Format(time.RFC3339))
+		failed := false
+		for _, vendor := range f.cfg.Vendors {
+			p, err := renderPreview(vendor, sample)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n\n", vendor.Name, err)
+				failed = true
+				continue
 			}
+			p.write(os.Stdout)
+			fmt.Println()
 		}
-
-		for _, query := range vendor.Tracking.Queries {
-			if err := validateMacros(query.Value, vendor.Name, fmt.Sprintf("tracking.queries.%s", query.Key)); err != nil {
-				errors = append(errors, err.Error())
-			}
+		if failed {
+			os.Exit(1)
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("found %d validation errors:\n- %s", len(errors), strings.Join(errors, "\n- "))
-	}
-
-	return nil
-}
-
-func validateMacros(text, vendorName, field string) error {
-	// Extract macros using the MacroRegExp from url strategy
-	matches := url.MacroRegExp.FindAllString(text, -1)
-
-	// Use the actual URL strategy to validate macros
-	// This ensures 100% consistency with runtime behavior
-	strategy := &url.Default{}
-
-	// No need for dummy params - we only check for UnknownMacroError
-	emptyParams := url.Params{}
-
-	// Validate each macro directly using the public GetMacroValue method
-	for _, macro := range matches {
-		_, err := strategy.GetMacroValue(macro, emptyParams)
-		if err != nil {
-			// Check if it's specifically an UnknownMacroError using errors.Is
-			if errors.Is(err, customerrors.ErrUnknownMacro) {
-				return fmt.Errorf("vendor %s: unsupported macro %s in %s", vendorName, macro, field)
+	if len(checks) > 0 {
+		fmt.Println()
+		failed := false
+		for _, c := range checks {
+			if !runResponseCheck(os.Stdout, f.cfg.Vendors, c, sample) {
+				failed = true
 			}
-			// For other errors (like missing params), we don't care during validation
-			// since we're only checking macro support, not parameter completeness
+			fmt.Println()
+		}
+		if failed {
+			fmt.Printf("❌ Response check failed\n")
+			os.Exit(1)
 		}
+		fmt.Printf("✅ Response check successful!\n")
 	}
-
-	return nil
 }
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/strategy"
+	"rec-vendor-api/internal/strategy/body"
+	"rec-vendor-api/internal/strategy/header"
+	"rec-vendor-api/internal/strategy/url"
+	"rec-vendor-api/internal/tracktoken"
+)
+
+// previewTime is the clock of the previewed signatures, so that they are reproducible
+var previewTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// previewSealer seals the {click_id_sealed} macro of the checks and previews with a dummy key
+var previewSealer, _ = clickseal.NewSealer([]clickseal.Key{{ID: "preview", Key: "cHJldmlldy1zZWFsLWtleQ=="}})
+
+// previewTrackingDeps sign the first-party click and impression URLs of the previews with a dummy key
+var previewTrackingDeps = strategy.TrackingDeps{
+	ClickSigner:  tracktoken.NewSigner("preview-signing-key", 0),
+	ClickBaseURL: "https://click.example.com",
+	ClickSealer:  previewSealer,
+}
+
+const maskedSecret = "****"
+
+// sampleRequest is the recommendation request of the previews, with the parameter names of /r/:vendor_key
+type sampleRequest struct {
+	UserID          string `json:"user_id"`
+	ClickID         string `json:"click_id"`
+	ImgWidth        int    `json:"w"`
+	ImgHeight       int    `json:"h"`
+	WebHost         string `json:"web_host"`
+	BundleID        string `json:"bundle_id"`
+	AdType          int    `json:"adtype"`
+	PartnerID       string `json:"partner_id"`
+	KeetaCampaignID string `json:"k_campaign_id"`
+	Latitude        string `json:"lat"`
+	Longitude       string `json:"lon"`
+	SubID           string `json:"subid"`
+	OS              string `json:"os"`
+	ClientIP        string `json:"client_ip"`
+	SiteID          string `json:"site_id"`
+}
+
+var defaultSampleRequest = sampleRequest{
+	UserID:          "3F2504E0-4F89-11D3-9A0C-0305E82C3301",
+	ClickID:         "sample-click-id",
+	ImgWidth:        300,
+	ImgHeight:       250,
+	WebHost:         "example.com",
+	BundleID:        "com.example.app",
+	AdType:          1,
+	PartnerID:       "sample-partner",
+	KeetaCampaignID: "sample-campaign",
+	Latitude:        "25.0330",
+	Longitude:       "121.5654",
+	SubID:           "sample-subid",
+	OS:              "android",
+	ClientIP:        "203.0.113.10",
+	SiteID:          "sample-site",
+}
+
+// sampleProduct is the dummy product of the previewed tracking URLs
+var sampleProduct = struct{ ID, URL string }{ID: "1", URL: "https://example.com/products/1"}
+
+func (r *sampleRequest) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&r.UserID, "user_id", r.UserID, "preview: user ID")
+	fs.StringVar(&r.ClickID, "click_id", r.ClickID, "preview: click ID")
+	fs.IntVar(&r.ImgWidth, "w", r.ImgWidth, "preview: image width")
+	fs.IntVar(&r.ImgHeight, "h", r.ImgHeight, "preview: image height")
+	fs.StringVar(&r.WebHost, "web_host", r.WebHost, "preview: web host")
+	fs.StringVar(&r.BundleID, "bundle_id", r.BundleID, "preview: app bundle ID")
+	fs.IntVar(&r.AdType, "adtype", r.AdType, "preview: ad type")
+	fs.StringVar(&r.PartnerID, "partner_id", r.PartnerID, "preview: partner ID")
+	fs.StringVar(&r.KeetaCampaignID, "k_campaign_id", r.KeetaCampaignID, "preview: Keeta campaign ID")
+	fs.StringVar(&r.Latitude, "lat", r.Latitude, "preview: latitude")
+	fs.StringVar(&r.Longitude, "lon", r.Longitude, "preview: longitude")
+	fs.StringVar(&r.SubID, "subid", r.SubID, "preview: sub ID")
+	fs.StringVar(&r.OS, "os", r.OS, "preview: OS, android or ios")
+	fs.StringVar(&r.ClientIP, "client_ip", r.ClientIP, "preview: client IP")
+	fs.StringVar(&r.SiteID, "site_id", r.SiteID, "preview: site ID")
+}
+
+func (r *sampleRequest) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read sample request: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return fmt.Errorf("failed to parse sample request: %w", err)
+	}
+	return nil
+}
+
+func (r sampleRequest) urlParams() url.Params {
+	return url.Params{
+		UserID:          r.UserID,
+		ClickID:         r.ClickID,
+		ImgWidth:        r.ImgWidth,
+		ImgHeight:       r.ImgHeight,
+		WebHost:         r.WebHost,
+		BundleID:        r.BundleID,
+		AdType:          r.AdType,
+		PartnerID:       r.PartnerID,
+		ClientIP:        r.ClientIP,
+		KeetaCampaignID: r.KeetaCampaignID,
+		Latitude:        r.Latitude,
+		Longitude:       r.Longitude,
+		SubID:           r.SubID,
+		OS:              r.OS,
+	}
+}
+
+// trackingParams are the params of the tracking URLs of the sample product, as set by the vendor client
+func (r sampleRequest) trackingParams() url.Params {
+	return url.Params{
+		ProductURL: sampleProduct.URL,
+		ProductID:  sampleProduct.ID,
+		SiteID:     r.SiteID,
+		ClickID:    r.ClickID,
+		UserID:     r.UserID,
+		OS:         r.OS,
+	}
+}
+
+func (r sampleRequest) bodyParams() body.Params {
+	return body.Params{
+		UserID:    r.UserID,
+		ClickID:   r.ClickID,
+		ImgWidth:  r.ImgWidth,
+		ImgHeight: r.ImgHeight,
+		BundleID:  r.BundleID,
+		SubID:     r.SubID,
+	}
+}
+
+// preview is what the vendor client sends for the sample request, with the vendor secrets masked
+type preview struct {
+	Vendor        string
+	Method        string
+	RequestURL    string
+	Headers       map[string]string
+	Body          string
+	TrackingURL   string
+	ImpressionURL string
+}
+
+func renderPreview(v config.Vendor, req sampleRequest) (preview, error) {
+	p := preview{Vendor: v.Name, Method: v.HTTPMethod}
+
+	requestURL, err := strategy.BuildRequest(v, previewSealer).GenerateURL(v.Request, req.urlParams())
+	if err != nil {
+		return p, fmt.Errorf("request url: %w", err)
+	}
+	p.Headers = strategy.BuildHeaderWithClock(v, header.FixedClock{Time: previewTime}).GenerateHeaders(header.Params{
+		RequestURL: requestURL,
+		UserID:     req.UserID,
+		HTTPMethod: v.HTTPMethod,
+	})
+	if v.HTTPMethod == "POST" {
+		b, err := json.Marshal(strategy.BuildBody(v).GenerateBody(req.bodyParams()))
+		if err != nil {
+			return p, fmt.Errorf("body: %w", err)
+		}
+		p.Body = string(b)
+	}
+
+	tracking, err := strategy.BuildTracking(v, previewTrackingDeps)
+	if err != nil {
+		return p, err
+	}
+	if p.TrackingURL, err = tracking.GenerateURL(v.Tracking, req.trackingParams()); err != nil {
+		return p, fmt.Errorf("tracking url: %w", err)
+	}
+	impression, err := strategy.BuildImpression(v, previewTrackingDeps)
+	if err != nil {
+		return p, err
+	}
+	if p.ImpressionURL, err = impression.GenerateURL(v.Tracking, req.trackingParams()); err != nil {
+		return p, fmt.Errorf("impression url: %w", err)
+	}
+
+	p.RequestURL = maskSecrets(v, requestURL)
+	for key, value := range p.Headers {
+		p.Headers[key] = maskSecrets(v, value)
+	}
+	p.Body = maskSecrets(v, p.Body)
+	return p, nil
+}
+
+// maskSecrets masks the credentials and the secret queries of the vendor
+func maskSecrets(v config.Vendor, s string) string {
+	for _, secret := range v.SecretValues() {
+		s = strings.ReplaceAll(s, secret, maskedSecret)
+	}
+	return s
+}
+
+func (p preview) write(w io.Writer) {
+	fmt.Fprintf(w, "🔎 %s (%s)\n", p.Vendor, p.Method)
+	fmt.Fprintf(w, "  request:    %s\n", p.RequestURL)
+	keys := make([]string, 0, len(p.Headers))
+	for key := range p.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  header:     %s: %s\n", key, p.Headers[key])
+	}
+	if p.Body != "" {
+		fmt.Fprintf(w, "  body:       %s\n", p.Body)
+	}
+	fmt.Fprintf(w, "  tracking:   %s\n", p.TrackingURL)
+	if p.ImpressionURL != "" {
+		fmt.Fprintf(w, "  impression: %s\n", p.ImpressionURL)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPreview(t *testing.T) {
+	tt := []struct {
+		name   string
+		vendor config.Vendor
+		want   preview
+	}{
+		{
+			name: "GIVEN a GET vendor THEN expect the request and tracking URLs",
+			vendor: config.Vendor{
+				Name:       "linkmine",
+				HTTPMethod: "GET",
+				Request: config.URLPattern{URL: "https://api.linkmine.co.kr/reco/", Queries: []config.Query{
+					{Key: "device_id", Value: "{user_id_lower}"},
+					{Key: "app_code", Value: "{subid}"},
+				}},
+				Tracking: config.URLPattern{URL: "{product_url}", Queries: []config.Query{{Key: "param1", Value: "{click_id_base64}"}}},
+			},
+			want: preview{
+				Vendor:      "linkmine",
+				Method:      "GET",
+				RequestURL:  "https://api.linkmine.co.kr/reco/?app_code=sample-subid&device_id=3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+				Headers:     map[string]string{},
+				TrackingURL: "https://example.com/products/1?param1=c2FtcGxlLWNsaWNrLWlk",
+			},
+		},
+		{
+			name: "GIVEN a POST vendor with credentials THEN expect the body and the signature with masked secrets",
+			vendor: config.Vendor{
+				Name:       "replace",
+				HTTPMethod: "POST",
+				AccessKey:  "replace-access-key",
+				SecretKey:  "replace-secret-key",
+				Request:    config.URLPattern{URL: "https://api-gateway.coupang.com/reco"},
+				Tracking:   config.URLPattern{URL: "https://click.adshot.network/trk", Queries: []config.Query{{Key: "land_url", Value: "{product_url}"}}},
+			},
+			want: preview{
+				Vendor:     "replace",
+				Method:     "POST",
+				RequestURL: "https://api-gateway.coupang.com/reco",
+				Headers: map[string]string{
+					"Authorization": "CEA algorithm=HmacSHA256, access-key=****, signed-date=250101T000000Z, signature=5ead2a120ab54552840f2c92cff801b2a27cf292a2aba7495b10588c7447d346",
+				},
+				Body:        `{"app":{"bundleId":"com.example.app"},"device":{"id":"3f2504e0-4f89-11d3-9a0c-0305e82c3301","lmt":0},"imp":{"imageSize":"300x250"},"affiliate":{"subId":"sample-subid","subParam":"c2FtcGxlLWNsaWNrLWlk"},"user":{"puid":"c2FtcGxlLWNsaWNrLWlk"}}`,
+				TrackingURL: "https://click.adshot.network/trk?land_url=https%3A%2F%2Fexample.com%2Fproducts%2F1",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderPreview(tc.vendor, defaultSampleRequest)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRenderPreviewMissingParam(t *testing.T) {
+	vendor := config.Vendor{
+		Name:       "keeta",
+		HTTPMethod: "GET",
+		Request:    config.URLPattern{URL: "https://keeta.example.com/reco", Queries: []config.Query{{Key: "campaignId", Value: "{keeta_campaign_id}"}}},
+		Tracking:   config.URLPattern{URL: "{product_url}"},
+	}
+	sample := defaultSampleRequest
+	sample.KeetaCampaignID = ""
+
+	_, err := renderPreview(vendor, sample)
+	require.ErrorContains(t, err, "KeetaCampaignID not provided")
+}
+
+func TestSampleRequestLoad(t *testing.T) {
+	samplePath := filepath.Join(t.TempDir(), "sample.json")
+	require.NoError(t, os.WriteFile(samplePath, []byte(`{"user_id": "ABC", "w": 600, "os": "ios"}`), 0o600))
+
+	sample := defaultSampleRequest
+	require.NoError(t, sample.load(samplePath))
+	require.Equal(t, "ABC", sample.UserID)
+	require.Equal(t, 600, sample.ImgWidth)
+	require.Equal(t, "ios", sample.OS)
+	// the fields missing from the file keep their default
+	require.Equal(t, defaultSampleRequest.ClickID, sample.ClickID)
+}
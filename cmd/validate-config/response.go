@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/strategy"
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// responseChecks are the -check-response vendor=path.json flags
+type responseChecks []responseCheck
+
+type responseCheck struct {
+	vendor string
+	path   string
+}
+
+func (r *responseChecks) String() string {
+	checks := make([]string, 0, len(*r))
+	for _, c := range *r {
+		checks = append(checks, c.vendor+"="+c.path)
+	}
+	return strings.Join(checks, ",")
+}
+
+func (r *responseChecks) Set(s string) error {
+	vendor, path, ok := strings.Cut(s, "=")
+	if !ok || vendor == "" || path == "" {
+		return fmt.Errorf("expected vendor=path.json, got %q", s)
+	}
+	*r = append(*r, responseCheck{vendor: vendor, path: path})
+	return nil
+}
+
+// fieldHints are the key fragments of the sample response fields that fill each PartnerResp field
+var fieldHints = []struct {
+	field    string
+	value    func(unmarshaler.PartnerResp) string
+	hints    []string
+	excludes []string
+}{
+	{field: "url", value: func(p unmarshaler.PartnerResp) string { return p.ProductURL }, hints: []string{"url", "link"}, excludes: []string{"image", "img"}},
+	{field: "image", value: func(p unmarshaler.PartnerResp) string { return p.ProductImage }, hints: []string{"image", "img"}},
+	{field: "title", value: func(p unmarshaler.PartnerResp) string { return p.ProductTitle }, hints: []string{"title", "name"}},
+	{field: "price", value: func(p unmarshaler.PartnerResp) string { return p.ProductPrice }, hints: []string{"price"}, excludes: []string{"sale", "discount"}},
+	{field: "sale_price", value: func(p unmarshaler.PartnerResp) string { return p.ProductSalePrice }, hints: []string{"sale", "discount"}},
+	{field: "currency", value: func(p unmarshaler.PartnerResp) string { return p.ProductCurrency }, hints: []string{"currency"}},
+}
+
+// responseItem is a parsed product of the sample response, with the fields the unmarshaler left empty
+// although the sample has them
+type responseItem struct {
+	resp        unmarshaler.PartnerResp
+	trackingURL string
+	missing     []string
+}
+
+// checkResponse parses the sample response with the unmarshaler of the vendor, it fails on the errors the
+// vendor client would fail on
+func checkResponse(v config.Vendor, body []byte, req sampleRequest) ([]responseItem, error) {
+	res, err := strategy.BuildUnmarshaler(v).UnmarshalResponse(context.Background(), body)
+	switch {
+	case errors.Is(err, unmarshaler.ErrInvalidFormat):
+		return nil, fmt.Errorf("the sample does not have the response format of the %s unmarshaler: %w", v.Name, err)
+	case errors.Is(err, unmarshaler.ErrNoProducts):
+		return nil, fmt.Errorf("the sample has no products for the %s unmarshaler: %w", v.Name, err)
+	case err != nil:
+		return nil, err
+	}
+
+	tracking, err := strategy.BuildTracking(v, previewTrackingDeps)
+	if err != nil {
+		return nil, err
+	}
+	objects := sampleObjects(body)
+
+	items := make([]responseItem, 0, len(res))
+	for _, r := range res {
+		params := req.trackingParams()
+		params.ProductURL, params.ProductID = r.ProductURL, r.ProductID
+		item := responseItem{resp: r, missing: missingFields(r, findObject(objects, r.ProductID))}
+		if item.trackingURL, err = tracking.GenerateURL(v.Tracking, params); err != nil {
+			return nil, fmt.Errorf("product %s: tracking url: %w", r.ProductID, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// sampleObjects returns the JSON objects of the sample, in document order
+func sampleObjects(body []byte) []map[string]any {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil
+	}
+	var objects []map[string]any
+	var walk func(any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			objects = append(objects, v)
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+	return objects
+}
+
+// findObject returns the innermost object of the sample with a field of the product ID
+func findObject(objects []map[string]any, productID string) map[string]any {
+	var found map[string]any
+	for _, obj := range objects {
+		for _, v := range obj {
+			if scalar(v) == productID {
+				found = obj
+			}
+		}
+	}
+	return found
+}
+
+func missingFields(r unmarshaler.PartnerResp, obj map[string]any) []string {
+	if obj == nil {
+		return nil
+	}
+	// sample values used by any field, e.g. a price parsed as sale price, are not missing
+	used := map[string]bool{r.ProductID: true}
+	for _, h := range fieldHints {
+		if v := h.value(r); v != "" {
+			used[v] = true
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var missing []string
+	for _, h := range fieldHints {
+		if h.value(r) != "" {
+			continue
+		}
+		for _, key := range keys {
+			s := scalar(obj[key])
+			if s == "" || used[s] || !matchesHint(strings.ToLower(key), h.hints, h.excludes) {
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("%s is empty but the sample has %s=%s", h.field, key, s))
+			break
+		}
+	}
+	return missing
+}
+
+func matchesHint(key string, hints, excludes []string) bool {
+	for _, e := range excludes {
+		if strings.Contains(key, e) {
+			return false
+		}
+	}
+	for _, h := range hints {
+		if strings.Contains(key, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// scalar returns the string form of a JSON string or number, and "" for the other values
+func scalar(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// runResponseCheck prints the products parsed from the sample response, it returns false if the check failed
+func runResponseCheck(w io.Writer, vendors []config.Vendor, c responseCheck, req sampleRequest) bool {
+	var vendor *config.Vendor
+	for i := range vendors {
+		if vendors[i].Name == c.vendor {
+			vendor = &vendors[i]
+		}
+	}
+	if vendor == nil {
+		fmt.Fprintf(w, "❌ %s: vendor not found\n", c.vendor)
+		return false
+	}
+	body, err := os.ReadFile(c.path)
+	if err != nil {
+		fmt.Fprintf(w, "❌ %s: failed to read sample response: %v\n", c.vendor, err)
+		return false
+	}
+	items, err := checkResponse(*vendor, body, req)
+	if err != nil {
+		fmt.Fprintf(w, "❌ %s: %v\n", c.vendor, err)
+		return false
+	}
+
+	ok := true
+	fmt.Fprintf(w, "🔎 %s: %d products parsed from %s\n", c.vendor, len(items), c.path)
+	for i, item := range items {
+		r := item.resp
+		fmt.Fprintf(w, "  %d. id=%s title=%q price=%s sale_price=%s currency=%s\n", i+1, r.ProductID, r.ProductTitle, r.ProductPrice, r.ProductSalePrice, r.ProductCurrency)
+		fmt.Fprintf(w, "     url:      %s\n", r.ProductURL)
+		fmt.Fprintf(w, "     image:    %s\n", r.ProductImage)
+		fmt.Fprintf(w, "     tracking: %s\n", item.trackingURL)
+		for _, m := range item.missing {
+			fmt.Fprintf(w, "     ❌ %s\n", m)
+			ok = false
+		}
+	}
+	return ok
+}
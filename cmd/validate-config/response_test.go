@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/strategy/unmarshaler"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckResponse(t *testing.T) {
+	linkmine := config.Vendor{Name: "linkmine", HTTPMethod: "GET", Tracking: config.URLPattern{URL: "{product_url}"}}
+	adforus := config.Vendor{Name: "adforus", HTTPMethod: "GET", Tracking: config.URLPattern{URL: "{product_url}"}}
+
+	tt := []struct {
+		name      string
+		vendor    config.Vendor
+		body      string
+		want      []responseItem
+		wantError error
+	}{
+		{
+			name:   "GIVEN a sample of the vendor format THEN expect the products and tracking URLs",
+			vendor: linkmine,
+			body:   `[{"productId": 1, "productUrl": "https://link.coupang.com/1", "productImage": "https://img.coupang.com/1.jpg"}]`,
+			want: []responseItem{{
+				resp:        unmarshaler.PartnerResp{ProductID: "1", ProductURL: "https://link.coupang.com/1", ProductImage: "https://img.coupang.com/1.jpg"},
+				trackingURL: "https://link.coupang.com/1",
+			}},
+		},
+		{
+			name:   "GIVEN a sample field the unmarshaler drops THEN expect it reported",
+			vendor: adforus,
+			body:   `[{"productId": "p1", "productName": "Sofa", "productPrice": 12000, "productImage": "https://img/1.jpg", "productUrl": "https://shop/1"}]`,
+			want: []responseItem{{
+				resp:        unmarshaler.PartnerResp{ProductID: "p1", ProductURL: "https://shop/1", ProductTitle: "Sofa", ProductSalePrice: "12000"},
+				trackingURL: "https://shop/1",
+				missing:     []string{"image is empty but the sample has productImage=https://img/1.jpg"},
+			}},
+		},
+		{
+			name:      "GIVEN a sample of another format THEN expect an invalid format error",
+			vendor:    linkmine,
+			body:      `{"data": [{"productId": 1}]}`,
+			wantError: unmarshaler.ErrInvalidFormat,
+		},
+		{
+			name:      "GIVEN a sample without products THEN expect a no products error",
+			vendor:    adforus,
+			body:      `[]`,
+			wantError: unmarshaler.ErrNoProducts,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := checkResponse(tc.vendor, []byte(tc.body), defaultSampleRequest)
+			if tc.wantError != nil {
+				require.ErrorIs(t, err, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestResponseChecksSet(t *testing.T) {
+	var checks responseChecks
+	require.NoError(t, checks.Set("keeta=samples/keeta.json"))
+	require.NoError(t, checks.Set("adforus=adforus.json"))
+	require.Equal(t, responseChecks{{vendor: "keeta", path: "samples/keeta.json"}, {vendor: "adforus", path: "adforus.json"}}, checks)
+	require.Equal(t, "keeta=samples/keeta.json,adforus=adforus.json", checks.String())
+
+	require.Error(t, checks.Set("keeta.json"))
+}
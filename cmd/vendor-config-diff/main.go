@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"rec-vendor-api/internal/configdiff"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format: text, json")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: vendor-config-diff [-format text|json] <old vendors.yaml> <new vendors.yaml>")
+		fmt.Fprintln(os.Stderr, "Example: vendor-config-diff <(git show master:config-template/vendors.yaml) config-template/vendors.yaml")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unsupported format %s (supported: text, json)\n", *format)
+		os.Exit(1)
+	}
+
+	oldVendors, err := configdiff.LoadVendors(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load old vendors: %v\n", err)
+		os.Exit(1)
+	}
+	newVendors, err := configdiff.LoadVendors(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load new vendors: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := configdiff.Diff(oldVendors, newVendors)
+	if *format == "json" {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	report.WriteText(os.Stdout)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"rec-vendor-api/internal/report"
+)
+
+func main() {
+	groupBy := flag.String("group-by", report.DimensionVendor, "comma-separated dimensions: vendor, site, hour")
+	format := flag.String("format", "table", "output format: table, csv")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: vendor-report [-group-by vendor,site,hour] [-format table|csv] <events.jsonl[.gz]>...")
+		fmt.Fprintln(os.Stderr, "Example: vendor-report -group-by vendor,hour /var/log/vendor-api/events.jsonl*")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "csv" {
+		fmt.Fprintf(os.Stderr, "unsupported format %s (supported: table, csv)\n", *format)
+		os.Exit(1)
+	}
+
+	dimensions := strings.Split(*groupBy, ",")
+	aggregator, err := report.NewAggregator(dimensions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := report.ReadFiles(flag.Args(), aggregator.Add); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read events: %v\n", err)
+		os.Exit(1)
+	}
+
+	write := report.WriteTable
+	if *format == "csv" {
+		write = report.WriteCSV
+	}
+	if err := write(os.Stdout, dimensions, aggregator.Rows(), aggregator.Overlaps()); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/scaffold"
+	"rec-vendor-api/internal/strategy"
+)
+
+var vendorNameRegExp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func main() {
+	name := flag.String("name", "", "vendor name, a lowercase identifier such as acme_shop")
+	method := flag.String("method", "GET", "http method of the vendor requests: GET, POST")
+	requestURL := flag.String("request-url", "", "sample request URL, with the sample query values of the vendor documentation")
+	responsePath := flag.String("response", "", "JSON file of the sample response")
+	withProxy := flag.Bool("with-proxy", false, "send the vendor requests through the proxy")
+	output := flag.String("output", "mapping", "response parsing: mapping, a response mapping in the vendors.yaml entry, or go, a generated unmarshaler and test")
+	dir := flag.String("dir", "internal/strategy/unmarshaler", "go output: directory of the generated unmarshaler and test")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: vendor-scaffold -name <vendor> [-method GET|POST] -request-url <url> -response <response.json> [-output mapping|go]")
+		fmt.Fprintln(os.Stderr, "Example: vendor-scaffold -name acme -request-url 'https://api.acme.com/reco?adid=...&click_id=...' -response acme.json >> config-template/vendors.yaml")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*name, *method, *requestURL, *responsePath, *withProxy, *output, *dir); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(name, method, requestURL, responsePath string, withProxy bool, output, dir string) error {
+	switch {
+	case !vendorNameRegExp.MatchString(name):
+		return fmt.Errorf("invalid vendor name %q: expected a lowercase identifier such as acme_shop", name)
+	case method != "GET" && method != "POST":
+		return fmt.Errorf("unsupported method %s (supported: GET, POST)", method)
+	case output != "mapping" && output != "go":
+		return fmt.Errorf("unsupported output %s (supported: mapping, go)", output)
+	case requestURL == "" || responsePath == "":
+		return errors.New("-request-url and -response are required")
+	}
+
+	request, err := scaffold.InferRequest(requestURL)
+	if err != nil {
+		return err
+	}
+	sample, err := os.ReadFile(responsePath)
+	if err != nil {
+		return fmt.Errorf("failed to read sample response: %w", err)
+	}
+	response, err := scaffold.InferResponse(sample)
+	if err != nil {
+		return err
+	}
+
+	v := config.Vendor{
+		Name:       name,
+		WithProxy:  withProxy,
+		HTTPMethod: method,
+		Request:    request,
+		Tracking:   config.URLPattern{URL: "{product_url}", Queries: []config.Query{}},
+	}
+	if output == "mapping" {
+		v.Response = response
+		// parse the sample as the vendor client would, so that a wrong mapping fails here
+		products, err := strategy.BuildUnmarshaler(v).UnmarshalResponse(context.Background(), sample)
+		if err != nil {
+			return fmt.Errorf("the inferred response mapping does not parse the sample: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ the response mapping parses %d products from the sample\n", len(products))
+	} else {
+		if err := writeUnmarshaler(name, response, sample, dir); err != nil {
+			return err
+		}
+	}
+
+	fmt.Print(scaffold.VendorEntry(v))
+	if method == "POST" {
+		fmt.Fprintf(os.Stderr, "⚠️  POST vendors need a body strategy: add it to BuildBody in internal/strategy/builder.go\n")
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  review the queries commented as from the sample request, then run make validate-vendors-config\n")
+	return nil
+}
+
+func writeUnmarshaler(name string, response config.Response, sample []byte, dir string) error {
+	code, test, err := scaffold.GenerateUnmarshaler(name, response, sample)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		filepath.Join(dir, name+".go"):      code,
+		filepath.Join(dir, name+"_test.go"): test,
+	}
+	for path := range files {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	for path, src := range files {
+		if err := os.WriteFile(path, src, 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✅ wrote %s\n", path)
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  add the unmarshaler to BuildUnmarshaler in internal/strategy/builder.go:\n\tcase %q:\n\t\treturn &unmarshaler.%s{}\n", name, scaffold.TypeName(name))
+	return nil
+}
@@ -17,6 +17,35 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/c/{token}": {
+            "get": {
+                "description": "Validates a signed click token, records the click and redirects to the vendor tracking URL",
+                "summary": "First-party click redirect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signed click token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/healthz": {
             "get": {
                 "description": "Usage for checking service liveness",
@@ -31,6 +60,160 @@ const docTemplate = `{
                 }
             }
         },
+        "/i/{token}": {
+            "get": {
+                "description": "Validates a signed impression token and records the impression, invalid tokens get no content",
+                "produces": [
+                    "image/gif"
+                ],
+                "summary": "Impression beacon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signed impression token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "1x1 GIF"
+                    },
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/pb/{vendor_key}": {
+            "get": {
+                "description": "Receives a conversion postback from a vendor, duplicated orders are acknowledged but not counted",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Conversion postback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vendor Key",
+                        "name": "vendor_key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Receives a conversion postback from a vendor, duplicated orders are acknowledged but not counted",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Conversion postback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vendor Key",
+                        "name": "vendor_key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/r/{vendor_key}": {
             "get": {
                 "description": "Returns recommended products for a user from a vendor",
@@ -103,6 +286,12 @@ const docTemplate = `{
                         "description": "Operating System (android, ios)",
                         "name": "os",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Response format (json, openrtb_native), default: json",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -178,6 +367,9 @@ const docTemplate = `{
                 "image": {
                     "type": "string"
                 },
+                "impression_url": {
+                    "type": "string"
+                },
                 "price": {
                     "type": "string"
                 },
@@ -187,6 +379,9 @@ const docTemplate = `{
                 "sale_price": {
                     "type": "string"
                 },
+                "title": {
+                    "type": "string"
+                },
                 "url": {
                     "type": "string"
                 }
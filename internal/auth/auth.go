@@ -0,0 +1,141 @@
+// Package auth authenticates the callers of the recommendation and vendor endpoints, by API key or gRPC client
+// certificate, and authorizes them per vendor key
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/constants"
+)
+
+const DefaultHeader = "x-api-key"
+
+// DefaultExemptPaths are the paths and gRPC methods of the probes and the metrics scraper
+var DefaultExemptPaths = []string{"/healthz", "/metrics", constants.FullMethodHealthCheck}
+
+var (
+	ErrUnauthenticated = errors.New("missing or invalid API key")
+	ErrForbidden       = errors.New("caller is not allowed to request this vendor")
+)
+
+type apiKey struct {
+	key    []byte
+	caller string
+}
+
+// Authenticator identifies the callers of the config, a caller without vendors can request all the vendor keys
+type Authenticator struct {
+	header      string
+	exempt      map[string]bool
+	keys        []apiKey
+	commonNames map[string]string
+	vendors     map[string]map[string]bool
+}
+
+// New returns the authenticator of the config, nil if the authentication is disabled
+func New(cfg config.AuthConfig) (*Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	a := &Authenticator{
+		header:      strings.ToLower(cfg.Header),
+		exempt:      map[string]bool{},
+		commonNames: map[string]string{},
+		vendors:     map[string]map[string]bool{},
+	}
+	if a.header == "" {
+		a.header = DefaultHeader
+	}
+	exemptPaths := cfg.ExemptPaths
+	if exemptPaths == nil {
+		exemptPaths = DefaultExemptPaths
+	}
+	for _, path := range exemptPaths {
+		a.exempt[path] = true
+	}
+
+	seenKeys := map[string]bool{}
+	for _, c := range cfg.Callers {
+		if _, ok := a.vendors[c.Name]; ok {
+			return nil, fmt.Errorf("duplicate auth caller %s", c.Name)
+		}
+		if len(c.APIKeys) == 0 && len(c.CommonNames) == 0 {
+			return nil, fmt.Errorf("auth caller %s has neither api_keys nor common_names", c.Name)
+		}
+		for _, key := range c.APIKeys {
+			if key == "" || seenKeys[key.Value()] {
+				return nil, fmt.Errorf("auth caller %s has an empty or duplicate API key", c.Name)
+			}
+			seenKeys[key.Value()] = true
+			a.keys = append(a.keys, apiKey{key: []byte(key.Value()), caller: c.Name})
+		}
+		for _, cn := range c.CommonNames {
+			if other, ok := a.commonNames[cn]; ok {
+				return nil, fmt.Errorf("auth callers %s and %s have the same common name %s", other, c.Name, cn)
+			}
+			a.commonNames[cn] = c.Name
+		}
+		var vendors map[string]bool
+		if len(c.Vendors) > 0 {
+			vendors = make(map[string]bool, len(c.Vendors))
+			for _, v := range c.Vendors {
+				vendors[v] = true
+			}
+		}
+		a.vendors[c.Name] = vendors
+	}
+	return a, nil
+}
+
+// Header is the lowercase header, or gRPC metadata, of the API key
+func (a *Authenticator) Header() string {
+	return a.header
+}
+
+// Exempt reports whether the path, or gRPC full method, is not authenticated
+func (a *Authenticator) Exempt(path string) bool {
+	return a.exempt[path]
+}
+
+// Authenticate returns the caller of the API key, or else of the verified client certificate common name
+func (a *Authenticator) Authenticate(key, commonName string) (string, error) {
+	if key != "" {
+		// compare all the keys in constant time, not to leak a key prefix by timing
+		caller := ""
+		for _, k := range a.keys {
+			if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+				caller = k.caller
+			}
+		}
+		if caller != "" {
+			return caller, nil
+		}
+		return "", ErrUnauthenticated
+	}
+	if caller, ok := a.commonNames[commonName]; ok && commonName != "" {
+		return caller, nil
+	}
+	return "", ErrUnauthenticated
+}
+
+// Authorize checks that the caller can request the vendor key, the requests without vendor key, such as
+// /vendors, are allowed and list only the vendors the caller can request
+func (a *Authenticator) Authorize(caller, vendorKey string) error {
+	if vendorKey == "" || a.Allows(caller, vendorKey) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// Allows reports whether the caller can request the vendor key, any caller can if the authentication is disabled
+func (a *Authenticator) Allows(caller, vendorKey string) bool {
+	if a == nil {
+		return true
+	}
+	vendors := a.vendors[caller]
+	return vendors == nil || vendors[vendorKey]
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"testing"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/constants"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.AuthConfig{
+	Enabled: true,
+	Callers: []config.AuthCaller{
+		{Name: "bidder", APIKeys: []config.Secret{"bidder-key", "bidder-key-v2"}},
+		{Name: "sdk", CommonNames: []string{"sdk.internal"}, Vendors: []string{"keeta"}},
+	},
+}
+
+func TestNew(t *testing.T) {
+	tt := []struct {
+		name      string
+		cfg       config.AuthConfig
+		wantNil   bool
+		wantError bool
+	}{
+		{
+			name:    "GIVEN a disabled config THEN expect no authenticator",
+			cfg:     config.AuthConfig{Callers: testConfig.Callers},
+			wantNil: true,
+		},
+		{
+			name: "GIVEN callers with keys and common names THEN expect no error",
+			cfg:  testConfig,
+		},
+		{
+			name:      "GIVEN a caller without key nor common name THEN expect error",
+			cfg:       config.AuthConfig{Enabled: true, Callers: []config.AuthCaller{{Name: "bidder"}}},
+			wantError: true,
+		},
+		{
+			name: "GIVEN a key shared by two callers THEN expect error",
+			cfg: config.AuthConfig{Enabled: true, Callers: []config.AuthCaller{
+				{Name: "bidder", APIKeys: []config.Secret{"key"}},
+				{Name: "sdk", APIKeys: []config.Secret{"key"}},
+			}},
+			wantError: true,
+		},
+		{
+			name: "GIVEN a duplicate caller THEN expect error",
+			cfg: config.AuthConfig{Enabled: true, Callers: []config.AuthCaller{
+				{Name: "bidder", APIKeys: []config.Secret{"key"}},
+				{Name: "bidder", APIKeys: []config.Secret{"key-2"}},
+			}},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := New(tc.cfg)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantNil, a == nil)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a, err := New(testConfig)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name       string
+		key        string
+		commonName string
+		want       string
+		wantError  error
+	}{
+		{name: "GIVEN a valid key THEN expect its caller", key: "bidder-key-v2", want: "bidder"},
+		{name: "GIVEN a verified common name THEN expect its caller", commonName: "sdk.internal", want: "sdk"},
+		{name: "GIVEN an invalid key and a common name THEN expect the key to fail", key: "bidder", commonName: "sdk.internal", wantError: ErrUnauthenticated},
+		{name: "GIVEN no key nor common name THEN expect unauthenticated", wantError: ErrUnauthenticated},
+		{name: "GIVEN an unknown common name THEN expect unauthenticated", commonName: "other.internal", wantError: ErrUnauthenticated},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			caller, err := a.Authenticate(tc.key, tc.commonName)
+			require.ErrorIs(t, err, tc.wantError)
+			require.Equal(t, tc.want, caller)
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	a, err := New(testConfig)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Authorize("bidder", "replace"))
+	require.NoError(t, a.Authorize("sdk", "keeta"))
+	require.NoError(t, a.Authorize("sdk", ""))
+	require.ErrorIs(t, a.Authorize("sdk", "replace"), ErrForbidden)
+
+	require.True(t, a.Allows("bidder", "replace"))
+	require.True(t, a.Allows("sdk", "keeta"))
+	require.False(t, a.Allows("sdk", "replace"))
+	// the authentication is disabled
+	require.True(t, (*Authenticator)(nil).Allows("", "replace"))
+}
+
+func TestExemptAndHeader(t *testing.T) {
+	a, err := New(testConfig)
+	require.NoError(t, err)
+	require.Equal(t, DefaultHeader, a.Header())
+	require.True(t, a.Exempt("/healthz"))
+	require.True(t, a.Exempt(constants.FullMethodHealthCheck))
+	require.False(t, a.Exempt("/vendors"))
+
+	a, err = New(config.AuthConfig{Enabled: true, Header: "X-Rec-Key", ExemptPaths: []string{"/metrics"}})
+	require.NoError(t, err)
+	require.Equal(t, "x-rec-key", a.Header())
+	require.False(t, a.Exempt("/healthz"))
+	require.True(t, a.Exempt("/metrics"))
+}
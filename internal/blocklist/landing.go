@@ -0,0 +1,27 @@
+package blocklist
+
+import (
+	urlpkg "net/url"
+	"strings"
+)
+
+// landingHosts returns the host of the URL and of any absolute URL passed in its query,
+// since affiliate links usually carry the merchant landing page as a deep link parameter.
+func landingHosts(rawURL string) []string {
+	parsedURL, err := urlpkg.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return nil
+	}
+
+	hosts := []string{strings.ToLower(parsedURL.Hostname())}
+	for _, values := range parsedURL.Query() {
+		for _, value := range values {
+			nested, err := urlpkg.Parse(value)
+			if err != nil || nested.Host == "" {
+				continue
+			}
+			hosts = append(hosts, strings.ToLower(nested.Hostname()))
+		}
+	}
+	return hosts
+}
@@ -0,0 +1,232 @@
+package blocklist
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ReasonProductID = "product_id"
+	ReasonDomain    = "domain"
+	ReasonKeyword   = "keyword"
+)
+
+// Source is a blocklist file and the scope it applies to.
+// A source without site IDs and vendors applies globally.
+type Source struct {
+	Path    string
+	SiteIDs []string
+	Vendors []string
+}
+
+// Item is the part of a product the blocklists are matched against
+type Item struct {
+	ProductID  string
+	LandingURL string
+	Title      string
+}
+
+type fileContent struct {
+	ProductIDs []string `yaml:"product_ids"`
+	Domains    []string `yaml:"domains"`
+	Keywords   []string `yaml:"keywords"`
+}
+
+type ruleSet struct {
+	source     Source
+	modTime    time.Time
+	productIDs map[string]struct{}
+	domains    []string
+	keywords   []string
+}
+
+// Store holds the blocklists loaded from the sources and reloads them when the files change
+type Store struct {
+	sources  []Source
+	ruleSets atomic.Pointer[[]*ruleSet]
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewStore loads all sources and, if reloadInterval is positive, checks them for changes at that interval.
+// Failing to load any source at startup is an error, failing to reload keeps the previous rules.
+func NewStore(sources []Source, reloadInterval time.Duration) (*Store, error) {
+	s := &Store{
+		sources: sources,
+		stop:    make(chan struct{}),
+	}
+
+	ruleSets := make([]*ruleSet, 0, len(sources))
+	for _, source := range sources {
+		rs, err := load(source)
+		if err != nil {
+			return nil, err
+		}
+		ruleSets = append(ruleSets, rs)
+	}
+	s.ruleSets.Store(&ruleSets)
+
+	if reloadInterval > 0 {
+		go s.watch(reloadInterval)
+	}
+	return s, nil
+}
+
+// Close stops reloading the blocklist files
+func (s *Store) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// HasKeywords reports whether the keywords of the loaded files apply to the vendor, on any site
+func (s *Store) HasKeywords(vendor string) bool {
+	for _, rs := range *s.ruleSets.Load() {
+		if len(rs.keywords) > 0 && (len(rs.source.Vendors) == 0 || slices.Contains(rs.source.Vendors, vendor)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the reason the item is blocked for the given vendor and site, if any
+func (s *Store) Match(vendor, siteID string, item Item) (string, bool) {
+	hosts := landingHosts(item.LandingURL)
+	title := strings.ToLower(item.Title)
+
+	for _, rs := range *s.ruleSets.Load() {
+		if !rs.source.appliesTo(vendor, siteID) {
+			continue
+		}
+		if _, ok := rs.productIDs[item.ProductID]; ok {
+			return ReasonProductID, true
+		}
+		for _, host := range hosts {
+			if rs.matchDomain(host) {
+				return ReasonDomain, true
+			}
+		}
+		if title != "" && rs.matchKeyword(title) {
+			return ReasonKeyword, true
+		}
+	}
+	return "", false
+}
+
+func (s *Store) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.reload()
+		}
+	}
+}
+
+func (s *Store) reload() {
+	current := *s.ruleSets.Load()
+	next := make([]*ruleSet, 0, len(current))
+	changed := false
+
+	for _, rs := range current {
+		info, err := os.Stat(rs.source.Path)
+		if err != nil {
+			log.Errorf("Fail to stat blocklist file %s, keep previous rules. err: %v", rs.source.Path, err)
+			next = append(next, rs)
+			continue
+		}
+		if info.ModTime().Equal(rs.modTime) {
+			next = append(next, rs)
+			continue
+		}
+
+		reloaded, err := load(rs.source)
+		if err != nil {
+			log.Errorf("Fail to reload blocklist file, keep previous rules. err: %v", err)
+			next = append(next, rs)
+			continue
+		}
+		log.Infof("Reloaded blocklist file %s", rs.source.Path)
+		next = append(next, reloaded)
+		changed = true
+	}
+
+	if changed {
+		s.ruleSets.Store(&next)
+	}
+}
+
+func load(source Source) (*ruleSet, error) {
+	info, err := os.Stat(source.Path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to stat blocklist file %s: %w", source.Path, err)
+	}
+	data, err := os.ReadFile(source.Path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read blocklist file %s: %w", source.Path, err)
+	}
+
+	var content fileContent
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("fail to parse blocklist file %s: %w", source.Path, err)
+	}
+
+	rs := &ruleSet{
+		source:     source,
+		modTime:    info.ModTime(),
+		productIDs: make(map[string]struct{}, len(content.ProductIDs)),
+		domains:    make([]string, 0, len(content.Domains)),
+		keywords:   make([]string, 0, len(content.Keywords)),
+	}
+	for _, id := range content.ProductIDs {
+		rs.productIDs[id] = struct{}{}
+	}
+	for _, domain := range content.Domains {
+		rs.domains = append(rs.domains, strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "."))
+	}
+	for _, keyword := range content.Keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			rs.keywords = append(rs.keywords, keyword)
+		}
+	}
+	return rs, nil
+}
+
+func (s Source) appliesTo(vendor, siteID string) bool {
+	if len(s.SiteIDs) > 0 && !slices.Contains(s.SiteIDs, siteID) {
+		return false
+	}
+	if len(s.Vendors) > 0 && !slices.Contains(s.Vendors, vendor) {
+		return false
+	}
+	return true
+}
+
+// matchDomain matches the domain itself and all of its subdomains
+func (rs *ruleSet) matchDomain(host string) bool {
+	for _, domain := range rs.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *ruleSet) matchKeyword(title string) bool {
+	for _, keyword := range rs.keywords {
+		if strings.Contains(title, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package blocklist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestStoreMatch(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := writeFile(t, dir, "global.yaml", `
+product_ids: ["111"]
+domains: ["blocked.com"]
+keywords: ["Casino"]
+`)
+	sitePath := writeFile(t, dir, "site.yaml", `
+domains: ["site-only.com"]
+`)
+	vendorPath := writeFile(t, dir, "vendor.yaml", `
+product_ids: ["222"]
+`)
+
+	store, err := NewStore([]Source{
+		{Path: globalPath},
+		{Path: sitePath, SiteIDs: []string{"site_a"}},
+		{Path: vendorPath, Vendors: []string{"linkmine"}},
+	}, 0)
+	require.NoError(t, err)
+	defer store.Close()
+
+	tt := []struct {
+		name       string
+		vendor     string
+		siteID     string
+		item       Item
+		wantReason string
+		wantMatch  bool
+	}{
+		{
+			name:       "GIVEN a globally blocked product ID THEN match by product ID",
+			vendor:     "adpacker",
+			item:       Item{ProductID: "111"},
+			wantReason: ReasonProductID,
+			wantMatch:  true,
+		},
+		{
+			name:       "GIVEN a subdomain of a blocked domain THEN match by domain",
+			vendor:     "adpacker",
+			item:       Item{ProductID: "1", LandingURL: "https://shop.blocked.com/item/1"},
+			wantReason: ReasonDomain,
+			wantMatch:  true,
+		},
+		{
+			name:       "GIVEN a blocked domain in the deep link of an affiliate URL THEN match by domain",
+			vendor:     "adpacker",
+			item:       Item{ProductID: "1", LandingURL: "https://tracker.com/ck?deep_link=https%3A%2F%2Fblocked.com%2Fitem"},
+			wantReason: ReasonDomain,
+			wantMatch:  true,
+		},
+		{
+			name:   "GIVEN a domain that only ends with the blocked domain THEN no match",
+			vendor: "adpacker",
+			item:   Item{ProductID: "1", LandingURL: "https://notblocked.com/item"},
+		},
+		{
+			name:       "GIVEN a title containing a keyword in another case THEN match by keyword",
+			vendor:     "adpacker",
+			item:       Item{ProductID: "1", Title: "Best CASINO chips"},
+			wantReason: ReasonKeyword,
+			wantMatch:  true,
+		},
+		{
+			name:       "GIVEN a site scoped domain and the matching site THEN match by domain",
+			vendor:     "adpacker",
+			siteID:     "site_a",
+			item:       Item{ProductID: "1", LandingURL: "https://site-only.com"},
+			wantReason: ReasonDomain,
+			wantMatch:  true,
+		},
+		{
+			name:   "GIVEN a site scoped domain and another site THEN no match",
+			vendor: "adpacker",
+			siteID: "site_b",
+			item:   Item{ProductID: "1", LandingURL: "https://site-only.com"},
+		},
+		{
+			name:       "GIVEN a vendor scoped product ID and the matching vendor THEN match by product ID",
+			vendor:     "linkmine",
+			item:       Item{ProductID: "222"},
+			wantReason: ReasonProductID,
+			wantMatch:  true,
+		},
+		{
+			name:   "GIVEN a vendor scoped product ID and another vendor THEN no match",
+			vendor: "adpacker",
+			item:   Item{ProductID: "222"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, matched := store.Match(tc.vendor, tc.siteID, tc.item)
+			require.Equal(t, tc.wantMatch, matched)
+			require.Equal(t, tc.wantReason, reason)
+		})
+	}
+}
+
+func TestStoreHasKeywords(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore([]Source{
+		{Path: writeFile(t, dir, "site.yaml", `keywords: ["casino"]`), SiteIDs: []string{"site_a"}, Vendors: []string{"keeta"}},
+		{Path: writeFile(t, dir, "vendor.yaml", `product_ids: ["222"]`), Vendors: []string{"linkmine"}},
+	}, 0)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.True(t, store.HasKeywords("keeta"))
+	require.False(t, store.HasKeywords("linkmine"))
+}
+
+func TestNewStoreError(t *testing.T) {
+	dir := t.TempDir()
+
+	tt := []struct {
+		name   string
+		source Source
+	}{
+		{
+			name:   "GIVEN a missing file THEN return error",
+			source: Source{Path: filepath.Join(dir, "missing.yaml")},
+		},
+		{
+			name:   "GIVEN an invalid file THEN return error",
+			source: Source{Path: writeFile(t, dir, "invalid.yaml", "product_ids: {")},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			store, err := NewStore([]Source{tc.source}, 0)
+			require.Error(t, err)
+			require.Nil(t, store)
+		})
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "global.yaml", `product_ids: ["111"]`)
+
+	store, err := NewStore([]Source{{Path: path}}, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, matched := store.Match("vendor", "site", Item{ProductID: "222"})
+	require.False(t, matched)
+
+	require.NoError(t, os.WriteFile(path, []byte(`product_ids: ["222"]`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	require.Eventually(t, func() bool {
+		_, matched := store.Match("vendor", "site", Item{ProductID: "222"})
+		return matched
+	}, time.Second, 10*time.Millisecond)
+
+	// an invalid update keeps the previous rules
+	require.NoError(t, os.WriteFile(path, []byte("product_ids: {"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	time.Sleep(50 * time.Millisecond)
+
+	_, matched = store.Match("vendor", "site", Item{ProductID: "222"})
+	require.True(t, matched)
+}
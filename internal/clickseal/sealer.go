@@ -0,0 +1,95 @@
+// Package clickseal encrypts the click IDs sent to the vendors with AES-GCM, so that they are opaque and
+// tamper-evident. The key ID prefixes the sealed click ID, so that the keys can be rotated.
+package clickseal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrMalformed  = errors.New("malformed sealed click id")
+	ErrUnknownKey = errors.New("unknown sealed click id key")
+	ErrInvalid    = errors.New("invalid sealed click id")
+)
+
+// Key is a base64 AES key of 16, 24 or 32 bytes, the ID must not contain a dot
+type Key struct {
+	ID  string
+	Key string
+}
+
+// Sealer seals with the first key and opens with any of the keys, a retired key is kept last until the
+// click IDs it sealed are no longer expected back
+type Sealer struct {
+	sealID string
+	aeads  map[string]cipher.AEAD
+}
+
+// NewSealer returns the sealer of the keys, nil if there is no key
+func NewSealer(keys []Key) (*Sealer, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	s := &Sealer{sealID: keys[0].ID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for _, k := range keys {
+		if k.ID == "" || strings.Contains(k.ID, ".") {
+			return nil, fmt.Errorf("invalid click seal key id '%s'", k.ID)
+		}
+		if _, ok := s.aeads[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate click seal key id %s", k.ID)
+		}
+		key, err := base64.StdEncoding.DecodeString(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("click seal key %s is not base64: %w", k.ID, err)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("click seal key %s: %w", k.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("click seal key %s: %w", k.ID, err)
+		}
+		s.aeads[k.ID] = aead
+	}
+	return s, nil
+}
+
+// Seal returns "<key id>.<base64url nonce and ciphertext>" of the click ID, the key ID is authenticated as well
+func (s *Sealer) Seal(clickID string) (string, error) {
+	aead := s.aeads[s.sealID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(clickID), []byte(s.sealID))
+	return s.sealID + "." + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open returns the click ID of a sealed click ID
+func (s *Sealer) Open(sealed string) (string, error) {
+	keyID, encoded, found := strings.Cut(sealed, ".")
+	if !found {
+		return "", ErrMalformed
+	}
+	aead, ok := s.aeads[keyID]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil || len(payload) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ciphertext := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+	clickID, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return "", ErrInvalid
+	}
+	return string(clickID), nil
+}
@@ -0,0 +1,103 @@
+package clickseal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	key1 = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	key2 = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func TestSealer(t *testing.T) {
+	sealer, err := NewSealer([]Key{{ID: "k2", Key: key2}, {ID: "k1", Key: key1}})
+	require.NoError(t, err)
+	previous, err := NewSealer([]Key{{ID: "k1", Key: key1}})
+	require.NoError(t, err)
+
+	sealed, err := sealer.Seal("click-1")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sealed, "k2."))
+	require.NotContains(t, sealed, "click-1")
+	other, err := sealer.Seal("click-1")
+	require.NoError(t, err)
+	require.NotEqual(t, sealed, other)
+
+	sealedByPrevious, err := previous.Seal("click-2")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		sealed  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "GIVEN a sealed click id THEN expect the click id",
+			sealed: sealed,
+			want:   "click-1",
+		},
+		{
+			name:   "GIVEN a click id sealed by a rotated key THEN expect the click id",
+			sealed: sealedByPrevious,
+			want:   "click-2",
+		},
+		{
+			name:    "GIVEN a plain click id THEN expect a malformed error",
+			sealed:  "click-1",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "GIVEN an unknown key id THEN expect an unknown key error",
+			sealed:  "k3" + strings.TrimPrefix(sealed, "k2"),
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name:    "GIVEN a swapped key id THEN expect an invalid error",
+			sealed:  "k1" + strings.TrimPrefix(sealed, "k2"),
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "GIVEN a tampered ciphertext THEN expect an invalid error",
+			sealed:  tamper(sealed),
+			wantErr: ErrInvalid,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := sealer.Open(tc.sealed)
+			require.ErrorIs(t, err, tc.wantErr)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewSealer(t *testing.T) {
+	sealer, err := NewSealer(nil)
+	require.NoError(t, err)
+	require.Nil(t, sealer)
+
+	for _, keys := range [][]Key{
+		{{ID: "k.1", Key: key1}},
+		{{ID: "k1", Key: key1}, {ID: "k1", Key: key2}},
+		{{ID: "k1", Key: "not base64"}},
+		{{ID: "k1", Key: "c2hvcnQ="}},
+	} {
+		_, err := NewSealer(keys)
+		require.Error(t, err, keys[0].ID)
+	}
+}
+
+// tamper flips a character in the middle of the ciphertext
+func tamper(sealed string) string {
+	i := len(sealed) / 2
+	c := byte('A')
+	if sealed[i] == c {
+		c = 'B'
+	}
+	return sealed[:i] + string(c) + sealed[i+1:]
+}
@@ -39,10 +39,17 @@ func Load(configPath string, cfg *Config) error {
 		return err
 	}
 
+	if err := ResolveReferences(cfg); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
 	validate := validator.New()
 	if err := validate.Struct(cfg); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
 	}
+	if err := cfg.ValidateFaultInjection(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
 
 	return nil
 }
@@ -1,23 +1,130 @@
 package config
 
 import (
+	"errors"
 	"time"
 
 	"github.com/plaxieappier/rec-go-kit/logkit"
 	"github.com/plaxieappier/rec-go-kit/tracekit"
 )
 
+const EnvironmentProduction = "production"
+
 type Config struct {
-	Logging         logkit.Config   `mapstructure:"logging"`
-	EnableGinLogger bool            `mapstructure:"enable_gin_logger"`
-	Tracing         tracekit.Config `mapstructure:"tracing"`
-	VendorConfig    VendorConfig    `mapstructure:"vendor_config"`
-	Grpc            GrpcConfig      `mapstructure:"grpc"`
+	Environment     string               `mapstructure:"environment" validate:"omitempty,oneof=dev staging production"`
+	Logging         logkit.Config        `mapstructure:"logging"`
+	EnableGinLogger bool                 `mapstructure:"enable_gin_logger"`
+	Tracing         tracekit.Config      `mapstructure:"tracing"`
+	VendorConfig    VendorConfig         `mapstructure:"vendor_config"`
+	Grpc            GrpcConfig           `mapstructure:"grpc"`
+	OpenRTB         OpenRTBConfig        `mapstructure:"openrtb"`
+	Events          EventsConfig         `mapstructure:"events"`
+	FaultInjection  FaultInjectionConfig `mapstructure:"fault_injection"`
+	Redaction       RedactionConfig      `mapstructure:"redaction"`
+	Auth            AuthConfig           `mapstructure:"auth"`
+}
+
+// ValidateFaultInjection refuses fault injection in a config flagged as production
+func (c *Config) ValidateFaultInjection() error {
+	if c.FaultInjection.Enabled && c.Environment == EnvironmentProduction {
+		return errors.New("fault_injection can not be enabled in the production environment")
+	}
+	return nil
 }
+
 type GrpcConfig struct {
 	MaxConnectionAge  time.Duration `mapstructure:"max_connection_age"`
 	WriteBufferSizeKb int           `mapstructure:"write_buffer_size_kb"`
 	ReadBufferSizeKb  int           `mapstructure:"read_buffer_size_kb"`
+	TLS               GrpcTLSConfig `mapstructure:"tls"`
+}
+
+// GrpcTLSConfig serves gRPC over TLS if set. With client_ca_file, the client certificates are verified if given,
+// and identify their caller by their common name.
+type GrpcTLSConfig struct {
+	CertFile     string `mapstructure:"cert_file" validate:"required_with=KeyFile"`
+	KeyFile      string `mapstructure:"key_file" validate:"required_with=CertFile"`
+	ClientCAFile string `mapstructure:"client_ca_file" validate:"excluded_without=CertFile"`
+}
+
+// AuthConfig authenticates the callers of the recommendation and vendor endpoints, it is disabled by default.
+// A caller is identified by one of its API keys, in the header of the same name for HTTP and gRPC (x-api-key by
+// default), or by the common name of its gRPC client certificate. The exempt paths and gRPC methods, /healthz,
+// /metrics and the gRPC health check by default, are not authenticated.
+type AuthConfig struct {
+	Enabled     bool         `mapstructure:"enabled"`
+	Header      string       `mapstructure:"header"`
+	ExemptPaths []string     `mapstructure:"exempt_paths"`
+	Callers     []AuthCaller `mapstructure:"callers" validate:"dive"`
+}
+
+// AuthCaller without vendors can request all the vendor keys
+type AuthCaller struct {
+	Name        string   `mapstructure:"name" validate:"required"`
+	APIKeys     []Secret `mapstructure:"api_keys"`
+	CommonNames []string `mapstructure:"common_names"`
+	Vendors     []string `mapstructure:"vendors"`
+}
+
+type OpenRTBConfig struct {
+	ImpTrackers []string `mapstructure:"imp_trackers" validate:"dive,url"`
+}
+
+// EventsConfig emits no events unless a sink is set
+type EventsConfig struct {
+	Sink          string          `mapstructure:"sink" validate:"omitempty,oneof=file stdout http"`
+	BufferSize    int             `mapstructure:"buffer_size" validate:"gte=0"`
+	BatchSize     int             `mapstructure:"batch_size" validate:"gte=0"`
+	FlushInterval time.Duration   `mapstructure:"flush_interval"`
+	File          EventFileConfig `mapstructure:"file"`
+	HTTP          EventHTTPConfig `mapstructure:"http"`
+}
+
+type EventFileConfig struct {
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb" validate:"gte=0"`
+	MaxBackups int    `mapstructure:"max_backups" validate:"gte=0"`
+}
+
+type EventHTTPConfig struct {
+	URL     string        `mapstructure:"url" validate:"omitempty,url"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// FaultInjectionConfig injects vendor faults for testing, it is disabled by default. With allow_header, a
+// request can also set its own fault with the x-fault-inject header.
+type FaultInjectionConfig struct {
+	Enabled     bool        `mapstructure:"enabled"`
+	AllowHeader bool        `mapstructure:"allow_header"`
+	Rules       []FaultRule `mapstructure:"rules" validate:"dive"`
+}
+
+// FaultRule without vendors applies to all vendors, the first matching rule is used
+type FaultRule struct {
+	Vendors             []string      `mapstructure:"vendors"`
+	Percent             float64       `mapstructure:"percent" validate:"gte=0,lte=100"`
+	Latency             time.Duration `mapstructure:"latency" validate:"gte=0"`
+	LatencyMax          time.Duration `mapstructure:"latency_max" validate:"gte=0"`
+	LatencyDistribution string        `mapstructure:"latency_distribution" validate:"omitempty,oneof=fixed uniform exponential"`
+	Error               string        `mapstructure:"error" validate:"omitempty,oneof=timeout connection_reset http_status unknown"`
+	HTTPStatus          int           `mapstructure:"http_status" validate:"required_if=Error http_status"`
+	Body                string        `mapstructure:"body" validate:"omitempty,oneof=truncated malformed empty"`
+}
+
+// RedactionConfig masks the user identifiers and secrets in the logs, the params, headers and fields are added to
+// the defaults of the redact package. The bodies are truncated to max_body_size bytes, 1024 by default.
+type RedactionConfig struct {
+	QueryParams []string         `mapstructure:"query_params"`
+	Headers     []string         `mapstructure:"headers"`
+	JSONFields  []string         `mapstructure:"json_fields"`
+	MaxBodySize int              `mapstructure:"max_body_size" validate:"gte=0"`
+	Debug       []RedactionDebug `mapstructure:"debug" validate:"dive"`
+}
+
+// RedactionDebug logs the vendor unredacted until the RFC 3339 time, at most 24 hours after the service start
+type RedactionDebug struct {
+	Vendor string `mapstructure:"vendor" validate:"required"`
+	Until  string `mapstructure:"until" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
 }
 
 type PortConfig struct {
@@ -27,25 +134,39 @@ type PortConfig struct {
 }
 
 type VendorConfig struct {
-	ProxyURL string        `mapstructure:"proxy_url"`
-	Timeout  time.Duration `mapstructure:"timeout"`
-	Vendors  []Vendor      `mapstructure:"vendors" validate:"dive"`
+	ProxyURL    Secret          `mapstructure:"proxy_url"`
+	Timeout     time.Duration   `mapstructure:"timeout"`
+	Vendors     []Vendor        `mapstructure:"vendors" validate:"dive"`
+	Blocklist   BlocklistConfig `mapstructure:"blocklist"`
+	FreqCap     FreqCapConfig   `mapstructure:"freq_cap"`
+	Click       ClickConfig     `mapstructure:"click"`
+	Postback    PostbackConfig  `mapstructure:"postback"`
+	Tape        TapeConfig      `mapstructure:"tape"`
+	URLDenylist URLDenylist     `mapstructure:"url_denylist"`
+	Redis       RedisConfig     `mapstructure:"redis"`
 }
 
 type Vendor struct {
-	Name         string     `mapstructure:"name"`
-	WithProxy    bool       `mapstructure:"with_proxy"`
-	HTTPMethod   string     `mapstructure:"http_method" validate:"oneof=GET POST"`
-	AccessKey    string     `mapstructure:"access_key"`
-	SecretKey    string     `mapstructure:"secret_key"`
-	UserAgent    string     `mapstructure:"user_agent"`
-	SceneType    string     `mapstructure:"scene_type"`
-	Ver          string     `mapstructure:"ver"`
-	ChannelToken string     `mapstructure:"channel_token"`
-	SCaApp       string     `mapstructure:"s_ca_app"`
-	SCaSecret    string     `mapstructure:"s_ca_secret"`
-	Request      URLPattern `mapstructure:"request"`
-	Tracking     URLPattern `mapstructure:"tracking"`
+	Name            string       `mapstructure:"name"`
+	WithProxy       bool         `mapstructure:"with_proxy"`
+	HTTPMethod      string       `mapstructure:"http_method" validate:"oneof=GET POST"`
+	AccessKey       Secret       `mapstructure:"access_key"`
+	SecretKey       Secret       `mapstructure:"secret_key"`
+	UserAgent       string       `mapstructure:"user_agent"`
+	SceneType       string       `mapstructure:"scene_type"`
+	Ver             string       `mapstructure:"ver"`
+	ChannelToken    Secret       `mapstructure:"channel_token"`
+	SCaApp          Secret       `mapstructure:"s_ca_app"`
+	SCaSecret       Secret       `mapstructure:"s_ca_secret"`
+	Request         URLPattern   `mapstructure:"request"`
+	Tracking        URLPattern   `mapstructure:"tracking"`
+	Response        Response     `mapstructure:"response"`
+	PostProcess     PostProcess  `mapstructure:"post_process"`
+	URLAllowlist    URLAllowlist `mapstructure:"url_allowlist"`
+	Ranker          Ranker       `mapstructure:"ranker"`
+	FirstPartyClick bool         `mapstructure:"first_party_click"`
+	ImpressionURL   bool         `mapstructure:"impression_url"`
+	Postback        Postback     `mapstructure:"postback"`
 }
 
 type URLPattern struct {
@@ -53,7 +174,161 @@ type URLPattern struct {
 	Queries []Query `mapstructure:"queries,omitempty"`
 }
 
+// Query of a URL pattern, its value is secret if it was resolved from a reference
 type Query struct {
 	Key   string `mapstructure:"key"`
 	Value string `mapstructure:"value"`
+
+	secret bool
+}
+
+func (q Query) IsSecret() bool {
+	return q.secret
+}
+
+// Response maps the response of a vendor without a dedicated unmarshaler, it is used if id is set.
+// The paths are dot-separated JSON keys: items is the path of the product list, empty for a top-level list,
+// and the fields are paths in each product.
+type Response struct {
+	Items     string `mapstructure:"items"`
+	ID        string `mapstructure:"id"`
+	URL       string `mapstructure:"url" validate:"required_with=ID"`
+	Image     string `mapstructure:"image"`
+	Title     string `mapstructure:"title"`
+	Price     string `mapstructure:"price"`
+	SalePrice string `mapstructure:"sale_price"`
+	Currency  string `mapstructure:"currency"`
+}
+
+type PostProcess struct {
+	Stages   []PostProcessStage `mapstructure:"stages" validate:"dive"`
+	MinItems int                `mapstructure:"min_items" validate:"gte=0"`
+}
+
+type PostProcessStage struct {
+	Type   string   `mapstructure:"type" validate:"oneof=dedupe required_fields force_https max_items"`
+	Fields []string `mapstructure:"fields" validate:"dive,oneof=id url image price"`
+	Limit  int      `mapstructure:"limit" validate:"gte=0"`
+}
+
+// URLAllowlist restricts the product and image URLs of a vendor before the tracking URLs are generated, the
+// items with another scheme or domain are dropped. A domain allows its subdomains, empty lists allow any.
+// The schemes default to http and https, an app scheme allows the deep links of the product URLs.
+type URLAllowlist struct {
+	Schemes      []string `mapstructure:"schemes" validate:"dive,required,lowercase,ne=javascript,ne=vbscript,ne=data,ne=file,ne=blob,ne=about"`
+	Domains      []string `mapstructure:"domains"`
+	ImageDomains []string `mapstructure:"image_domains"`
+}
+
+// URLDenylist refuses the URLs whose host is in the CIDRs or domains, on top of the private IP ranges and
+// localhost that are always refused
+type URLDenylist struct {
+	CIDRs   []string `mapstructure:"cidrs" validate:"dive,cidr"`
+	Domains []string `mapstructure:"domains"`
+}
+
+type BlocklistConfig struct {
+	ReloadInterval time.Duration   `mapstructure:"reload_interval"`
+	Files          []BlocklistFile `mapstructure:"files" validate:"dive"`
+}
+
+// BlocklistFile without site IDs and vendors applies to all requests
+type BlocklistFile struct {
+	Path    string   `mapstructure:"path" validate:"required"`
+	SiteIDs []string `mapstructure:"site_ids"`
+	Vendors []string `mapstructure:"vendors"`
+}
+
+// ClickConfig configures the first-party click redirect and impression beacon, used by vendors with
+// first_party_click and impression_url
+type ClickConfig struct {
+	BaseURL    string        `mapstructure:"base_url" validate:"omitempty,url"`
+	SigningKey Secret        `mapstructure:"signing_key" validate:"required_with=BaseURL"`
+	TokenTTL   time.Duration `mapstructure:"token_ttl" validate:"gte=0"`
+	// ImpressionDedupe* drop the impression beacons replayed with the same token within the window
+	ImpressionDedupeWindow     time.Duration `mapstructure:"impression_dedupe_window" validate:"gte=0"`
+	ImpressionDedupeStore      string        `mapstructure:"impression_dedupe_store" validate:"omitempty,oneof=memory redis"`
+	ImpressionDedupeMaxEntries int           `mapstructure:"impression_dedupe_max_entries" validate:"gte=0"`
+	// SealKeys encrypt the {click_id_sealed} macro, the first key seals and all of them open
+	SealKeys []ClickSealKey `mapstructure:"seal_keys" validate:"dive"`
+}
+
+// ClickSealKey is a base64 AES key of 16, 24 or 32 bytes, its ID prefixes the sealed click IDs
+type ClickSealKey struct {
+	ID  string `mapstructure:"id" validate:"required,alphanum"`
+	Key Secret `mapstructure:"key" validate:"required"`
+}
+
+// PostbackConfig deduplicates the orders per pod with the memory store, the redis store is shared by the pods
+type PostbackConfig struct {
+	DedupeWindow time.Duration `mapstructure:"dedupe_window"`
+	Store        string        `mapstructure:"store" validate:"omitempty,oneof=memory redis"`
+	MaxEntries   int           `mapstructure:"max_entries" validate:"gte=0"`
+	// TrustedProxies are the peers whose X-Forwarded-For is used for the postback allowed_ips, IPs or CIDRs
+	TrustedProxies []string `mapstructure:"trusted_proxies" validate:"dive,cidr|ip"`
+}
+
+// Postback maps the conversion postback parameters of a vendor, it is enabled by click_id_param.
+// The postbacks are authenticated by the secret, the allowed IPs, or both.
+type Postback struct {
+	ClickIDParam    string   `mapstructure:"click_id_param"`
+	ClickIDEncoding string   `mapstructure:"click_id_encoding" validate:"omitempty,oneof=base64 raw sealed"`
+	OrderIDParam    string   `mapstructure:"order_id_param" validate:"required_with=ClickIDParam"`
+	RevenueParam    string   `mapstructure:"revenue_param"`
+	CurrencyParam   string   `mapstructure:"currency_param"`
+	Secret          Secret   `mapstructure:"secret"`
+	SecretParam     string   `mapstructure:"secret_param"`
+	AllowedIPs      []string `mapstructure:"allowed_ips" validate:"dive,cidr|ip"`
+}
+
+// TapeConfig records the vendor exchanges to <dir>/<vendor>.json, or replays them without calling the vendors.
+// Recording stops at max_exchanges per vendor, 0 records without limit.
+type TapeConfig struct {
+	Mode         string `mapstructure:"mode" validate:"omitempty,oneof=record replay"`
+	Dir          string `mapstructure:"dir" validate:"required_with=Mode"`
+	MaxExchanges int    `mapstructure:"max_exchanges" validate:"gte=0"`
+}
+
+// FreqCapConfig records on serve by default, or on impression beacons with record_on impression.
+// The memory store counts per pod, the redis store is shared by the pods.
+type FreqCapConfig struct {
+	Store         string        `mapstructure:"store" validate:"omitempty,oneof=memory redis"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval" validate:"gte=0"`
+	MaxEntries    int           `mapstructure:"max_entries" validate:"gte=0"`
+	RecordOn      string        `mapstructure:"record_on" validate:"omitempty,oneof=serve impression"`
+	Rules         []FreqCapRule `mapstructure:"rules" validate:"dive"`
+}
+
+// FreqCapRule without site IDs and vendors applies to all requests, the first matching rule is used
+type FreqCapRule struct {
+	Vendors        []string      `mapstructure:"vendors"`
+	SiteIDs        []string      `mapstructure:"site_ids"`
+	MaxImpressions int           `mapstructure:"max_impressions" validate:"gt=0"`
+	Window         time.Duration `mapstructure:"window" validate:"gt=0"`
+}
+
+// RedisConfig is the redis of the stores set to redis
+type RedisConfig struct {
+	Addr     string        `mapstructure:"addr" validate:"omitempty,hostname_port"`
+	Password Secret        `mapstructure:"password"`
+	DB       int           `mapstructure:"db" validate:"gte=0"`
+	PoolSize int           `mapstructure:"pool_size" validate:"gte=0"`
+	Timeout  time.Duration `mapstructure:"timeout" validate:"gte=0"`
+}
+
+// Ranker reorders the vendor products, if arms are set the users are split between them instead
+type Ranker struct {
+	RankerSpec `mapstructure:",squash"`
+	Arms       []RankerArm `mapstructure:"arms" validate:"dive"`
+}
+
+type RankerArm struct {
+	Name       string `mapstructure:"name" validate:"required"`
+	Weight     int    `mapstructure:"weight" validate:"gt=0"`
+	RankerSpec `mapstructure:",squash"`
+}
+
+type RankerSpec struct {
+	Type      string `mapstructure:"type" validate:"omitempty,oneof=vendor_order price_asc price_desc discount_first shuffle weighted_score"`
+	ScoreFile string `mapstructure:"score_file" validate:"required_if=Type weighted_score"`
 }
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLAllowlistSchemes(t *testing.T) {
+	validate := validator.New()
+
+	require.NoError(t, validate.Struct(URLAllowlist{Schemes: []string{"https", "keeta"}}))
+	require.Error(t, validate.Struct(URLAllowlist{Schemes: []string{"javascript"}}))
+	require.Error(t, validate.Struct(URLAllowlist{Schemes: []string{"Keeta"}}))
+	require.Error(t, validate.Struct(URLAllowlist{Schemes: []string{""}}))
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const secretMask = "****"
+
+// Secret is a config value that is never printed: fmt, logrus and JSON get it masked, only Value returns it
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return secretMask
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// String masks the value of a secret query
+func (q Query) String() string {
+	if q.secret {
+		return q.Key + "=" + secretMask
+	}
+	return q.Key + "=" + q.Value
+}
+
+func (q Query) MarshalJSON() ([]byte, error) {
+	value := q.Value
+	if q.secret {
+		value = secretMask
+	}
+	return json.Marshal(struct{ Key, Value string }{q.Key, value})
+}
+
+// SecretValues returns the values of the vendor credentials and of its secret queries
+func (v Vendor) SecretValues() []string {
+	var values []string
+	for _, s := range []Secret{v.AccessKey, v.SecretKey, v.SCaApp, v.SCaSecret, v.ChannelToken, v.Postback.Secret} {
+		if s != "" {
+			values = append(values, s.Value())
+		}
+	}
+	for _, queries := range [][]Query{v.Request.Queries, v.Tracking.Queries} {
+		for _, q := range queries {
+			if q.IsSecret() && q.Value != "" {
+				values = append(values, q.Value)
+			}
+		}
+	}
+	return values
+}
+
+var (
+	// referenceRegExp matches a value that is a whole reference, e.g. ${env:KEETA_SECRET} or
+	// ${file:/var/run/secrets/keeta/secret}
+	referenceRegExp = regexp.MustCompile(`^\$\{(env|file):([^}]+)\}$`)
+
+	queryType = reflect.TypeOf(Query{})
+)
+
+// CheckReference returns an error if the value looks like a reference, i.e. starts with ${, but is not one
+func CheckReference(value string) error {
+	if strings.HasPrefix(value, "${") && !referenceRegExp.MatchString(value) {
+		return fmt.Errorf("invalid reference %s, expected ${env:NAME} or ${file:/path}", value)
+	}
+	return nil
+}
+
+// resolveReference returns the value of the reference, and false if the value is not a reference
+func resolveReference(value string) (string, bool, error) {
+	if err := CheckReference(value); err != nil {
+		return "", true, err
+	}
+	m := referenceRegExp.FindStringSubmatch(value)
+	if m == nil {
+		return value, false, nil
+	}
+
+	var resolved string
+	switch m[1] {
+	case "env":
+		resolved = os.Getenv(m[2])
+		if resolved == "" {
+			return "", true, fmt.Errorf("unresolved reference %s: environment variable not set", value)
+		}
+	case "file":
+		b, err := os.ReadFile(m[2])
+		if err != nil {
+			return "", true, fmt.Errorf("unresolved reference %s: %w", value, err)
+		}
+		// secret files usually end with a newline
+		resolved = strings.TrimSpace(string(b))
+		if resolved == "" {
+			return "", true, fmt.Errorf("unresolved reference %s: empty file", value)
+		}
+	}
+	return resolved, true, nil
+}
+
+// ResolveReferences replaces the ${env:...} and ${file:...} references of the string fields by their values,
+// and marks the query values resolved from a reference as secret. All the unresolved references are returned.
+func ResolveReferences(cfg any) error {
+	return errors.Join(resolveValue(reflect.ValueOf(cfg), "")...)
+}
+
+func resolveValue(v reflect.Value, path string) []error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveValue(v.Elem(), path)
+	case reflect.Struct:
+		var errs []error
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+			fieldPath := path
+			if !strings.Contains(opts, "squash") {
+				fieldPath = joinPath(path, name)
+			}
+			errs = append(errs, resolveValue(v.Field(i), fieldPath)...)
+		}
+		return errs
+	case reflect.Slice:
+		if v.Type().Elem() == queryType {
+			return resolveQueries(v.Interface().([]Query), path)
+		}
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, resolveValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case reflect.String:
+		resolved, ok, err := resolveReference(v.String())
+		if err != nil {
+			return []error{fmt.Errorf("%s: %w", path, err)}
+		}
+		if ok && v.CanSet() {
+			v.SetString(resolved)
+		}
+	}
+	return nil
+}
+
+func resolveQueries(queries []Query, path string) []error {
+	var errs []error
+	for i := range queries {
+		resolved, ok, err := resolveReference(queries[i].Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s[%d].value: %w", path, i, err))
+			continue
+		}
+		if ok {
+			queries[i].Value, queries[i].secret = resolved, true
+		}
+	}
+	return errs
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
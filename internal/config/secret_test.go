@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	s := Secret("sk-123")
+	require.Equal(t, "sk-123", s.Value())
+	require.Equal(t, "****", fmt.Sprint(s))
+	require.Equal(t, `"****"`, fmt.Sprintf("%#v", s))
+
+	b, err := json.Marshal(Vendor{Name: "replace", SecretKey: s})
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "sk-123")
+
+	require.Equal(t, "", Secret("").String())
+
+	q := Query{Key: "token", Value: "tk-123", secret: true}
+	require.Equal(t, "[token=**** subid={subid}]", fmt.Sprint([]Query{q, {Key: "subid", Value: "{subid}"}}))
+	b, err = json.Marshal(q)
+	require.NoError(t, err)
+	require.Equal(t, `{"Key":"token","Value":"****"}`, string(b))
+}
+
+func TestResolveReferences(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-secret\n"), 0o600))
+	t.Setenv("TEST_ACCESS_KEY", "env-key")
+
+	tt := []struct {
+		name      string
+		vendor    Vendor
+		want      Vendor
+		wantError []string
+	}{
+		{
+			name: "GIVEN env and file references THEN expect their values and the query marked as secret",
+			vendor: Vendor{
+				Name:      "replace",
+				AccessKey: "${env:TEST_ACCESS_KEY}",
+				SecretKey: Secret("${file:" + secretFile + "}"),
+				Request:   URLPattern{Queries: []Query{{Key: "token", Value: "${env:TEST_ACCESS_KEY}"}, {Key: "subid", Value: "{subid}"}}},
+			},
+			want: Vendor{
+				Name:      "replace",
+				AccessKey: "env-key",
+				SecretKey: "file-secret",
+				Request:   URLPattern{Queries: []Query{{Key: "token", Value: "env-key", secret: true}, {Key: "subid", Value: "{subid}"}}},
+			},
+		},
+		{
+			name:   "GIVEN inline values THEN expect them unchanged",
+			vendor: Vendor{Name: "keeta", SCaApp: "app", SCaSecret: "secret"},
+			want:   Vendor{Name: "keeta", SCaApp: "app", SCaSecret: "secret"},
+		},
+		{
+			name: "GIVEN unresolved and malformed references THEN expect an error per field",
+			vendor: Vendor{
+				AccessKey: "${env:TEST_MISSING_KEY}",
+				SecretKey: Secret("${file:" + filepath.Join(t.TempDir(), "missing") + "}"),
+				Tracking:  URLPattern{Queries: []Query{{Key: "token", Value: "${vault:token}"}}},
+			},
+			wantError: []string{
+				"vendor_config.vendors[0].access_key: unresolved reference ${env:TEST_MISSING_KEY}: environment variable not set",
+				"vendor_config.vendors[0].secret_key: unresolved reference ${file:",
+				"vendor_config.vendors[0].tracking.queries[0].value: invalid reference ${vault:token}",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{VendorConfig: VendorConfig{Vendors: []Vendor{tc.vendor}}}
+			err := ResolveReferences(cfg)
+			if len(tc.wantError) > 0 {
+				require.Error(t, err)
+				for _, want := range tc.wantError {
+					require.Contains(t, err.Error(), want)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, cfg.VendorConfig.Vendors[0])
+		})
+	}
+}
+
+func TestSecretValues(t *testing.T) {
+	v := Vendor{
+		AccessKey: "ak",
+		Postback:  Postback{Secret: "ps"},
+		Request:   URLPattern{Queries: []Query{{Key: "token", Value: "tk", secret: true}, {Key: "subid", Value: "{subid}"}}},
+	}
+	require.Equal(t, []string{"ak", "ps", "tk"}, v.SecretValues())
+}
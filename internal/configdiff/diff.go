@@ -0,0 +1,233 @@
+// Package configdiff compares two revisions of the vendor configs by meaning rather than by YAML lines
+package configdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+)
+
+// Change kinds
+const (
+	KindAdded         = "added"
+	KindRemoved       = "removed"
+	KindChanged       = "changed"
+	KindSecretChanged = "secret_changed"
+)
+
+// secretFields are only reported as changed, never with their values
+var secretFields = map[string]bool{
+	"access_key":      true,
+	"secret_key":      true,
+	"s_ca_app":        true,
+	"s_ca_secret":     true,
+	"channel_token":   true,
+	"postback.secret": true,
+}
+
+type Report struct {
+	Added   []string       `json:"added"`
+	Removed []string       `json:"removed"`
+	Changed []VendorChange `json:"changed"`
+}
+
+type VendorChange struct {
+	Vendor  string   `json:"vendor"`
+	Changes []Change `json:"changes"`
+}
+
+// Change of a field, such as http_method or request.queries.subid. The values of secrets are left empty.
+type Change struct {
+	Field string `json:"field"`
+	Kind  string `json:"kind"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Diff compares the vendors by name. The queries are compared by key, regardless of their order, since
+// the URL strategy sets them by key.
+func Diff(oldVendors, newVendors []config.Vendor) Report {
+	report := Report{Added: []string{}, Removed: []string{}, Changed: []VendorChange{}}
+	oldByName := byName(oldVendors)
+	newByName := byName(newVendors)
+
+	for _, v := range newVendors {
+		if _, ok := oldByName[v.Name]; !ok {
+			report.Added = append(report.Added, v.Name)
+		}
+	}
+	for _, v := range oldVendors {
+		newVendor, ok := newByName[v.Name]
+		if !ok {
+			report.Removed = append(report.Removed, v.Name)
+			continue
+		}
+		if changes := diffFields(flatten(v), flatten(newVendor)); len(changes) > 0 {
+			report.Changed = append(report.Changed, VendorChange{Vendor: v.Name, Changes: changes})
+		}
+	}
+	return report
+}
+
+func byName(vendors []config.Vendor) map[string]config.Vendor {
+	m := make(map[string]config.Vendor, len(vendors))
+	for _, v := range vendors {
+		m[v.Name] = v
+	}
+	return m
+}
+
+// diffFields reports the changes of the fields, the fields holding the value of a secret, such as a query
+// with the channel token, are reported as secrets too
+func diffFields(oldFields, newFields map[string]string) []Change {
+	var secrets []string
+	for field := range secretFields {
+		for _, fields := range []map[string]string{oldFields, newFields} {
+			if v := fields[field]; v != "" {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+	isSecret := func(field string) bool {
+		if secretFields[field] {
+			return true
+		}
+		for _, secret := range secrets {
+			if strings.Contains(oldFields[field], secret) || strings.Contains(newFields[field], secret) {
+				return true
+			}
+		}
+		return false
+	}
+
+	fields := make([]string, 0, len(oldFields)+len(newFields))
+	for field := range oldFields {
+		fields = append(fields, field)
+	}
+	for field := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var changes []Change
+	for _, field := range fields {
+		oldValue, inOld := oldFields[field]
+		newValue, inNew := newFields[field]
+		var c Change
+		switch {
+		case inOld && inNew && oldValue == newValue:
+			continue
+		case !inOld:
+			c = Change{Field: field, Kind: KindAdded, New: newValue}
+		case !inNew:
+			c = Change{Field: field, Kind: KindRemoved, Old: oldValue}
+		default:
+			c = Change{Field: field, Kind: KindChanged, Old: oldValue, New: newValue}
+		}
+		if isSecret(field) {
+			c = Change{Field: field, Kind: KindSecretChanged}
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// flatten returns the non-zero fields of the vendor by their mapstructure path, e.g. request.url. The queries
+// are keyed by their key, e.g. request.queries.subid, and the other lists by their index.
+func flatten(v config.Vendor) map[string]string {
+	fields := map[string]string{}
+	flattenValue(fields, "", reflect.ValueOf(v))
+	return fields
+}
+
+var queryType = reflect.TypeOf(config.Query{})
+
+func flattenValue(fields map[string]string, prefix string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, opts, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+			switch {
+			case strings.Contains(opts, "squash"):
+				flattenValue(fields, prefix, v.Field(i))
+			case name != "" && name != "-":
+				flattenValue(fields, join(prefix, name), v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem() == queryType {
+			for i := 0; i < v.Len(); i++ {
+				q := v.Index(i).Interface().(config.Query)
+				// the last query of a key wins, as with url.Values.Set
+				fields[join(prefix, q.Key)] = q.Value
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			flattenValue(fields, fmt.Sprintf("%s[%d]", prefix, i), v.Index(i))
+		}
+	case reflect.String:
+		// the values of config.Secret, which prints masked, are compared too
+		if v.String() != "" {
+			fields[prefix] = v.String()
+		}
+	default:
+		if !v.IsZero() {
+			fields[prefix] = fmt.Sprint(v.Interface())
+		}
+	}
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// WriteText writes the report for humans, one line per change
+func (r Report) WriteText(w io.Writer) {
+	if r.Empty() {
+		fmt.Fprintln(w, "no vendor changes")
+		return
+	}
+	for _, name := range r.Added {
+		fmt.Fprintf(w, "+ vendor %s\n", name)
+	}
+	for _, name := range r.Removed {
+		fmt.Fprintf(w, "- vendor %s\n", name)
+	}
+	for _, vc := range r.Changed {
+		fmt.Fprintf(w, "~ vendor %s\n", vc.Vendor)
+		for _, c := range vc.Changes {
+			switch c.Kind {
+			case KindAdded:
+				fmt.Fprintf(w, "    + %s: %q\n", c.Field, c.New)
+			case KindRemoved:
+				fmt.Fprintf(w, "    - %s: %q\n", c.Field, c.Old)
+			case KindChanged:
+				fmt.Fprintf(w, "    ~ %s: %q -> %q\n", c.Field, c.Old, c.New)
+			case KindSecretChanged:
+				fmt.Fprintf(w, "    ~ %s: secret changed\n", c.Field)
+			}
+		}
+	}
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,129 @@
+package configdiff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	base := config.Vendor{
+		Name:       "keeta",
+		HTTPMethod: "GET",
+		SCaSecret:  "secret-v1",
+		Request: config.URLPattern{URL: "https://keeta.example.com/reco", Queries: []config.Query{
+			{Key: "reqId", Value: "{click_id}"},
+			{Key: "lat", Value: "{latitude}"},
+			{Key: "channelToken", Value: "token-v1"},
+		}},
+		ChannelToken: "token-v1",
+	}
+
+	tt := []struct {
+		name   string
+		old    []config.Vendor
+		new    []config.Vendor
+		want   Report
+		modify func(v *config.Vendor)
+	}{
+		{
+			name: "GIVEN reordered queries THEN expect no change",
+			modify: func(v *config.Vendor) {
+				v.Request.Queries = []config.Query{v.Request.Queries[2], v.Request.Queries[0], v.Request.Queries[1]}
+			},
+			want: Report{Added: []string{}, Removed: []string{}, Changed: []VendorChange{}},
+		},
+		{
+			name: "GIVEN changed method, proxy and queries THEN expect the changes by field",
+			modify: func(v *config.Vendor) {
+				v.HTTPMethod = "POST"
+				v.WithProxy = true
+				v.Request.Queries = []config.Query{
+					{Key: "reqId", Value: "{click_id_base64}"},
+					{Key: "lon", Value: "{longitude}"},
+					{Key: "channelToken", Value: "token-v1"},
+				}
+				v.PostProcess.Stages = []config.PostProcessStage{{Type: "dedupe"}}
+			},
+			want: Report{Added: []string{}, Removed: []string{}, Changed: []VendorChange{{Vendor: "keeta", Changes: []Change{
+				{Field: "http_method", Kind: KindChanged, Old: "GET", New: "POST"},
+				{Field: "post_process.stages[0].type", Kind: KindAdded, New: "dedupe"},
+				{Field: "request.queries.lat", Kind: KindRemoved, Old: "{latitude}"},
+				{Field: "request.queries.lon", Kind: KindAdded, New: "{longitude}"},
+				{Field: "request.queries.reqId", Kind: KindChanged, Old: "{click_id}", New: "{click_id_base64}"},
+				{Field: "with_proxy", Kind: KindAdded, New: "true"},
+			}}}},
+		},
+		{
+			name: "GIVEN rotated credentials THEN expect secret changes without values",
+			modify: func(v *config.Vendor) {
+				v.SCaSecret = "secret-v2"
+				v.ChannelToken = "token-v2"
+				v.Request.Queries[2].Value = "token-v2"
+			},
+			want: Report{Added: []string{}, Removed: []string{}, Changed: []VendorChange{{Vendor: "keeta", Changes: []Change{
+				{Field: "channel_token", Kind: KindSecretChanged},
+				{Field: "request.queries.channelToken", Kind: KindSecretChanged},
+				{Field: "s_ca_secret", Kind: KindSecretChanged},
+			}}}},
+		},
+		{
+			name: "GIVEN added and removed vendors THEN expect them by name",
+			old:  []config.Vendor{{Name: "linkmine"}},
+			new:  []config.Vendor{{Name: "adforus"}},
+			want: Report{Added: []string{"adforus"}, Removed: []string{"linkmine"}, Changed: []VendorChange{}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			oldVendors, newVendors := tc.old, tc.new
+			if tc.modify != nil {
+				modified := base
+				modified.Request.Queries = append([]config.Query(nil), base.Request.Queries...)
+				tc.modify(&modified)
+				oldVendors, newVendors = []config.Vendor{base}, []config.Vendor{modified}
+			}
+			require.Equal(t, tc.want, Diff(oldVendors, newVendors))
+		})
+	}
+}
+
+func TestReportWriteText(t *testing.T) {
+	report := Report{
+		Added:   []string{"adforus"},
+		Removed: []string{"binalab"},
+		Changed: []VendorChange{{Vendor: "keeta", Changes: []Change{
+			{Field: "http_method", Kind: KindChanged, Old: "GET", New: "POST"},
+			{Field: "s_ca_secret", Kind: KindSecretChanged},
+		}}},
+	}
+	var buf bytes.Buffer
+	report.WriteText(&buf)
+	require.Equal(t, `+ vendor adforus
+- vendor binalab
+~ vendor keeta
+    ~ http_method: "GET" -> "POST"
+    ~ s_ca_secret: secret changed
+`, buf.String())
+}
+
+func TestLoadVendorsFromTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vendors.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`vendors:
+  - name: replace
+    {{- with secret "secret/project/recommendation/rec-serving/replace" }}
+    access_key: {{ .Data.data.access_key }}
+    {{- end }}
+    http_method: POST
+`), 0o600))
+
+	vendors, err := LoadVendors(path)
+	require.NoError(t, err)
+	require.Equal(t, []config.Vendor{{Name: "replace", AccessKey: "{{ .Data.data.access_key }}", HTTPMethod: "POST"}}, vendors)
+}
@@ -0,0 +1,60 @@
+package configdiff
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// templateValueRegExp matches a consul-template action that is a whole YAML value
+	templateValueRegExp = regexp.MustCompile(`(:\s*)(\{\{[^}]*\}\})\s*$`)
+)
+
+// LoadVendors loads the vendors of a vendors.yaml, or of the vendor_config of a full config. Consul-template
+// files are accepted: the action lines are dropped and the actions used as values are kept as strings, so
+// that a secret reference compares like a secret.
+func LoadVendors(path string) ([]config.Vendor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(StripTemplate(data))); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if v.IsSet("vendor_config") {
+		v = v.Sub("vendor_config")
+	}
+
+	var cfg struct {
+		Vendors []config.Vendor `mapstructure:"vendors"`
+	}
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return cfg.Vendors, nil
+}
+
+// StripTemplate makes a consul-template file parsable as YAML: the action lines are dropped, and the actions
+// used as values are quoted
+func StripTemplate(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") {
+			continue
+		}
+		out = append(out, templateValueRegExp.ReplaceAllString(line, `$1"$2"`))
+	}
+	return []byte(strings.Join(out, "\n"))
+}
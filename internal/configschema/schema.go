@@ -0,0 +1,244 @@
+// Package configschema generates the JSON Schemas of config.yaml and vendors.yaml from the config structs, so
+// that the configs can be validated without Go, e.g. by editors
+package configschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/strategy/url"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema used for the configs
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema `json:"patternProperties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// durationPattern matches the strings of time.ParseDuration, the durations can also be integers of nanoseconds
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+var (
+	configPkgPath = reflect.TypeOf(config.Config{}).PkgPath()
+	durationType  = reflect.TypeOf(time.Duration(0))
+	secretType    = reflect.TypeOf(config.Secret(""))
+
+	// macroFields are the fields where url.Default replaces the macros
+	macroFields = map[reflect.Type]string{
+		reflect.TypeOf(config.URLPattern{}): "url",
+		reflect.TypeOf(config.Query{}):      "value",
+	}
+)
+
+// vendorsFile is a vendors.yaml, the vendors of the vendor_config of config.yaml
+type vendorsFile struct {
+	ProxyURL string          `mapstructure:"proxy_url"`
+	Vendors  []config.Vendor `mapstructure:"vendors" validate:"dive"`
+}
+
+// Generate returns the schemas by file name: config.schema.json and vendors.schema.json
+func Generate() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for name, root := range map[string]*Schema{
+		"config.schema.json":  generate("rec-vendor-api config.yaml", reflect.TypeOf(config.Config{})),
+		"vendors.schema.json": generate("rec-vendor-api vendors.yaml", reflect.TypeOf(vendorsFile{})),
+	} {
+		b, err := json.MarshalIndent(root, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[name] = append(b, '\n')
+	}
+	return files, nil
+}
+
+// referencePattern matches a reference resolved by config.Load, such as ${env:NAME} or ${file:/path}
+const referencePattern = `^\$\{(env|file):[^}]+\}$`
+
+// MacroPattern matches the strings whose macros are all supported by url.Default
+func MacroPattern() string {
+	macros := make([]string, 0, len(url.Macros))
+	for _, m := range url.Macros {
+		macros = append(macros, regexp.QuoteMeta(strings.Trim(m, "{}")))
+	}
+	return `^([^{}]|\{(` + strings.Join(macros, "|") + `)\})*$`
+}
+
+func generate(title string, t reflect.Type) *Schema {
+	g := &generator{defs: map[string]*Schema{
+		"Macro": {Description: "macro replaced by the URL strategy with a value of the request", Enum: url.Macros},
+	}}
+	root := g.object(t, "#")
+	root.Schema, root.Title, root.Defs = draft, title, g.defs
+	return root
+}
+
+type generator struct {
+	defs map[string]*Schema
+}
+
+// schema returns the schema of the type, the structs of the config package are referenced in $defs, and the
+// other structs, e.g. of the logging kit, are any object
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == durationType {
+		return &Schema{Type: []string{"string", "integer"}, Pattern: durationPattern}
+	}
+	if t == secretType {
+		return &Schema{Type: "string", Description: "secret, inline or a reference such as ${env:NAME} or ${file:/path}"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Struct:
+		if t.PkgPath() != configPkgPath {
+			return &Schema{Type: "object"}
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			// reserved before the fields, for recursive types
+			g.defs[t.Name()] = nil
+			g.defs[t.Name()] = g.object(t, "#/$defs/"+t.Name())
+		}
+		return &Schema{Ref: "#/$defs/" + t.Name()}
+	default:
+		return &Schema{Type: "object"}
+	}
+}
+
+// object returns the schema of the struct with its mapstructure fields, pointer is the JSON pointer of the schema.
+// Unknown keys are refused, since viper ignores them and a typo would silently unset a field. The keys are
+// matched in any case like viper does: the properties list the lowercase keys for the editors, and the
+// patternProperties refer to them in any case.
+func (g *generator) object(t reflect.Type, pointer string) *Schema {
+	closed := false
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, PatternProperties: map[string]*Schema{}, AdditionalProperties: &closed}
+	g.addFields(s, t, pointer)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type, pointer string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if strings.Contains(opts, "squash") {
+			g.addFields(s, f.Type, pointer)
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fs := g.schema(f.Type)
+		if macroFields[t] == name {
+			fs.Pattern = referencePattern + "|" + MacroPattern()
+			fs.Description = "the macros must be one of #/$defs/Macro, or the whole value a reference such as ${env:NAME}"
+		}
+		pattern := caseInsensitivePattern(name)
+		if applyRules(fs, f.Tag.Get("validate")) {
+			// required in any case: not all the keys differ from the name
+			s.AllOf = append(s.AllOf, &Schema{
+				Description: name + " is required",
+				Not:         &Schema{PropertyNames: &Schema{Not: &Schema{Pattern: pattern}}},
+			})
+		}
+		s.Properties[name] = fs
+		s.PatternProperties[pattern] = &Schema{Ref: pointer + "/properties/" + name}
+	}
+}
+
+// caseInsensitivePattern matches the key in any case, JSON Schema patterns have no case-insensitive flag
+func caseInsensitivePattern(key string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range key {
+		lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+		if lower == upper {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			continue
+		}
+		b.WriteString("[" + string(lower) + string(upper) + "]")
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// applyRules applies the validate rules with a JSON Schema equivalent, the rules after dive apply to the
+// items. It returns whether the field is required, i.e. its zero value fails a rule without omitempty, such
+// as oneof=GET POST or gt=0.
+func applyRules(s *Schema, tag string) bool {
+	required, dived, omitempty := false, false, false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			if s.Items == nil {
+				return required
+			}
+			s, dived = s.Items, true
+		case "omitempty":
+			omitempty = true
+		case "required":
+			required = required || !dived
+		case "oneof":
+			s.Enum = strings.Fields(param)
+			required = required || (!dived && !omitempty)
+		case "url":
+			s.Format = "uri"
+		case "datetime":
+			if param == "2006-01-02T15:04:05Z07:00" {
+				s.Format = "date-time"
+			}
+		case "gte":
+			s.Minimum = number(param)
+		case "gt":
+			s.ExclusiveMinimum = number(param)
+			required = required || (!dived && !omitempty && !strings.HasPrefix(param, "-"))
+		case "lte":
+			s.Maximum = number(param)
+		}
+	}
+	return required
+}
+
+func number(s string) *float64 {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
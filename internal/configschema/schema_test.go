@@ -0,0 +1,250 @@
+package configschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"rec-vendor-api/internal/configdiff"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const templateDir = "../../config-template"
+
+// templateActionRegExp matches the consul-template actions left by configdiff.StripTemplate, i.e. the secrets
+var templateActionRegExp = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+func TestCheckedInSchemas(t *testing.T) {
+	files, err := Generate()
+	require.NoError(t, err)
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(templateDir, name))
+		require.NoError(t, err)
+		require.Equal(t, string(want), string(got), "%s is stale, run make config-schema", name)
+	}
+}
+
+func TestTemplatesMatchSchemas(t *testing.T) {
+	files, err := Generate()
+	require.NoError(t, err)
+	schemas := map[string]*Schema{}
+	for name, b := range files {
+		var s Schema
+		require.NoError(t, json.Unmarshal(b, &s))
+		schemas[name] = &s
+	}
+
+	templates, err := filepath.Glob(filepath.Join(templateDir, "*.yaml"))
+	require.NoError(t, err)
+	for _, path := range templates {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			rendered := templateActionRegExp.ReplaceAll(configdiff.StripTemplate(data), []byte("secret"))
+			var doc any
+			require.NoError(t, yaml.Unmarshal(rendered, &doc))
+
+			schema := schemas["config.schema.json"]
+			if filepath.Base(path) == "vendors.yaml" {
+				schema = schemas["vendors.schema.json"]
+			}
+			require.Empty(t, validate(schema, schema, doc, ""))
+		})
+	}
+}
+
+func TestSchemaRefusesInvalidVendors(t *testing.T) {
+	files, err := Generate()
+	require.NoError(t, err)
+	var schema Schema
+	require.NoError(t, json.Unmarshal(files["vendors.schema.json"], &schema))
+
+	tt := []struct {
+		name   string
+		vendor string
+		want   []string
+	}{
+		{
+			name:   "GIVEN a supported method and macros THEN expect no error",
+			vendor: `{name: acme, http_method: GET, request: {url: "https://acme.com/{subid}", queries: [{key: size, value: "{width}x{height}"}]}}`,
+		},
+		{
+			name:   "GIVEN secret references THEN expect no error",
+			vendor: `{name: keeta, http_method: GET, s_ca_secret: "${env:KEETA_SECRET}", request: {url: "https://keeta.com/reco", queries: [{key: token, value: "${file:/var/run/secrets/keeta/token}"}]}}`,
+		},
+		{
+			name:   "GIVEN an unsupported method THEN expect an enum error",
+			vendor: `{name: acme, http_method: PUT}`,
+			want:   []string{`/vendors/0/http_method: "PUT" is not one of [GET POST]`},
+		},
+		{
+			name:   "GIVEN no method THEN expect a required error",
+			vendor: `{name: acme}`,
+			want:   []string{`/vendors/0: http_method is required`},
+		},
+		{
+			name:   "GIVEN an unsupported macro THEN expect a pattern error",
+			vendor: `{name: acme, http_method: GET, tracking: {url: "{product_url}", queries: [{key: id, value: "{user_id}"}]}}`,
+			want:   []string{`/vendors/0/tracking/queries/0/value: "{user_id}" does not match the pattern`},
+		},
+		{
+			name:   "GIVEN keys in another case THEN expect no error, as viper matches them in any case",
+			vendor: `{Name: acme, HTTP_Method: GET, request: {url: "https://acme.com/reco", queries: [{Key: size, Value: "{width}"}]}}`,
+		},
+		{
+			name:   "GIVEN a key in another case THEN expect it validated",
+			vendor: `{name: acme, http_method: GET, request: {url: "https://acme.com/reco", queries: [{Key: id, Value: "{user_id}"}]}}`,
+			want:   []string{`/vendors/0/request/queries/0/Value: "{user_id}" does not match the pattern`},
+		},
+		{
+			name:   "GIVEN a misspelled key THEN expect an unknown key error",
+			vendor: `{name: acme, http_method: GET, with_proxi: true}`,
+			want:   []string{`/vendors/0: unknown key with_proxi`},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var doc any
+			require.NoError(t, yaml.Unmarshal([]byte("vendors: ["+tc.vendor+"]"), &doc))
+			require.Equal(t, tc.want, validate(&schema, &schema, doc, ""))
+		})
+	}
+}
+
+func TestMacroPattern(t *testing.T) {
+	re := regexp.MustCompile(MacroPattern())
+	for _, s := range []string{"", "0", "https://example.com/reco", "{width}x{height}", "{product_url}", "https://click/{click_id_base64}"} {
+		require.True(t, re.MatchString(s), s)
+	}
+	for _, s := range []string{"{user_id}", "{width}x{h}", "{", "https://{web_host"} {
+		require.False(t, re.MatchString(s), s)
+	}
+}
+
+// validate validates the document against the subset of JSON Schema generated by this package
+func validate(root, s *Schema, v any, path string) []string {
+	var errs []string
+	if s.Ref != "" {
+		errs = append(errs, validate(root, resolve(root, s.Ref), v, path)...)
+	}
+	for _, sub := range s.AllOf {
+		errs = append(errs, validate(root, sub, v, path)...)
+	}
+	if s.Not != nil && len(validate(root, s.Not, v, path)) == 0 {
+		errs = append(errs, fmt.Sprintf("%s: %s", path, s.Description))
+	}
+	if s.Type != nil && !matchesType(s.Type, v) {
+		return append(errs, fmt.Sprintf("%s: %v is not of type %v", path, v, s.Type))
+	}
+	if str, ok := v.(string); ok {
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			errs = append(errs, fmt.Sprintf("%s: %q is not one of %v", path, str, s.Enum))
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+			errs = append(errs, fmt.Sprintf("%s: %q does not match the pattern", path, str))
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if s.PropertyNames != nil {
+				errs = append(errs, validate(root, s.PropertyNames, key, path)...)
+			}
+			prop := propertySchema(s, key)
+			if prop == nil {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					errs = append(errs, fmt.Sprintf("%s: unknown key %s", path, key))
+				}
+				continue
+			}
+			errs = append(errs, validate(root, prop, v[key], path+"/"+key)...)
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				errs = append(errs, validate(root, s.Items, item, fmt.Sprintf("%s/%d", path, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+// propertySchema returns the schema of the key, from the properties or else the first matching pattern property
+func propertySchema(s *Schema, key string) *Schema {
+	if prop, ok := s.Properties[key]; ok {
+		return prop
+	}
+	for pattern, prop := range s.PatternProperties {
+		if regexp.MustCompile(pattern).MatchString(key) {
+			return prop
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema of a JSON pointer in $defs and properties
+func resolve(root *Schema, ref string) *Schema {
+	s := root
+	parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	for i := 0; i+1 < len(parts); i += 2 {
+		switch parts[i] {
+		case "$defs":
+			s = root.Defs[parts[i+1]]
+		case "properties":
+			s = s.Properties[parts[i+1]]
+		}
+	}
+	return s
+}
+
+func matchesType(typ any, v any) bool {
+	types, ok := typ.([]any)
+	if !ok {
+		types = []any{typ}
+	}
+	for _, t := range types {
+		switch v.(type) {
+		case map[string]any:
+			ok = t == "object"
+		case []any:
+			ok = t == "array"
+		case string:
+			ok = t == "string"
+		case bool:
+			ok = t == "boolean"
+		case int:
+			ok = t == "integer" || t == "number"
+		case float64:
+			ok = t == "number"
+		case nil:
+			// an empty YAML value, e.g. a section with all its keys commented
+			ok = true
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/redact"
+	"rec-vendor-api/internal/strategy/url"
+	"rec-vendor-api/internal/telemetry"
+	"rec-vendor-api/internal/tracktoken"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	clickStatusOK      = "ok"
+	clickStatusInvalid = "invalid"
+	clickStatusExpired = "expired"
+	unknownVendor      = "unknown"
+)
+
+// ClickTracker is a plain http.Handler so it can be mounted on both the gin and the gateway servers
+type ClickTracker struct {
+	signer       *tracktoken.Signer
+	sealer       *clickseal.Sealer
+	eventEmitter event.Emitter
+}
+
+// NewClickTracker returns the click redirect handler, the sealer opens the sealed click IDs of the tokens
+func NewClickTracker(signer *tracktoken.Signer, sealer *clickseal.Sealer, eventEmitter event.Emitter) *ClickTracker {
+	return &ClickTracker{
+		signer:       signer,
+		sealer:       sealer,
+		eventEmitter: eventEmitter,
+	}
+}
+
+// ServeHTTP godoc
+// @Summary      First-party click redirect
+// @Description  Validates a signed click token, records the click and redirects to the vendor tracking URL
+// @Param        token path string true "Signed click token"
+// @Success      302 "Found"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Router       /c/{token} [get]
+func (c *ClickTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, url.ClickPath)
+	claims, err := c.verify(token)
+	if err != nil {
+		status := clickStatusInvalid
+		if errors.Is(err, tracktoken.ErrExpired) {
+			status = clickStatusExpired
+		}
+		telemetry.Metrics.ClickTotal.WithLabelValues(unknownVendor, status).Inc()
+		log.WithContext(r.Context()).WithError(err).Warnf("Invalid click token, uri: %s", redact.URL(r.Context(), r.RequestURI))
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	telemetry.Metrics.ClickTotal.WithLabelValues(claims.Vendor, clickStatusOK).Inc()
+	requestInfo := telemetry.RequestInfoFromContext(r.Context())
+	c.eventEmitter.Emit(event.Event{
+		Type:       event.TypeClick,
+		Timestamp:  time.Now(),
+		ReqID:      requestInfo.ReqID,
+		TraceID:    requestInfo.TraceID,
+		SiteID:     claims.SiteID,
+		Vendor:     claims.Vendor,
+		ClickID:    claims.ClickID,
+		Outcome:    clickStatusOK,
+		ProductIDs: []string{claims.ProductID},
+	})
+	log.WithContext(r.Context()).WithFields(log.Fields{
+		"event":      "click",
+		"vendor":     claims.Vendor,
+		"click_id":   claims.ClickID,
+		"user_id":    claims.UserID,
+		"product_id": claims.ProductID,
+	}).Info("Click tracked")
+
+	http.Redirect(w, r, claims.URL, http.StatusFound)
+}
+
+// verify returns the claims of the token, with the click ID opened when it is sealed
+func (c *ClickTracker) verify(token string) (tracktoken.Claims, error) {
+	claims, err := c.signer.Verify(token)
+	if err != nil || !claims.Sealed {
+		return claims, err
+	}
+	if c.sealer == nil {
+		return tracktoken.Claims{}, errors.New("sealed click id without click seal_keys")
+	}
+	if claims.ClickID, err = c.sealer.Open(claims.ClickID); err != nil {
+		return tracktoken.Claims{}, err
+	}
+	claims.Sealed = false
+	return claims, nil
+}
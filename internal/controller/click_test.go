@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/tracktoken"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickTracker(t *testing.T) {
+	signer := tracktoken.NewSigner("secret", time.Hour)
+	token, err := signer.Sign(tracktoken.Claims{Vendor: "linkmine", ClickID: "click-1", UserID: "user-1", ProductID: "p1", SiteID: "site-1", URL: "https://tracker.com/trk?id=1"})
+	require.NoError(t, err)
+	sealer, err := clickseal.NewSealer([]clickseal.Key{{ID: "k1", Key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}})
+	require.NoError(t, err)
+	sealedClickID, err := sealer.Seal("click-1")
+	require.NoError(t, err)
+	sealedToken, err := signer.Sign(tracktoken.Claims{Vendor: "linkmine", ClickID: sealedClickID, ProductID: "p2", URL: "https://tracker.com/trk?id=2", Sealed: true})
+	require.NoError(t, err)
+	badSealToken, err := signer.Sign(tracktoken.Claims{Vendor: "linkmine", ClickID: "k1.AAAA", URL: "https://tracker.com/trk?id=3", Sealed: true})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name         string
+		path         string
+		wantCode     int
+		wantLocation string
+		wantEvents   []event.Event
+	}{
+		{
+			name:         "GIVEN a valid token THEN expect a redirect to the vendor tracking URL",
+			path:         "/c/" + token,
+			wantCode:     http.StatusFound,
+			wantLocation: "https://tracker.com/trk?id=1",
+			wantEvents: []event.Event{
+				{Type: event.TypeClick, SiteID: "site-1", Vendor: "linkmine", ClickID: "click-1", Outcome: "ok", ProductIDs: []string{"p1"}},
+			},
+		},
+		{
+			name:         "GIVEN a token with a sealed click id THEN expect a redirect to the vendor tracking URL",
+			path:         "/c/" + sealedToken,
+			wantCode:     http.StatusFound,
+			wantLocation: "https://tracker.com/trk?id=2",
+			wantEvents: []event.Event{
+				{Type: event.TypeClick, Vendor: "linkmine", ClickID: "click-1", Outcome: "ok", ProductIDs: []string{"p2"}},
+			},
+		},
+		{
+			name:     "GIVEN a token with an invalid sealed click id THEN expect a bad request response",
+			path:     "/c/" + badSealToken,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "GIVEN a tampered token THEN expect a bad request response",
+			path:     "/c/x" + token,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "GIVEN no token THEN expect a bad request response",
+			path:     "/c/",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			emitter := &recordingEmitter{}
+			w := httptest.NewRecorder()
+			NewClickTracker(signer, sealer, emitter).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Equal(t, tc.wantLocation, w.Header().Get("Location"))
+			for i := range emitter.events {
+				emitter.events[i].Timestamp = time.Time{}
+			}
+			require.Equal(t, tc.wantEvents, emitter.events)
+		})
+	}
+}
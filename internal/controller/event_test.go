@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"rec-vendor-api/internal/event"
+)
+
+// recordingEmitter keeps the emitted events for the assertions
+type recordingEmitter struct {
+	mu     sync.Mutex
+	events []event.Event
+}
+
+func (e *recordingEmitter) Emit(ev event.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, ev)
+}
+
+func (e *recordingEmitter) Close(_ context.Context) error {
+	return nil
+}
@@ -2,13 +2,21 @@ package controller
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
+	"rec-vendor-api/internal/auth"
 	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/telemetry"
 	"rec-vendor-api/internal/vendor"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
@@ -19,6 +27,21 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// metadataFormat selects the response format, as the format query param of the HTTP API
+	metadataFormat = "x-response-format"
+	// ProductInfo has no title and impression URL fields, so they are sent as response header metadata, one value
+	// per product
+	metadataProductTitle  = "x-product-title-bin"
+	metadataImpressionURL = "x-impression-url"
+
+	// maxHeaderBytes bounds the response header metadata below the 8 KiB header limit of common proxies, leaving
+	// room for the other headers. It is counted as HTTP/2 does: the name and value lengths plus 32 bytes per field.
+	maxHeaderBytes = 7 << 10
+	// maxTitleBytes truncates the titles, they are display text and not identifiers
+	maxTitleBytes = 100
+)
+
 type Handler interface {
 	GetRecommendations(context.Context, *schema.GetRecommendationsRequest) (*schema.GetRecommendationsResponse, error)
 	GetVendors(context.Context, *emptypb.Empty) (*schema.GetVendorsResponse, error)
@@ -29,16 +52,33 @@ type HandlerImpl struct {
 	schema.UnimplementedVendorAPIServer
 	vendorRegistry map[string]vendor.Client
 	vendorInfo     []*schema.VendorInfo
+	authenticator  *auth.Authenticator
 }
 
-func NewHandler(vendorRegistry map[string]vendor.Client, vendorConfig config.VendorConfig) (*HandlerImpl, error) {
+// NewHandler lists the vendors the caller can request in GetVendors, all of them if the authenticator is nil
+func NewHandler(vendorRegistry map[string]vendor.Client, vendorConfig config.VendorConfig, authenticator *auth.Authenticator) (*HandlerImpl, error) {
 	return &HandlerImpl{
 		vendorRegistry: vendorRegistry,
 		vendorInfo:     initVendorInfo(vendorConfig),
+		authenticator:  authenticator,
 	}, nil
 }
 
 func (s *HandlerImpl) GetRecommendations(ctx context.Context, req *schema.GetRecommendationsRequest) (*schema.GetRecommendationsResponse, error) {
+	format := formatJSON
+	if values := metadata.ValueFromIncomingContext(ctx, metadataFormat); len(values) > 0 && values[0] != "" {
+		format = values[0]
+	}
+	if format != formatJSON && format != formatOpenRTBNative {
+		log.WithContext(ctx).Errorf("Invalid response format: %s", format)
+		return nil, status.Errorf(codes.InvalidArgument, "Format '%s' not supported (supported: %s, %s)", format, formatJSON, formatOpenRTBNative)
+	}
+	// GetRecommendationsResponse has no field for the native responses, and they are too large for the header
+	// metadata
+	if format == formatOpenRTBNative {
+		return nil, status.Errorf(codes.Unimplemented, "Format '%s' is only supported by the HTTP API", formatOpenRTBNative)
+	}
+
 	vendorKey := req.VendorKey
 	vendorClient := s.vendorRegistry[vendorKey]
 	if vendorClient == nil {
@@ -58,12 +98,25 @@ func (s *HandlerImpl) GetRecommendations(ctx context.Context, req *schema.GetRec
 		return nil, status.Errorf(codes.Internal, "Fail to recommend any products. err: %v", err)
 	}
 
+	header := toHeader(ctx, products)
+	if len(header) > 0 {
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			log.WithContext(ctx).Warnf("Fail to set the response header. err: %v", err)
+		}
+	}
 	return toProto(products)
 }
 
-func (s *HandlerImpl) GetVendors(_ context.Context, _ *emptypb.Empty) (*schema.GetVendorsResponse, error) {
+func (s *HandlerImpl) GetVendors(ctx context.Context, _ *emptypb.Empty) (*schema.GetVendorsResponse, error) {
+	caller := telemetry.RequestInfoFromContext(ctx).Caller
+	vendors := make([]*schema.VendorInfo, 0, len(s.vendorInfo))
+	for _, v := range s.vendorInfo {
+		if s.authenticator.Allows(caller, v.VendorKey) {
+			vendors = append(vendors, v)
+		}
+	}
 	return &schema.GetVendorsResponse{
-		Vendors: s.vendorInfo,
+		Vendors: vendors,
 	}, nil
 }
 
@@ -132,6 +185,61 @@ func toProto(products []vendor.ProductInfo) (*schema.GetRecommendationsResponse,
 	}, nil
 }
 
+// toHeader returns the response header metadata: the impression URLs and the product titles if any product has
+// one. A key that would exceed maxHeaderBytes is left out, the impression URLs first since the beacons depend on
+// them.
+func toHeader(ctx context.Context, products []vendor.ProductInfo) metadata.MD {
+	header := metadata.MD{}
+	size := 0
+	size += setProductValues(ctx, header, metadataImpressionURL, maxHeaderBytes-size, products, func(p vendor.ProductInfo) string { return p.ImpressionURL })
+	setProductValues(ctx, header, metadataProductTitle, maxHeaderBytes-size, products, func(p vendor.ProductInfo) string { return truncateUTF8(p.Title, maxTitleBytes) })
+	return header
+}
+
+// setProductValues sets one value per product under the key, unless all values are empty or they exceed the budget,
+// and returns their size
+func setProductValues(ctx context.Context, header metadata.MD, key string, budget int, products []vendor.ProductInfo, value func(vendor.ProductInfo) string) int {
+	values := make([]string, len(products))
+	for i, product := range products {
+		values[i] = value(product)
+	}
+	if !slices.ContainsFunc(values, func(v string) bool { return v != "" }) {
+		return 0
+	}
+	size := headerSize(key, values)
+	if size > budget {
+		log.WithContext(ctx).Debugf("Drop the %s response header metadata of %d bytes, over the budget of %d bytes", key, size, budget)
+		telemetry.Metrics.HeaderDroppedTotal.WithLabelValues(key).Inc()
+		return 0
+	}
+	header.Set(key, values...)
+	return size
+}
+
+// headerSize returns the size of the values on the wire, where the binary ones are base64 encoded
+func headerSize(key string, values []string) int {
+	size := 0
+	for _, v := range values {
+		n := len(v)
+		if strings.HasSuffix(key, "-bin") {
+			n = base64.RawStdEncoding.EncodedLen(n)
+		}
+		size += len(key) + n + 32
+	}
+	return size
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
 func getClientIP(ctx context.Context) string {
 	if realIP, ok := grpc_realip.FromContext(ctx); ok && realIP.IsValid() {
 		return realIP.String()
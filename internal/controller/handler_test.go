@@ -3,16 +3,24 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"unicode/utf8"
 
+	"rec-vendor-api/internal/auth"
 	"rec-vendor-api/internal/config"
 	controller_errors "rec-vendor-api/internal/controller/errors"
+	"rec-vendor-api/internal/strategy/url"
+	"rec-vendor-api/internal/telemetry"
+	"rec-vendor-api/internal/tracktoken"
 	"rec-vendor-api/internal/vendor"
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -52,10 +60,12 @@ func (ts *HandlerTestSuite) TestGetRecommendations() {
 	tt := []struct {
 		name         string
 		vendorKey    string
+		format       string
 		setupMock    func(mockClient *vendor.MockClient)
 		wantCode     codes.Code
 		wantErrMsg   string
 		wantProducts []*schema.ProductInfo
+		wantHeader   metadata.MD
 	}{
 		{
 			name:      "GIVEN a valid request THEN expect a successful response",
@@ -71,6 +81,42 @@ func (ts *HandlerTestSuite) TestGetRecommendations() {
 				{ProductId: "1", Url: "url", Image: "img", Price: "100", SalePrice: "80", Currency: "USD"},
 			},
 		},
+		{
+			name:      "GIVEN products with titles and impression URLs THEN expect them in the header",
+			vendorKey: "test_vendor",
+			setupMock: func(mc *vendor.MockClient) {
+				mockResp := []vendor.ProductInfo{
+					{ProductID: "1", Title: "title", Url: "url", Image: "img", Price: "100", ImpressionURL: "https://rec.example.com/i/t1"},
+					{ProductID: "2", Url: "url2", Image: "img2"},
+				}
+				mc.EXPECT().GetUserRecommendationItems(gomock.Any(), gomock.Any()).Return(mockResp, nil)
+			},
+			wantCode: codes.OK,
+			wantProducts: []*schema.ProductInfo{
+				{ProductId: "1", Url: "url", Image: "img", Price: "100"},
+				{ProductId: "2", Url: "url2", Image: "img2"},
+			},
+			wantHeader: metadata.MD{
+				metadataProductTitle:  {"title", ""},
+				metadataImpressionURL: {"https://rec.example.com/i/t1", ""},
+			},
+		},
+		{
+			name:       "GIVEN the openrtb_native format THEN expect an unimplemented response",
+			vendorKey:  "test_vendor",
+			format:     "openrtb_native",
+			setupMock:  func(mc *vendor.MockClient) {},
+			wantCode:   codes.Unimplemented,
+			wantErrMsg: "Format 'openrtb_native' is only supported by the HTTP API",
+		},
+		{
+			name:       "GIVEN an unsupported format THEN expect a bad request response",
+			vendorKey:  "test_vendor",
+			format:     "xml",
+			setupMock:  func(mc *vendor.MockClient) {},
+			wantCode:   codes.InvalidArgument,
+			wantErrMsg: "Format 'xml' not supported (supported: json, openrtb_native)",
+		},
 		{
 			name:       "GIVEN an invalid vendor key THEN expect a bad request response",
 			vendorKey:  "wrong_vendor_key",
@@ -102,7 +148,7 @@ func (ts *HandlerTestSuite) TestGetRecommendations() {
 		ts.T().Run(tc.name, func(t *testing.T) {
 			tc.setupMock(ts.mockClient)
 
-			handler, err := NewHandler(ts.vendorRegistry, ts.vendorConfig)
+			handler, err := NewHandler(ts.vendorRegistry, ts.vendorConfig, nil)
 			require.NoError(t, err)
 			request := &schema.GetRecommendationsRequest{
 				VendorKey: tc.vendorKey,
@@ -111,7 +157,9 @@ func (ts *HandlerTestSuite) TestGetRecommendations() {
 				W:         100,
 				H:         200,
 			}
-			resp, err := handler.GetRecommendations(context.Background(), request)
+			stream := &headerStream{}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataFormat, tc.format))
+			resp, err := handler.GetRecommendations(grpc.NewContextWithServerTransportStream(ctx, stream), request)
 
 			if tc.wantCode == codes.OK {
 				require.NoError(t, err)
@@ -120,6 +168,7 @@ func (ts *HandlerTestSuite) TestGetRecommendations() {
 				for i, wantProduct := range tc.wantProducts {
 					require.True(t, proto.Equal(wantProduct, resp.Products[i]))
 				}
+				require.Equal(t, tc.wantHeader, stream.header)
 			} else {
 				require.Error(t, err)
 				st, ok := status.FromError(err)
@@ -173,7 +222,7 @@ func (ts *HandlerTestSuite) TestGetVendors() {
 
 	for _, tc := range tt {
 		ts.T().Run(tc.name, func(t *testing.T) {
-			handler, err := NewHandler(ts.vendorRegistry, tc.vendorConfig)
+			handler, err := NewHandler(ts.vendorRegistry, tc.vendorConfig, nil)
 
 			require.NoError(t, err)
 			require.NotNil(t, handler)
@@ -188,6 +237,95 @@ func (ts *HandlerTestSuite) TestGetVendors() {
 	}
 }
 
+func (ts *HandlerTestSuite) TestGetVendorsRestrictedCaller() {
+	authenticator, err := auth.New(config.AuthConfig{Enabled: true, Callers: []config.AuthCaller{
+		{Name: "restricted", APIKeys: []config.Secret{"k1"}, Vendors: []string{"another_vendor"}},
+		{Name: "unrestricted", APIKeys: []config.Secret{"k2"}},
+	}})
+	require.NoError(ts.T(), err)
+	handler, err := NewHandler(ts.vendorRegistry, ts.vendorConfig, authenticator)
+	require.NoError(ts.T(), err)
+
+	ctx := telemetry.RequestInfoToContext(context.Background(), telemetry.RequestInfo{Caller: "restricted"})
+	resp, err := handler.GetVendors(ctx, &emptypb.Empty{})
+	require.NoError(ts.T(), err)
+	require.Len(ts.T(), resp.Vendors, 1)
+	require.Equal(ts.T(), "another_vendor", resp.Vendors[0].VendorKey)
+
+	ctx = telemetry.RequestInfoToContext(context.Background(), telemetry.RequestInfo{Caller: "unrestricted"})
+	resp, err = handler.GetVendors(ctx, &emptypb.Empty{})
+	require.NoError(ts.T(), err)
+	require.Len(ts.T(), resp.Vendors, 2)
+}
+
+func TestToHeader(t *testing.T) {
+	// a realistic page of 20 products, with long multi-byte titles and signed impression URLs
+	beacon := &url.ImpressionBeacon{Signer: tracktoken.NewSigner("key", 0), BaseURL: "https://rec-vendor-api.example.com", VendorName: "linkmine"}
+	products := make([]vendor.ProductInfo, 20)
+	for i := range products {
+		impressionURL, err := beacon.GenerateURL(config.URLPattern{}, url.Params{
+			ClickID:   "3f2b8c1e-7d4a-4e9b-a1c6-5d8e2f9b0a47",
+			UserID:    "a91c3e5f-2b7d-4c8a-9e1f-6b3d5a7c9e21",
+			ProductID: fmt.Sprintf("49012345678%02d", i),
+			SiteID:    "news-app-jp",
+		})
+		require.NoError(t, err)
+		products[i] = vendor.ProductInfo{
+			ProductID:     fmt.Sprintf("49012345678%02d", i),
+			Title:         "【公式】ワイヤレスイヤホン Bluetooth 5.3 ノイズキャンセリング 最大40時間再生 IPX5防水 マイク付き",
+			ImpressionURL: impressionURL,
+		}
+	}
+	requireHeaderBudget := func(t *testing.T, header metadata.MD) {
+		size := 0
+		for key, values := range header {
+			size += headerSize(key, values)
+		}
+		require.LessOrEqual(t, size, maxHeaderBytes)
+	}
+
+	// the impression URLs fill most of the budget, the titles are left out rather than exceeding it
+	header := toHeader(context.Background(), products)
+	require.Len(t, header.Get(metadataImpressionURL), 20)
+	require.Empty(t, header.Get(metadataProductTitle))
+	requireHeaderBudget(t, header)
+
+	// without impression URLs, the truncated titles fit
+	for i := range products {
+		products[i].ImpressionURL = ""
+	}
+	header = toHeader(context.Background(), products)
+	require.Empty(t, header.Get(metadataImpressionURL))
+	require.Len(t, header.Get(metadataProductTitle), 20)
+	requireHeaderBudget(t, header)
+	for _, title := range header.Get(metadataProductTitle) {
+		require.LessOrEqual(t, len(title), maxTitleBytes)
+		require.True(t, utf8.ValidString(title))
+	}
+}
+
+// headerStream keeps the response header set by the handler
+type headerStream struct {
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string {
+	return "/vendorapi.VendorAPI/GetRecommendations"
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *headerStream) SetTrailer(_ metadata.MD) error {
+	return nil
+}
+
 func TestHandlerTestSuite(t *testing.T) {
 	t.Parallel()
 	suite.Run(t, &HandlerTestSuite{})
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"rec-vendor-api/internal/dedupe"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/freqcap"
+	"rec-vendor-api/internal/redact"
+	"rec-vendor-api/internal/strategy/url"
+	"rec-vendor-api/internal/telemetry"
+	"rec-vendor-api/internal/tracktoken"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	impressionStatusOK        = "ok"
+	impressionStatusInvalid   = "invalid"
+	impressionStatusDuplicate = "duplicate"
+	unknownSite               = "unknown"
+)
+
+// transparentGIF is a 1x1 transparent GIF
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// ImpressionTracker is a plain http.Handler so it can be mounted on both the gin and the gateway servers.
+// If freqCap is set, the beacons are recorded as impressions for frequency capping. The impressions are counted
+// per product by the impression events only, the metric would not bear the cardinality of the product IDs.
+// The beacons replayed with a token seen by the deduper are answered but not recorded.
+type ImpressionTracker struct {
+	signer       *tracktoken.Signer
+	freqCap      *freqcap.Capper
+	deduper      dedupe.Store
+	eventEmitter event.Emitter
+}
+
+func NewImpressionTracker(signer *tracktoken.Signer, freqCap *freqcap.Capper, deduper dedupe.Store, eventEmitter event.Emitter) *ImpressionTracker {
+	return &ImpressionTracker{
+		signer:       signer,
+		freqCap:      freqCap,
+		deduper:      deduper,
+		eventEmitter: eventEmitter,
+	}
+}
+
+// ServeHTTP godoc
+// @Summary      Impression beacon
+// @Description  Validates a signed impression token and records the impression, invalid tokens get no content
+// @Produce      image/gif
+// @Param        token path string true "Signed impression token"
+// @Success      200 "1x1 GIF"
+// @Success      204 "No Content"
+// @Router       /i/{token} [get]
+func (t *ImpressionTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	token := strings.TrimPrefix(r.URL.Path, url.ImpressionPath)
+	claims, err := t.signer.Verify(token)
+	if err != nil {
+		// beacons are fire-and-forget, so invalid tokens are only counted and never surface as errors
+		telemetry.Metrics.ImpressionTotal.WithLabelValues(unknownVendor, unknownSite, impressionStatusInvalid).Inc()
+		log.WithContext(r.Context()).WithError(err).Warnf("Invalid impression token, uri: %s", redact.URL(r.Context(), r.RequestURI))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// a failing store does not drop the impression, the beacon cannot be retried
+	firstSeen, err := t.deduper.FirstSeen(r.Context(), tracktoken.ID(token))
+	if err != nil {
+		log.WithContext(r.Context()).WithError(err).Error("Fail to deduplicate the impression token")
+	} else if !firstSeen {
+		telemetry.Metrics.ImpressionTotal.WithLabelValues(claims.Vendor, claims.SiteID, impressionStatusDuplicate).Inc()
+		writeGIF(w)
+		return
+	}
+
+	telemetry.Metrics.ImpressionTotal.WithLabelValues(claims.Vendor, claims.SiteID, impressionStatusOK).Inc()
+	requestInfo := telemetry.RequestInfoFromContext(r.Context())
+	t.eventEmitter.Emit(event.Event{
+		Type:       event.TypeImpression,
+		Timestamp:  time.Now(),
+		ReqID:      requestInfo.ReqID,
+		TraceID:    requestInfo.TraceID,
+		SiteID:     claims.SiteID,
+		Vendor:     claims.Vendor,
+		ClickID:    claims.ClickID,
+		Outcome:    impressionStatusOK,
+		ProductIDs: []string{claims.ProductID},
+	})
+	log.WithContext(r.Context()).WithFields(log.Fields{
+		"event":      "impression",
+		"vendor":     claims.Vendor,
+		"site_id":    claims.SiteID,
+		"click_id":   claims.ClickID,
+		"user_id":    claims.UserID,
+		"product_id": claims.ProductID,
+	}).Info("Impression tracked")
+
+	if t.freqCap != nil {
+		t.freqCap.Record(r.Context(), claims.Vendor, claims.SiteID, claims.UserID, []string{claims.ProductID})
+	}
+	writeGIF(w)
+}
+
+// Close stops the background sweeping of the seen tokens, or closes the connections of the shared store
+func (t *ImpressionTracker) Close() {
+	dedupe.Close(t.deduper)
+}
+
+func writeGIF(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "image/gif")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(transparentGIF)
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/dedupe"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/freqcap"
+	"rec-vendor-api/internal/tracktoken"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpressionTracker(t *testing.T) {
+	signer := tracktoken.NewSigner("secret", time.Hour)
+	token, err := signer.Sign(tracktoken.Claims{Vendor: "linkmine", ClickID: "click-1", UserID: "user-1", ProductID: "p1", SiteID: "site-1"})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name            string
+		path            string
+		wantCode        int
+		wantContentType string
+		wantCapped      bool
+		wantEvents      []event.Event
+	}{
+		{
+			name:            "GIVEN a valid token THEN expect a GIF and the impression recorded",
+			path:            "/i/" + token,
+			wantCode:        http.StatusOK,
+			wantContentType: "image/gif",
+			wantCapped:      true,
+			wantEvents: []event.Event{
+				{Type: event.TypeImpression, SiteID: "site-1", Vendor: "linkmine", ClickID: "click-1", Outcome: "ok", ProductIDs: []string{"p1"}},
+			},
+		},
+		{
+			name:     "GIVEN a tampered token THEN expect no content and no impression recorded",
+			path:     "/i/x" + token,
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			store := freqcap.NewMemoryStore(0, 0)
+			defer store.Close()
+			capper := freqcap.NewCapper(store, []freqcap.Rule{{MaxImpressions: 1, Window: time.Hour}})
+
+			emitter := &recordingEmitter{}
+			w := httptest.NewRecorder()
+			NewImpressionTracker(signer, capper, dedupe.NewMemoryStore("test", time.Minute, 0, 0), emitter).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Equal(t, tc.wantContentType, w.Header().Get("Content-Type"))
+			allowed := capper.Allowed(context.Background(), "linkmine", "site-1", "user-1", []string{"p1"})
+			require.Equal(t, tc.wantCapped, len(allowed) == 0)
+			for i := range emitter.events {
+				emitter.events[i].Timestamp = time.Time{}
+			}
+			require.Equal(t, tc.wantEvents, emitter.events)
+		})
+	}
+}
+
+func TestImpressionTrackerReplay(t *testing.T) {
+	signer := tracktoken.NewSigner("secret", time.Hour)
+	token, err := signer.Sign(tracktoken.Claims{Vendor: "linkmine", ClickID: "click-1", UserID: "user-1", ProductID: "p1"})
+	require.NoError(t, err)
+	other, err := signer.Sign(tracktoken.Claims{Vendor: "linkmine", ClickID: "click-1", UserID: "user-1", ProductID: "p2"})
+	require.NoError(t, err)
+
+	emitter := &recordingEmitter{}
+	tracker := NewImpressionTracker(signer, nil, dedupe.NewMemoryStore("test", time.Minute, 0, 0), emitter)
+	defer tracker.Close()
+
+	for _, path := range []string{"/i/" + token, "/i/" + token, "/i/" + other} {
+		w := httptest.NewRecorder()
+		tracker.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		// the replayed beacon still gets the GIF
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "image/gif", w.Header().Get("Content-Type"))
+	}
+	require.Len(t, emitter.events, 2)
+	require.Equal(t, []string{"p1"}, emitter.events[0].ProductIDs)
+	require.Equal(t, []string{"p2"}, emitter.events[1].ProductIDs)
+}
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/dedupe"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/postback"
+	"rec-vendor-api/internal/telemetry"
+	"rec-vendor-api/internal/vendor"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PostbackPath is the path prefix of the conversion postback endpoint, followed by the vendor key
+const PostbackPath = "/pb/"
+
+const (
+	conversionStatusOK           = "ok"
+	conversionStatusDuplicate    = "duplicate"
+	conversionStatusUnauthorized = "unauthorized"
+	conversionStatusInvalid      = "invalid"
+	conversionStatusError        = "error"
+
+	defaultPostbackDedupeWindow     = 30 * 24 * time.Hour
+	defaultPostbackDedupeMaxEntries = 1_000_000
+	postbackSweepInterval           = 10 * time.Minute
+)
+
+type postbackVendor struct {
+	mapping postback.Mapping
+	auth    *postback.Authenticator
+}
+
+// PostbackReceiver is a plain http.Handler so it can be mounted on both the gin and the gateway servers
+type PostbackReceiver struct {
+	vendors        map[string]postbackVendor
+	deduper        dedupe.Store
+	trustedProxies []netip.Prefix
+	eventEmitter   event.Emitter
+}
+
+// NewPostbackReceiver accepts the postbacks of the vendors with a postback click_id_param, the sealer opens the
+// click IDs of the sealed encoding and the accepted conversions are emitted as events
+func NewPostbackReceiver(cfg config.VendorConfig, sealer *clickseal.Sealer, eventEmitter event.Emitter) (*PostbackReceiver, error) {
+	vendors := map[string]postbackVendor{}
+	for _, v := range cfg.Vendors {
+		if v.Postback.ClickIDParam == "" {
+			continue
+		}
+		if v.Postback.ClickIDEncoding == postback.ClickIDEncodingSealed && sealer == nil {
+			return nil, fmt.Errorf("vendor %s: click_id_encoding sealed requires click.seal_keys", v.Name)
+		}
+		auth, err := postback.NewAuthenticator(v.Postback.Secret.Value(), v.Postback.SecretParam, v.Postback.AllowedIPs)
+		if err != nil {
+			return nil, fmt.Errorf("vendor %s: %w", v.Name, err)
+		}
+		vendors[v.Name] = postbackVendor{
+			mapping: postback.Mapping{
+				ClickIDParam:    v.Postback.ClickIDParam,
+				ClickIDEncoding: v.Postback.ClickIDEncoding,
+				OrderIDParam:    v.Postback.OrderIDParam,
+				RevenueParam:    v.Postback.RevenueParam,
+				CurrencyParam:   v.Postback.CurrencyParam,
+				Sealer:          sealer,
+			},
+			auth: auth,
+		}
+	}
+
+	trustedProxies, err := postback.ParsePrefixes(cfg.Postback.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("postback trusted proxies: %w", err)
+	}
+
+	deduper, err := buildDedupeStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("postback: %w", err)
+	}
+	return &PostbackReceiver{
+		vendors:        vendors,
+		deduper:        deduper,
+		trustedProxies: trustedProxies,
+		eventEmitter:   eventEmitter,
+	}, nil
+}
+
+func buildDedupeStore(cfg config.VendorConfig) (dedupe.Store, error) {
+	window := cfg.Postback.DedupeWindow
+	if window == 0 {
+		window = defaultPostbackDedupeWindow
+	}
+	if cfg.Postback.Store == "redis" {
+		client, err := vendor.NewRedisClient(cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		return dedupe.NewRedisStore(client, "vendor-api:postback:", window), nil
+	}
+
+	maxEntries := cfg.Postback.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = defaultPostbackDedupeMaxEntries
+	}
+	return dedupe.NewMemoryStore("postback_dedupe", window, postbackSweepInterval, maxEntries), nil
+}
+
+// ServeHTTP godoc
+// @Summary      Conversion postback
+// @Description  Receives a conversion postback from a vendor, duplicated orders are acknowledged but not counted
+// @Produce      json
+// @Param        vendor_key path string true "Vendor Key"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      403 {object} map[string]string "Forbidden"
+// @Failure      404 {object} map[string]string "Not Found"
+// @Failure      503 {object} map[string]string "Service Unavailable"
+// @Router       /pb/{vendor_key} [get]
+// @Router       /pb/{vendor_key} [post]
+func (p *PostbackReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vendorKey := strings.TrimPrefix(r.URL.Path, PostbackPath)
+	v, ok := p.vendors[vendorKey]
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("vendor key '%s' has no postback", vendorKey))
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		telemetry.Metrics.ConversionTotal.WithLabelValues(vendorKey, conversionStatusInvalid).Inc()
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	clientIP := postback.ClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), p.trustedProxies)
+	if !v.auth.Allow(r.Form, clientIP) {
+		telemetry.Metrics.ConversionTotal.WithLabelValues(vendorKey, conversionStatusUnauthorized).Inc()
+		log.WithContext(r.Context()).Warnf("Unauthorized postback for vendor %s from %s", vendorKey, clientIP)
+		writeJSONError(w, http.StatusForbidden, errors.New("unauthorized postback"))
+		return
+	}
+
+	conversion, err := postback.Parse(vendorKey, v.mapping, r.Form)
+	if err != nil {
+		telemetry.Metrics.ConversionTotal.WithLabelValues(vendorKey, conversionStatusInvalid).Inc()
+		log.WithContext(r.Context()).WithError(err).Warnf("Invalid postback for vendor %s", vendorKey)
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// vendors retry until they get a success, so duplicates are acknowledged as well and store errors are not
+	// orders are deduplicated per vendor
+	firstSeen, err := p.deduper.FirstSeen(r.Context(), vendorKey+"\x00"+conversion.OrderID)
+	if err != nil {
+		telemetry.Metrics.ConversionTotal.WithLabelValues(vendorKey, conversionStatusError).Inc()
+		log.WithContext(r.Context()).WithError(err).Errorf("Fail to deduplicate the postback of vendor %s", vendorKey)
+		writeJSONError(w, http.StatusServiceUnavailable, errors.New("postback store unavailable"))
+		return
+	}
+	if !firstSeen {
+		telemetry.Metrics.ConversionTotal.WithLabelValues(vendorKey, conversionStatusDuplicate).Inc()
+		writeJSON(w, http.StatusOK, map[string]any{"status": conversionStatusDuplicate})
+		return
+	}
+
+	telemetry.Metrics.ConversionTotal.WithLabelValues(vendorKey, conversionStatusOK).Inc()
+	telemetry.Metrics.ConversionRevenueTotal.WithLabelValues(vendorKey, conversion.Currency).Add(conversion.Revenue)
+	requestInfo := telemetry.RequestInfoFromContext(r.Context())
+	p.eventEmitter.Emit(event.Event{
+		Type:      event.TypeConversion,
+		Timestamp: time.Now(),
+		ReqID:     requestInfo.ReqID,
+		TraceID:   requestInfo.TraceID,
+		Vendor:    vendorKey,
+		ClickID:   conversion.ClickID,
+		Outcome:   conversionStatusOK,
+		OrderID:   conversion.OrderID,
+		Revenue:   conversion.Revenue,
+		Currency:  conversion.Currency,
+	})
+	log.WithContext(r.Context()).WithFields(log.Fields{
+		"event":    "conversion",
+		"vendor":   vendorKey,
+		"click_id": conversion.ClickID,
+		"order_id": conversion.OrderID,
+		"revenue":  conversion.Revenue,
+		"currency": conversion.Currency,
+	}).Info("Conversion received")
+
+	writeJSON(w, http.StatusOK, map[string]any{"status": conversionStatusOK})
+}
+
+// Close stops the background sweeping of the deduplicated orders, or closes the connections of the shared store
+func (p *PostbackReceiver) Close() {
+	dedupe.Close(p.deduper)
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/redis/redistest"
+	"rec-vendor-api/internal/strategy/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostbackReceiver(t *testing.T) {
+	cfg := config.VendorConfig{Vendors: []config.Vendor{
+		{
+			Name: "linkmine",
+			Postback: config.Postback{
+				ClickIDParam: "sub1", OrderIDParam: "order", RevenueParam: "amount", CurrencyParam: "cur",
+				Secret: "s3cret",
+			},
+		},
+		{
+			Name: "adpacker",
+			Postback: config.Postback{
+				ClickIDParam: "click", ClickIDEncoding: "raw", OrderIDParam: "oid",
+				AllowedIPs: []string{"10.0.0.0/8"},
+			},
+		},
+		{Name: "no_postback"},
+	}, Postback: config.PostbackConfig{TrustedProxies: []string{"172.16.0.0/12"}}}
+	emitter := &recordingEmitter{}
+	receiver, err := NewPostbackReceiver(cfg, nil, emitter)
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	linkmineQuery := url.Values{"sub1": {utils.EncodeClickID("click-1")}, "order": {"o1"}, "amount": {"10"}, "cur": {"KRW"}, "secret": {"s3cret"}}
+
+	tt := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		remoteAddr   string
+		forwardedFor string
+		wantCode     int
+		wantBody     string
+	}{
+		{
+			name:     "GIVEN a valid postback THEN expect it accepted",
+			method:   http.MethodGet,
+			target:   "/pb/linkmine?" + linkmineQuery.Encode(),
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"ok"}`,
+		},
+		{
+			name:     "GIVEN the same order again THEN expect it acknowledged as duplicate",
+			method:   http.MethodGet,
+			target:   "/pb/linkmine?" + linkmineQuery.Encode(),
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"duplicate"}`,
+		},
+		{
+			name:     "GIVEN a wrong secret THEN expect forbidden",
+			method:   http.MethodGet,
+			target:   "/pb/linkmine?sub1=Y2xpY2stMQ&order=o2&secret=wrong",
+			wantCode: http.StatusForbidden,
+			wantBody: `{"detail":"unauthorized postback","status":403}`,
+		},
+		{
+			name:     "GIVEN a missing order id THEN expect bad request",
+			method:   http.MethodGet,
+			target:   "/pb/linkmine?sub1=Y2xpY2stMQ&secret=s3cret",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"detail":"missing order id parameter order","status":400}`,
+		},
+		{
+			name:     "GIVEN a negative revenue THEN expect bad request",
+			method:   http.MethodGet,
+			target:   "/pb/linkmine?sub1=Y2xpY2stMQ&order=o5&amount=-5&secret=s3cret",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"detail":"invalid revenue -5: must be a finite non-negative number","status":400}`,
+		},
+		{
+			name:       "GIVEN a form postback from an allowed IP THEN expect it accepted",
+			method:     http.MethodPost,
+			target:     "/pb/adpacker",
+			body:       "click=click-1&oid=o1",
+			remoteAddr: "10.1.2.3:5555",
+			wantCode:   http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+		},
+		{
+			name:       "GIVEN a postback from an unknown IP THEN expect forbidden",
+			method:     http.MethodPost,
+			target:     "/pb/adpacker",
+			body:       "click=click-1&oid=o2",
+			remoteAddr: "192.168.1.1:5555",
+			wantCode:   http.StatusForbidden,
+			wantBody:   `{"detail":"unauthorized postback","status":403}`,
+		},
+		{
+			name:         "GIVEN a spoofed X-Forwarded-For from an untrusted peer THEN expect forbidden",
+			method:       http.MethodPost,
+			target:       "/pb/adpacker",
+			body:         "click=click-1&oid=o3",
+			remoteAddr:   "203.0.113.9:5555",
+			forwardedFor: "10.1.2.3",
+			wantCode:     http.StatusForbidden,
+			wantBody:     `{"detail":"unauthorized postback","status":403}`,
+		},
+		{
+			name:         "GIVEN an allowed X-Forwarded-For from a trusted proxy THEN expect it accepted",
+			method:       http.MethodPost,
+			target:       "/pb/adpacker",
+			body:         "click=click-1&oid=o4",
+			remoteAddr:   "172.16.0.2:5555",
+			forwardedFor: "10.1.2.3",
+			wantCode:     http.StatusOK,
+			wantBody:     `{"status":"ok"}`,
+		},
+		{
+			name:     "GIVEN a vendor without postback THEN expect not found",
+			method:   http.MethodGet,
+			target:   "/pb/no_postback",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"detail":"vendor key 'no_postback' has no postback","status":404}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tc.remoteAddr != "" {
+				req.RemoteAddr = tc.remoteAddr
+			}
+			if tc.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			receiver.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.JSONEq(t, tc.wantBody, w.Body.String())
+		})
+	}
+
+	// only the accepted conversions are emitted
+	conversions := emitter.events
+	require.Len(t, conversions, 3)
+	require.Equal(t, event.Event{
+		Type:      event.TypeConversion,
+		Timestamp: conversions[0].Timestamp,
+		Vendor:    "linkmine",
+		ClickID:   "click-1",
+		Outcome:   "ok",
+		OrderID:   "o1",
+		Revenue:   10,
+		Currency:  "KRW",
+	}, conversions[0])
+	require.Equal(t, []string{"o1", "o1", "o4"}, []string{conversions[0].OrderID, conversions[1].OrderID, conversions[2].OrderID})
+}
+
+func TestNewPostbackReceiverWithoutAuth(t *testing.T) {
+	_, err := NewPostbackReceiver(config.VendorConfig{Vendors: []config.Vendor{
+		{Name: "linkmine", Postback: config.Postback{ClickIDParam: "sub1", OrderIDParam: "order"}},
+	}}, nil, &event.NoEmitter{})
+	require.EqualError(t, err, "vendor linkmine: postback requires a secret or allowed IPs")
+}
+
+func TestNewPostbackReceiverSealedWithoutKeys(t *testing.T) {
+	_, err := NewPostbackReceiver(config.VendorConfig{Vendors: []config.Vendor{
+		{Name: "linkmine", Postback: config.Postback{ClickIDParam: "sub1", ClickIDEncoding: "sealed", OrderIDParam: "order", Secret: "s3cret"}},
+	}}, nil, &event.NoEmitter{})
+	require.EqualError(t, err, "vendor linkmine: click_id_encoding sealed requires click.seal_keys")
+}
+
+func TestNewPostbackReceiverRedisWithoutAddr(t *testing.T) {
+	_, err := NewPostbackReceiver(config.VendorConfig{Postback: config.PostbackConfig{Store: "redis"}}, nil, &event.NoEmitter{})
+	require.EqualError(t, err, "postback: the redis store requires redis.addr")
+}
+
+func TestPostbackReceiverSharedStore(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	cfg := config.VendorConfig{
+		Vendors: []config.Vendor{
+			{Name: "linkmine", Postback: config.Postback{ClickIDParam: "sub1", OrderIDParam: "order", Secret: "s3cret"}},
+		},
+		Postback: config.PostbackConfig{Store: "redis"},
+		Redis:    config.RedisConfig{Addr: server.Addr()},
+	}
+	// two pods sharing the store
+	first, err := NewPostbackReceiver(cfg, nil, &event.NoEmitter{})
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := NewPostbackReceiver(cfg, nil, &event.NoEmitter{})
+	require.NoError(t, err)
+
+	query := url.Values{"sub1": {utils.EncodeClickID("click-1")}, "order": {"o1"}, "secret": {"s3cret"}}
+	rec := httptest.NewRecorder()
+	first.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pb/linkmine?"+query.Encode(), nil))
+	require.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	second.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pb/linkmine?"+query.Encode(), nil))
+	require.JSONEq(t, `{"status":"duplicate"}`, rec.Body.String())
+
+	// the vendor retries while the store is unavailable
+	second.Close()
+	rec = httptest.NewRecorder()
+	second.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pb/linkmine?"+query.Encode(), nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
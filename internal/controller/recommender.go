@@ -5,20 +5,31 @@ import (
 	"net/http"
 
 	"errors"
+	"rec-vendor-api/internal/config"
 	controller_errors "rec-vendor-api/internal/controller/errors"
+	"rec-vendor-api/internal/openrtb"
+	"rec-vendor-api/internal/redact"
 	"rec-vendor-api/internal/vendor"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	queryFormat         = "format"
+	formatJSON          = "json"
+	formatOpenRTBNative = "openrtb_native"
+)
+
 type Recommender struct {
 	vendorRegistry map[string]vendor.Client
+	openRTBConfig  config.OpenRTBConfig
 }
 
-func NewRecommender(vendorRegistry map[string]vendor.Client) *Recommender {
+func NewRecommender(vendorRegistry map[string]vendor.Client, openRTBConfig config.OpenRTBConfig) *Recommender {
 	return &Recommender{
 		vendorRegistry: vendorRegistry,
+		openRTBConfig:  openRTBConfig,
 	}
 }
 
@@ -36,6 +47,7 @@ func NewRecommender(vendorRegistry map[string]vendor.Client) *Recommender {
 // @Param        adtype      query int    false "Ad Type (native → 3, else → 2)"
 // @Param        partner_id  query string false "Partner ID"
 // @Param        os          query string false "Operating System (android, ios)"
+// @Param        format      query string false "Response format (json, openrtb_native), default: json"
 // @Success      200 {object} []vendor.ProductInfo
 // @Failure      400 {object} map[string]string "Bad Request"
 // @Failure      500 {object} map[string]string "Internal Error"
@@ -43,12 +55,19 @@ func NewRecommender(vendorRegistry map[string]vendor.Client) *Recommender {
 func (c *Recommender) Recommend(ctx *gin.Context) {
 	var req vendor.Request
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		log.WithContext(ctx).WithError(err).Errorf("fail to bind query parameter, uri: %s", ctx.Request.RequestURI)
+		log.WithContext(ctx).WithError(err).Errorf("fail to bind query parameter, uri: %s", redact.URL(ctx, ctx.Request.RequestURI))
 		handleBadRequest(ctx, err)
 		return
 	}
 	req.ClientIP = ctx.ClientIP()
 
+	format := ctx.DefaultQuery(queryFormat, formatJSON)
+	if format != formatJSON && format != formatOpenRTBNative {
+		log.WithContext(ctx).Errorf("Invalid response format: %s", format)
+		handleBadRequest(ctx, fmt.Errorf("format '%s' not supported (supported: %s, %s)", format, formatJSON, formatOpenRTBNative))
+		return
+	}
+
 	vendorKey := ctx.Param("vendor_key")
 	vendorClient := c.vendorRegistry[vendorKey]
 	if vendorClient == nil {
@@ -70,5 +89,26 @@ func (c *Recommender) Recommend(ctx *gin.Context) {
 		handleInternalServerError(ctx, fmt.Errorf("fail to recommend any products for vendor %s. err: %w", vendorKey, err))
 		return
 	}
+
+	if format == formatOpenRTBNative {
+		ctx.JSON(http.StatusOK, toOpenRTBNative(req, response, c.openRTBConfig.ImpTrackers))
+		return
+	}
 	ctx.JSON(http.StatusOK, response)
 }
+
+// toOpenRTBNative is shared by the HTTP and gRPC APIs
+func toOpenRTBNative(req vendor.Request, products []vendor.ProductInfo, impTrackers []string) []openrtb.Response {
+	nativeProducts := make([]openrtb.Product, 0, len(products))
+	for _, p := range products {
+		nativeProducts = append(nativeProducts, openrtb.Product{
+			Title:         p.Title,
+			ImageURL:      p.Image,
+			Price:         p.Price,
+			SalePrice:     p.SalePrice,
+			ClickURL:      p.Url,
+			ImpressionURL: p.ImpressionURL,
+		})
+	}
+	return openrtb.FromProducts(nativeProducts, req.ImgWidth, req.ImgHeight, impTrackers)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"rec-vendor-api/internal/config"
 	controller_errors "rec-vendor-api/internal/controller/errors"
 	"testing"
 
@@ -46,6 +47,35 @@ func (ts *RecommenderTestSuite) TestRecommend() {
 			wantCode: http.StatusOK,
 			wantBody: `[{"product_id":"1","url":"url","image":"img","price":"","sale_price":"","currency":""}]`,
 		},
+		{
+			name:       "GIVEN the openrtb_native format THEN expect OpenRTB native responses",
+			vendorKey:  "test_vendor",
+			requestURL: "/r/test_vendor?user_id=123&click_id=456&w=100&h=200&format=openrtb_native",
+			setupMock: func(mc *vendor.MockClient) {
+				mockResp := []vendor.ProductInfo{{ProductID: "1", Title: "title", Url: "url", Image: "img", Price: "100", SalePrice: "80"}}
+				mc.EXPECT().GetUserRecommendationItems(gomock.Any(), gomock.Any()).Return(mockResp, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"native":{
+				"ver":"1.2",
+				"assets":[
+					{"id":2,"required":1,"img":{"type":3,"url":"img","w":100,"h":200}},
+					{"id":1,"title":{"text":"title"}},
+					{"id":3,"data":{"type":6,"value":"100"}},
+					{"id":4,"data":{"type":7,"value":"80"}}
+				],
+				"link":{"url":"url"},
+				"eventtrackers":[{"event":1,"method":1,"url":"https://imp.example.com/t"}]
+			}}]`,
+		},
+		{
+			name:       "GIVEN an unsupported format THEN expect a bad request response",
+			vendorKey:  "test_vendor",
+			requestURL: "/r/test_vendor?user_id=123&click_id=456&w=100&h=200&format=xml",
+			setupMock:  func(mc *vendor.MockClient) {},
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `{"detail":"format 'xml' not supported (supported: json, openrtb_native)", "status":400}`,
+		},
 		{
 			name:       "GIVEN an invalid vendor key THEN expect a bad request response",
 			vendorKey:  "bad_vendor",
@@ -93,7 +123,7 @@ func (ts *RecommenderTestSuite) TestRecommend() {
 
 			tc.setupMock(ts.mockClient)
 
-			r := NewRecommender(ts.vendorRegistry)
+			r := NewRecommender(ts.vendorRegistry, config.OpenRTBConfig{ImpTrackers: []string{"https://imp.example.com/t"}})
 			r.Recommend(c)
 
 			require.Equal(t, tc.wantCode, w.Code)
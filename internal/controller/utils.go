@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,3 +14,14 @@ func handleBadRequest(ctx *gin.Context, err error) {
 func handleInternalServerError(ctx *gin.Context, err error) {
 	ctx.JSON(http.StatusInternalServerError, gin.H{"status": http.StatusInternalServerError, "detail": err.Error()})
 }
+
+// writeJSON and writeJSONError serve the plain http.Handler endpoints in the same format as gin
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeJSONError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]any{"status": code, "detail": err.Error()})
+}
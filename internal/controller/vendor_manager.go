@@ -3,7 +3,10 @@ package controller
 import (
 	"net/http"
 	"net/url"
+
+	"rec-vendor-api/internal/auth"
 	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/telemetry"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,10 +17,12 @@ type VendorInfo struct {
 }
 
 type vendorManager struct {
-	vendors []VendorInfo
+	vendors       []VendorInfo
+	authenticator *auth.Authenticator
 }
 
-func NewVendorManager(cfg config.VendorConfig) *vendorManager {
+// NewVendorManager lists the vendors the caller can request, all of them if the authenticator is nil
+func NewVendorManager(cfg config.VendorConfig, authenticator *auth.Authenticator) *vendorManager {
 	vendors := make([]VendorInfo, 0, len(cfg.Vendors))
 	for _, vendor := range cfg.Vendors {
 		requestHost := ""
@@ -31,7 +36,8 @@ func NewVendorManager(cfg config.VendorConfig) *vendorManager {
 	}
 
 	return &vendorManager{
-		vendors: vendors,
+		vendors:       vendors,
+		authenticator: authenticator,
 	}
 }
 
@@ -42,5 +48,12 @@ func NewVendorManager(cfg config.VendorConfig) *vendorManager {
 // @Success 	200 {array} VendorInfo
 // @Router 		/vendors [get]
 func (vm *vendorManager) GetVendors(ctx *gin.Context) {
-	ctx.JSON(http.StatusOK, vm.vendors)
+	caller := telemetry.RequestInfoFromContext(ctx.Request.Context()).Caller
+	vendors := make([]VendorInfo, 0, len(vm.vendors))
+	for _, v := range vm.vendors {
+		if vm.authenticator.Allows(caller, v.VendorKey) {
+			vendors = append(vendors, v)
+		}
+	}
+	ctx.JSON(http.StatusOK, vendors)
 }
@@ -1,11 +1,14 @@
 package controller
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"rec-vendor-api/internal/auth"
 	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/telemetry"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -89,7 +92,54 @@ func (ts *VendorsTestSuite) TestGetVendors() {
 			c, _ := gin.CreateTestContext(w)
 			c.Request = httptest.NewRequest(http.MethodGet, "/vendors", nil)
 
-			vm := NewVendorManager(tc.vendorConfig)
+			vm := NewVendorManager(tc.vendorConfig, nil)
+			vm.GetVendors(c)
+
+			require.Equal(ts.T(), http.StatusOK, w.Code)
+			require.JSONEq(ts.T(), tc.wantBody, w.Body.String())
+		})
+	}
+}
+
+func (ts *VendorsTestSuite) TestGetVendorsRestrictedCaller() {
+	vendorConfig := config.VendorConfig{Vendors: []config.Vendor{
+		{Name: "vendor1", Request: config.URLPattern{URL: "https://api.vendor1.com/recommend"}},
+		{Name: "vendor2", Request: config.URLPattern{URL: "https://api.vendor2.com/recommend"}},
+	}}
+	authenticator, err := auth.New(config.AuthConfig{Enabled: true, Callers: []config.AuthCaller{
+		{Name: "restricted", APIKeys: []config.Secret{"k1"}, Vendors: []string{"vendor2"}},
+		{Name: "unrestricted", APIKeys: []config.Secret{"k2"}},
+	}})
+	require.NoError(ts.T(), err)
+	vm := NewVendorManager(vendorConfig, authenticator)
+
+	tt := []struct {
+		name     string
+		caller   string
+		wantBody string
+	}{
+		{
+			name:     "GIVEN a caller restricted to vendors THEN expect only its vendors",
+			caller:   "restricted",
+			wantBody: `[{"vendor_key": "vendor2", "request_host": "api.vendor2.com"}]`,
+		},
+		{
+			name:   "GIVEN a caller without vendors THEN expect all vendors",
+			caller: "unrestricted",
+			wantBody: `[
+				{"vendor_key": "vendor1", "request_host": "api.vendor1.com"},
+				{"vendor_key": "vendor2", "request_host": "api.vendor2.com"}
+			]`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			ctx := telemetry.RequestInfoToContext(context.Background(), telemetry.RequestInfo{Caller: tc.caller})
+			c.Request = httptest.NewRequest(http.MethodGet, "/vendors", nil).WithContext(ctx)
+
 			vm.GetVendors(c)
 
 			require.Equal(ts.T(), http.StatusOK, w.Code)
@@ -0,0 +1,146 @@
+// Package dedupe remembers the keys seen within a window, such as the postback orders and the impression tokens
+package dedupe
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"rec-vendor-api/internal/redis"
+	"rec-vendor-api/internal/telemetry"
+)
+
+// Store remembers the keys within the window it was built with
+type Store interface {
+	// FirstSeen reports whether the key was not seen within the window, and remembers it
+	FirstSeen(ctx context.Context, key string) (bool, error)
+}
+
+// MemoryStore is a Store local to the process, so each pod only deduplicates the keys it saw itself.
+// Expired keys are swept at sweepInterval, and a random key is evicted to add one beyond maxEntries.
+type MemoryStore struct {
+	name       string
+	mu         sync.Mutex
+	seen       map[string]time.Time
+	window     time.Duration
+	maxEntries int
+	now        func() time.Time
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+// NewMemoryStore returns a MemoryStore, its evictions are counted under name
+func NewMemoryStore(name string, window, sweepInterval time.Duration, maxEntries int) *MemoryStore {
+	s := &MemoryStore{
+		name:       name,
+		seen:       map[string]time.Time{},
+		window:     window,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		stop:       make(chan struct{}),
+	}
+	if sweepInterval > 0 {
+		go s.sweep(sweepInterval)
+	}
+	return s
+}
+
+func (s *MemoryStore) FirstSeen(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	expiresAt, ok := s.seen[key]
+	if ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	if !ok {
+		s.makeRoom(now)
+	}
+	s.seen[key] = now.Add(s.window)
+	return true, nil
+}
+
+// Close stops sweeping expired keys
+func (s *MemoryStore) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func (s *MemoryStore) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.removeExpired()
+		}
+	}
+}
+
+func (s *MemoryStore) removeExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpiredAt(s.now())
+}
+
+func (s *MemoryStore) removeExpiredAt(now time.Time) {
+	for k, expiresAt := range s.seen {
+		if !now.Before(expiresAt) {
+			delete(s.seen, k)
+		}
+	}
+}
+
+// makeRoom must be called with the lock held, it removes the expired keys once the store is full and
+// evicts a random one if none expired
+func (s *MemoryStore) makeRoom(now time.Time) {
+	if s.maxEntries <= 0 || len(s.seen) < s.maxEntries {
+		return
+	}
+	s.removeExpiredAt(now)
+	for k := range s.seen {
+		if len(s.seen) < s.maxEntries {
+			return
+		}
+		delete(s.seen, k)
+		telemetry.Metrics.StoreEvictedTotal.WithLabelValues(s.name).Inc()
+	}
+}
+
+// RedisStore is a Store shared by the pods, the keys expire in redis
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	window string
+}
+
+// NewRedisStore returns a RedisStore, its keys are prefixed with prefix
+func NewRedisStore(client *redis.Client, prefix string, window time.Duration) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix, window: strconv.FormatInt(max(window.Milliseconds(), 1), 10)}
+}
+
+func (s *RedisStore) FirstSeen(ctx context.Context, key string) (bool, error) {
+	replies, err := s.client.Do(ctx, []string{"SET", s.prefix + key, "1", "PX", s.window, "NX"})
+	if err != nil {
+		return false, err
+	}
+	// SET NX replies nil if the key was already set
+	return replies[0] != nil, nil
+}
+
+// Close closes the connections to redis
+func (s *RedisStore) Close() {
+	s.client.Close()
+}
+
+// Close stops the background work of the store, such as the sweeping of a MemoryStore
+func Close(s Store) {
+	if closer, ok := s.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package dedupe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/redis"
+	"rec-vendor-api/internal/redis/redistest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore("test", time.Hour, 0, 0)
+	defer s.Close()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	requireFirstSeen(t, s, "k1", true)
+	requireFirstSeen(t, s, "k1", false)
+	requireFirstSeen(t, s, "k2", true)
+
+	now = now.Add(2 * time.Hour)
+	s.removeExpired()
+	require.Empty(t, s.seen)
+	requireFirstSeen(t, s, "k1", true)
+}
+
+func TestMemoryStoreMaxEntries(t *testing.T) {
+	s := NewMemoryStore("test", time.Hour, 0, 2)
+	defer s.Close()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	requireFirstSeen(t, s, "k1", true)
+	now = now.Add(30 * time.Minute)
+	requireFirstSeen(t, s, "k2", true)
+
+	// the expired key is removed first
+	now = now.Add(30 * time.Minute)
+	requireFirstSeen(t, s, "k3", true)
+	requireFirstSeen(t, s, "k2", false)
+
+	// a random key is evicted if none expired
+	requireFirstSeen(t, s, "k4", true)
+	require.Len(t, s.seen, 2)
+}
+
+func TestRedisStore(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	s := NewRedisStore(redis.NewClient(redis.Config{Addr: server.Addr()}), "test:", time.Hour)
+	defer Close(s)
+
+	requireFirstSeen(t, s, "k1", true)
+	requireFirstSeen(t, s, "k1", false)
+	requireFirstSeen(t, s, "k2", true)
+	require.Equal(t, time.Hour, server.TTL("test:k1"))
+
+	server.Advance(time.Hour)
+	requireFirstSeen(t, s, "k1", true)
+}
+
+func requireFirstSeen(t *testing.T, s Store, key string, want bool) {
+	t.Helper()
+	got, err := s.FirstSeen(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
@@ -0,0 +1,64 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/plaxieappier/rec-go-kit/httpkit"
+)
+
+const (
+	defaultBufferSize    = 10000
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultHTTPTimeout   = 5 * time.Second
+)
+
+// NewEmitter builds the emitter of the configured sink, or a NoEmitter if there is none
+func NewEmitter(cfg config.EventsConfig) (Emitter, error) {
+	var sink Sink
+	switch cfg.Sink {
+	case "":
+		return &NoEmitter{}, nil
+	case "stdout":
+		sink = NewWriterSink(os.Stdout)
+	case "file":
+		if cfg.File.Path == "" {
+			return nil, errors.New("events file sink requires file.path")
+		}
+		fileSink, err := NewFileSink(cfg.File.Path, int64(cfg.File.MaxSizeMB)*1024*1024, cfg.File.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sink = fileSink
+	case "http":
+		if cfg.HTTP.URL == "" {
+			return nil, errors.New("events http sink requires http.url")
+		}
+		client, err := httpkit.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		sink = NewHTTPSink(client, cfg.HTTP.URL, withDefault(cfg.HTTP.Timeout, defaultHTTPTimeout))
+	default:
+		return nil, fmt.Errorf("unknown event sink %q", cfg.Sink)
+	}
+
+	return NewAsyncEmitter(
+		sink,
+		withDefault(cfg.BufferSize, defaultBufferSize),
+		withDefault(cfg.BatchSize, defaultBatchSize),
+		withDefault(cfg.FlushInterval, defaultFlushInterval),
+	), nil
+}
+
+func withDefault[T int | time.Duration](value, defaultValue T) T {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package event
+
+import (
+	"testing"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmitter(t *testing.T) {
+	emitter, err := NewEmitter(config.EventsConfig{})
+	require.NoError(t, err)
+	require.IsType(t, &NoEmitter{}, emitter)
+
+	tt := []struct {
+		name    string
+		cfg     config.EventsConfig
+		wantErr string
+	}{
+		{
+			name:    "GIVEN an unknown sink THEN expect an error",
+			cfg:     config.EventsConfig{Sink: "kafka"},
+			wantErr: `unknown event sink "kafka"`,
+		},
+		{
+			name:    "GIVEN a file sink without path THEN expect an error",
+			cfg:     config.EventsConfig{Sink: "file"},
+			wantErr: "events file sink requires file.path",
+		},
+		{
+			name:    "GIVEN an http sink without url THEN expect an error",
+			cfg:     config.EventsConfig{Sink: "http"},
+			wantErr: "events http sink requires http.url",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewEmitter(tc.cfg)
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"rec-vendor-api/internal/telemetry"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	dropReasonBufferFull = "buffer_full"
+	dropReasonSinkError  = "sink_error"
+	dropReasonClosed     = "closed"
+)
+
+// AsyncEmitter buffers up to bufferSize events and writes them to the sink in batches of batchSize,
+// or every flushInterval. Events are dropped and counted when the buffer is full or the sink fails.
+type AsyncEmitter struct {
+	sink          Sink
+	events        chan Event
+	batchSize     int
+	flushInterval time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+func NewAsyncEmitter(sink Sink, bufferSize, batchSize int, flushInterval time.Duration) *AsyncEmitter {
+	e := &AsyncEmitter{
+		sink:          sink,
+		events:        make(chan Event, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *AsyncEmitter) Emit(ev Event) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		telemetry.Metrics.EventDroppedTotal.WithLabelValues(ev.Type, dropReasonClosed).Inc()
+		return
+	}
+
+	select {
+	case e.events <- ev:
+	default:
+		telemetry.Metrics.EventDroppedTotal.WithLabelValues(ev.Type, dropReasonBufferFull).Inc()
+	}
+}
+
+func (e *AsyncEmitter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.events)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return e.sink.Close()
+}
+
+func (e *AsyncEmitter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, e.batchSize)
+	for {
+		select {
+		case ev, ok := <-e.events:
+			if !ok {
+				e.flush(batch)
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) >= e.batchSize {
+				e.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			e.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (e *AsyncEmitter) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := e.sink.Write(context.Background(), batch); err != nil {
+		log.Errorf("Fail to write %d events, err: %v", len(batch), err)
+		for _, ev := range batch {
+			telemetry.Metrics.EventDroppedTotal.WithLabelValues(ev.Type, dropReasonSinkError).Inc()
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/telemetry"
+
+	"github.com/stretchr/testify/require"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Event
+	err     error
+	block   chan struct{}
+	closed  bool
+}
+
+func (s *memorySink) Write(_ context.Context, events []Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return s.err
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []Event
+	for _, batch := range s.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+func droppedCount(t *testing.T, eventType, reason string) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, telemetry.Metrics.EventDroppedTotal.WithLabelValues(eventType, reason).Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestAsyncEmitterBatches(t *testing.T) {
+	sink := &memorySink{}
+	e := NewAsyncEmitter(sink, 10, 2, time.Hour)
+	droppedClosed := droppedCount(t, "batch", dropReasonClosed)
+
+	e.Emit(Event{Type: "batch", Vendor: "v1"})
+	e.Emit(Event{Type: "batch", Vendor: "v2"})
+	e.Emit(Event{Type: "batch", Vendor: "v3"})
+	require.NoError(t, e.Close(context.Background()))
+
+	require.Equal(t, [][]Event{
+		{{Type: "batch", Vendor: "v1"}, {Type: "batch", Vendor: "v2"}},
+		// the partial batch is flushed on close
+		{{Type: "batch", Vendor: "v3"}},
+	}, sink.batches)
+	require.True(t, sink.closed)
+
+	e.Emit(Event{Type: "batch"})
+	require.Equal(t, droppedClosed+1, droppedCount(t, "batch", dropReasonClosed))
+}
+
+func TestAsyncEmitterFlushInterval(t *testing.T) {
+	sink := &memorySink{}
+	e := NewAsyncEmitter(sink, 10, 100, 10*time.Millisecond)
+	defer e.Close(context.Background())
+
+	e.Emit(Event{Type: "interval"})
+	require.Eventually(t, func() bool { return len(sink.events()) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestAsyncEmitterDrops(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{})}
+	e := NewAsyncEmitter(sink, 1, 1, time.Hour)
+	droppedFull := droppedCount(t, "overflow", dropReasonBufferFull)
+	droppedSinkError := droppedCount(t, "overflow", dropReasonSinkError)
+
+	// the first event blocks the sink, the second fills the buffer, the third is dropped
+	e.Emit(Event{Type: "overflow"})
+	require.Eventually(t, func() bool { return len(e.events) == 0 }, time.Second, time.Millisecond)
+	e.Emit(Event{Type: "overflow"})
+	e.Emit(Event{Type: "overflow"})
+	require.Equal(t, droppedFull+1, droppedCount(t, "overflow", dropReasonBufferFull))
+
+	sink.err = errors.New("sink down")
+	close(sink.block)
+	require.NoError(t, e.Close(context.Background()))
+	require.Equal(t, droppedSinkError+2, droppedCount(t, "overflow", dropReasonSinkError))
+}
+
+func TestAsyncEmitterCloseTimeout(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{})}
+	defer close(sink.block)
+	e := NewAsyncEmitter(sink, 1, 1, time.Hour)
+	e.Emit(Event{Type: "timeout"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, e.Close(ctx), context.DeadlineExceeded)
+}
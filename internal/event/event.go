@@ -0,0 +1,65 @@
+// Package event emits structured serve and tracking events asynchronously to a pluggable sink
+package event
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	TypeServe      = "serve"
+	TypeImpression = "impression"
+	TypeClick      = "click"
+	TypeConversion = "conversion"
+
+	// CacheStatusNone is reported until vendor responses are cached
+	CacheStatusNone = "none"
+)
+
+// Event is one JSON line of the event log
+type Event struct {
+	Type          string    `json:"type"`
+	Timestamp     time.Time `json:"timestamp"`
+	ReqID         string    `json:"req_id,omitempty"`
+	TraceID       string    `json:"trace_id,omitempty"`
+	Method        string    `json:"method,omitempty"`
+	SiteID        string    `json:"site_id,omitempty"`
+	OID           string    `json:"oid,omitempty"`
+	BidObjID      string    `json:"bid_obj_id,omitempty"`
+	SubID         string    `json:"subid,omitempty"`
+	Vendor        string    `json:"vendor"`
+	ClickID       string    `json:"click_id,omitempty"`
+	LatencyMs     float64   `json:"latency_ms"`
+	Outcome       string    `json:"outcome"`
+	ProductIDs    []string  `json:"product_ids"`
+	ExperimentArm string    `json:"experiment_arm,omitempty"`
+	CacheStatus   string    `json:"cache_status,omitempty"`
+	// OrderID, Revenue and Currency are set on the conversion events
+	OrderID  string  `json:"order_id,omitempty"`
+	Revenue  float64 `json:"revenue,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+//go:generate mockgen -source=./event.go -destination=./event_mock.go -package=event
+
+// Emitter must not block the caller, events that cannot be delivered are dropped
+type Emitter interface {
+	Emit(e Event)
+	// Close flushes the buffered events, waiting at most until ctx is done
+	Close(ctx context.Context) error
+}
+
+// Sink writes a batch of events, it is only called by one goroutine at a time
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// NoEmitter discards every event, used when no sink is configured
+type NoEmitter struct{}
+
+func (e *NoEmitter) Emit(_ Event) {}
+
+func (e *NoEmitter) Close(_ context.Context) error {
+	return nil
+}
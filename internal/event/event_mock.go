@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./event.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./event.go -destination=./event_mock.go -package=event
+//
+
+// Package event is a generated GoMock package.
+package event
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEmitter is a mock of Emitter interface.
+type MockEmitter struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmitterMockRecorder
+}
+
+// MockEmitterMockRecorder is the mock recorder for MockEmitter.
+type MockEmitterMockRecorder struct {
+	mock *MockEmitter
+}
+
+// NewMockEmitter creates a new mock instance.
+func NewMockEmitter(ctrl *gomock.Controller) *MockEmitter {
+	mock := &MockEmitter{ctrl: ctrl}
+	mock.recorder = &MockEmitterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmitter) EXPECT() *MockEmitterMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockEmitter) Close(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockEmitterMockRecorder) Close(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockEmitter)(nil).Close), ctx)
+}
+
+// Emit mocks base method.
+func (m *MockEmitter) Emit(e Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Emit", e)
+}
+
+// Emit indicates an expected call of Emit.
+func (mr *MockEmitterMockRecorder) Emit(e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Emit", reflect.TypeOf((*MockEmitter)(nil).Emit), e)
+}
+
+// MockSink is a mock of Sink interface.
+type MockSink struct {
+	ctrl     *gomock.Controller
+	recorder *MockSinkMockRecorder
+}
+
+// MockSinkMockRecorder is the mock recorder for MockSink.
+type MockSinkMockRecorder struct {
+	mock *MockSink
+}
+
+// NewMockSink creates a new mock instance.
+func NewMockSink(ctrl *gomock.Controller) *MockSink {
+	mock := &MockSink{ctrl: ctrl}
+	mock.recorder = &MockSinkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSink) EXPECT() *MockSinkMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockSink) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockSinkMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSink)(nil).Close))
+}
+
+// Write mocks base method.
+func (m *MockSink) Write(ctx context.Context, events []Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockSinkMockRecorder) Write(ctx, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSink)(nil).Write), ctx, events)
+}
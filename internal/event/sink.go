@@ -0,0 +1,144 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/plaxieappier/rec-go-kit/httpkit"
+)
+
+// WriterSink writes the events as JSON lines, e.g. to stdout
+type WriterSink struct {
+	w io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(_ context.Context, events []Event) error {
+	return writeLines(s.w, events)
+}
+
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// FileSink appends the events as JSON lines to a file. When the file would exceed maxSizeBytes it is
+// rotated to <path>.1, shifting the older files up to <path>.<maxBackups>. A maxSizeBytes of 0 never rotates.
+type FileSink struct {
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	file         *os.File
+	size         int64
+}
+
+func NewFileSink(path string, maxSizeBytes int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{path: path, maxSizeBytes: maxSizeBytes, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) Write(_ context.Context, events []Event) error {
+	for _, ev := range events {
+		line, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if s.maxSizeBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSizeBytes {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := s.file.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups <= 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.open()
+	}
+
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(s.path, i), backupPath(s.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, backupPath(s.path, 1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// HTTPSink posts each batch of events as a JSON array
+type HTTPSink struct {
+	client  httpkit.Client
+	url     string
+	timeout time.Duration
+}
+
+func NewHTTPSink(client httpkit.Client, url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{client: client, url: url, timeout: timeout}
+}
+
+func (s *HTTPSink) Write(ctx context.Context, events []Event) error {
+	req := httpkit.NewRequest(s.url).SetBody(events)
+	_, err := s.client.Post(ctx, req, s.timeout, []int{200, 202, 204})
+	return err
+}
+
+func (s *HTTPSink) Close() error {
+	return nil
+}
+
+func writeLines(w io.Writer, events []Event) error {
+	enc := json.NewEncoder(w)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plaxieappier/rec-go-kit/httpkit"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, sink.Write(context.Background(), []Event{
+		{Type: TypeServe, Timestamp: ts, Vendor: "linkmine", Outcome: "ok", ProductIDs: []string{"1", "2"}, CacheStatus: CacheStatusNone},
+		{Type: TypeServe, Timestamp: ts, Vendor: "adpacker", Outcome: "no_products"},
+	}))
+
+	require.Equal(t, `{"type":"serve","timestamp":"2026-01-02T03:04:05Z","vendor":"linkmine","latency_ms":0,"outcome":"ok","product_ids":["1","2"],"cache_status":"none"}
+{"type":"serve","timestamp":"2026-01-02T03:04:05Z","vendor":"adpacker","latency_ms":0,"outcome":"no_products","product_ids":null}
+`, buf.String())
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	// each event line is 115 bytes, so every file holds two events
+	sink, err := NewFileSink(path, 250, 2)
+	require.NoError(t, err)
+
+	for _, vendor := range []string{"v1", "v2", "v3", "v4", "v5", "v6", "v7"} {
+		require.NoError(t, sink.Write(context.Background(), []Event{{Type: TypeServe, Vendor: vendor, Outcome: "ok"}}))
+	}
+	require.NoError(t, sink.Close())
+
+	vendorsIn := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var vendors []string
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			vendors = append(vendors, line[strings.Index(line, `"vendor":"`)+10:][:2])
+		}
+		return strings.Join(vendors, ",")
+	}
+	require.Equal(t, "v7", vendorsIn(path))
+	require.Equal(t, "v5,v6", vendorsIn(path+".1"))
+	require.Equal(t, "v3,v4", vendorsIn(path+".2"))
+	// the oldest file is dropped
+	_, err = os.Stat(path + ".3")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o644))
+
+	sink, err := NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), []Event{{Type: TypeServe}}))
+	require.NoError(t, sink.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(content), "\n"))
+}
+
+func TestHTTPSink(t *testing.T) {
+	client := httpkit.NewMockClient(gomock.NewController(t))
+	sink := NewHTTPSink(client, "https://events.example.com/batch", time.Second)
+	events := []Event{{Type: TypeServe, Vendor: "linkmine"}}
+
+	client.EXPECT().Post(gomock.Any(), httpkit.NewRequest("https://events.example.com/batch").SetBody(events), time.Second, []int{200, 202, 204}).
+		Return(&httpkit.Response{StatusCode: 200}, nil)
+	require.NoError(t, sink.Write(context.Background(), events))
+
+	client.EXPECT().Post(gomock.Any(), gomock.Any(), time.Second, gomock.Any()).Return(nil, errors.New("connection refused"))
+	require.EqualError(t, sink.Write(context.Background(), events), "connection refused")
+}
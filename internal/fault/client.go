@@ -0,0 +1,89 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/plaxieappier/rec-go-kit/httpkit"
+)
+
+// HTTPClient applies the fault carried by the request context to the vendor call: the latency counts
+// against the request timeout, the errors are the ones the vendor client categorizes, and the body faults
+// alter a successful response. The empty body fault is applied by the vendor client, since an empty
+// result has a different format for every vendor.
+type HTTPClient struct {
+	httpkit.Client
+}
+
+func NewHTTPClient(client httpkit.Client) *HTTPClient {
+	return &HTTPClient{Client: client}
+}
+
+func (c *HTTPClient) Get(ctx context.Context, req *httpkit.Request, timeout time.Duration, statuses []int) (*httpkit.Response, error) {
+	return c.do(ctx, timeout, func(ctx context.Context, timeout time.Duration) (*httpkit.Response, error) {
+		return c.Client.Get(ctx, req, timeout, statuses)
+	})
+}
+
+func (c *HTTPClient) Post(ctx context.Context, req *httpkit.Request, timeout time.Duration, statuses []int) (*httpkit.Response, error) {
+	return c.do(ctx, timeout, func(ctx context.Context, timeout time.Duration) (*httpkit.Response, error) {
+		return c.Client.Post(ctx, req, timeout, statuses)
+	})
+}
+
+func (c *HTTPClient) do(ctx context.Context, timeout time.Duration, call func(context.Context, time.Duration) (*httpkit.Response, error)) (*httpkit.Response, error) {
+	f, ok := FromContext(ctx)
+	if !ok || f.IsZero() {
+		return call(ctx, timeout)
+	}
+
+	if f.Latency > 0 {
+		if timeout > 0 && f.Latency >= timeout {
+			if err := sleep(ctx, timeout); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("fault injected: %w", context.DeadlineExceeded)
+		}
+		if err := sleep(ctx, f.Latency); err != nil {
+			return nil, err
+		}
+		timeout -= f.Latency
+	}
+
+	switch f.Error {
+	case ErrorTimeout:
+		return nil, fmt.Errorf("fault injected: %w", context.DeadlineExceeded)
+	case ErrorConnectionReset:
+		return nil, fmt.Errorf("fault injected: read: %w", syscall.ECONNRESET)
+	case ErrorHTTPStatus:
+		return &httpkit.Response{StatusCode: f.HTTPStatus}, fmt.Errorf("fault injected: invalid status code %d", f.HTTPStatus)
+	case ErrorUnknown:
+		return nil, errors.New("fault injected: unknown network error")
+	}
+
+	resp, err := call(ctx, timeout)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	switch f.Body {
+	case BodyTruncated:
+		resp.Body = resp.Body[:len(resp.Body)/2]
+	case BodyMalformed:
+		resp.Body = []byte(`{"fault injected": [`)
+	}
+	return resp, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,107 @@
+// Package fault injects vendor misbehaviour, such as latency, network errors and broken bodies, for testing
+package fault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carries a fault for a single request when header-driven injection is allowed,
+// e.g. "latency=300ms; error=http_status; status=503"
+const Header = "x-fault-inject"
+
+// Error classes, matching the categories of the vendor client
+const (
+	ErrorTimeout         = "timeout"
+	ErrorConnectionReset = "connection_reset"
+	ErrorHTTPStatus      = "http_status"
+	ErrorUnknown         = "unknown"
+)
+
+// Body faults applied to a successful vendor response
+const (
+	BodyTruncated = "truncated"
+	BodyMalformed = "malformed"
+	BodyEmpty     = "empty"
+)
+
+type Fault struct {
+	Latency    time.Duration
+	Error      string
+	HTTPStatus int
+	Body       string
+}
+
+func (f Fault) IsZero() bool {
+	return f == Fault{}
+}
+
+// ParseHeader parses the semicolon-separated key=value pairs of the fault header
+func ParseHeader(s string) (Fault, error) {
+	var f Fault
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Fault{}, fmt.Errorf("invalid fault %q, expected key=value", part)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "latency":
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return Fault{}, fmt.Errorf("invalid fault latency %q", value)
+			}
+			f.Latency = d
+		case "error":
+			f.Error = value
+		case "status":
+			code, err := strconv.Atoi(value)
+			if err != nil {
+				return Fault{}, fmt.Errorf("invalid fault status %q", value)
+			}
+			f.HTTPStatus = code
+		case "body":
+			f.Body = value
+		default:
+			return Fault{}, fmt.Errorf("unknown fault key %q", key)
+		}
+	}
+	return f, f.Validate()
+}
+
+func (f Fault) Validate() error {
+	switch f.Error {
+	case "", ErrorTimeout, ErrorConnectionReset, ErrorUnknown:
+	case ErrorHTTPStatus:
+		if f.HTTPStatus < 100 || f.HTTPStatus > 599 || f.HTTPStatus == http.StatusOK {
+			return fmt.Errorf("invalid fault status %d", f.HTTPStatus)
+		}
+	default:
+		return fmt.Errorf("unknown fault error %q", f.Error)
+	}
+	switch f.Body {
+	case "", BodyTruncated, BodyMalformed, BodyEmpty:
+	default:
+		return fmt.Errorf("unknown fault body %q", f.Body)
+	}
+	return nil
+}
+
+type faultKey struct{}
+
+func WithFault(ctx context.Context, f Fault) context.Context {
+	return context.WithValue(ctx, faultKey{}, f)
+}
+
+func FromContext(ctx context.Context) (Fault, bool) {
+	f, ok := ctx.Value(faultKey{}).(Fault)
+	return f, ok
+}
@@ -0,0 +1,130 @@
+package fault
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		want      Fault
+		wantError bool
+	}{
+		{
+			name:   "GIVEN latency and status error THEN parse both",
+			header: "latency=300ms; error=http_status; status=503",
+			want:   Fault{Latency: 300 * time.Millisecond, Error: ErrorHTTPStatus, HTTPStatus: 503},
+		},
+		{
+			name:   "GIVEN a body fault THEN parse it",
+			header: "body=truncated",
+			want:   Fault{Body: BodyTruncated},
+		},
+		{
+			name:   "GIVEN an empty header THEN return no fault",
+			header: "",
+			want:   Fault{},
+		},
+		{
+			name:      "GIVEN an unknown error class THEN return an error",
+			header:    "error=dns",
+			wantError: true,
+		},
+		{
+			name:      "GIVEN an http_status error without status THEN return an error",
+			header:    "error=http_status",
+			wantError: true,
+		},
+		{
+			name:      "GIVEN an unknown key THEN return an error",
+			header:    "color=red",
+			wantError: true,
+		},
+		{
+			name:      "GIVEN a pair without value THEN return an error",
+			header:    "latency",
+			wantError: true,
+		},
+		{
+			name:      "GIVEN a negative latency THEN return an error",
+			header:    "latency=-1s",
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHeader(tt.header)
+			if tt.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInjectorPick(t *testing.T) {
+	injector, err := NewInjector([]Rule{
+		{Vendors: []string{"keeta"}, Percent: 50, Error: ErrorTimeout},
+		{Percent: 100, Latency: 100 * time.Millisecond, LatencyMax: 300 * time.Millisecond, LatencyDistribution: DistributionUniform},
+	}, false)
+	require.NoError(t, err)
+
+	roll := 0.0
+	injector.randFloat = func() float64 { return roll }
+	injector.randExp = func() float64 { return 1 }
+
+	// the first matching rule is used, within its percentage
+	roll = 0.49
+	got, ok := injector.Pick("keeta")
+	require.True(t, ok)
+	require.Equal(t, Fault{Error: ErrorTimeout}, got)
+
+	roll = 0.5
+	_, ok = injector.Pick("keeta")
+	require.False(t, ok)
+
+	// rules without vendors apply to every vendor
+	roll = 0.5
+	got, ok = injector.Pick("adpacker")
+	require.True(t, ok)
+	require.Equal(t, Fault{Latency: 200 * time.Millisecond}, got)
+}
+
+func TestInjectorExponentialLatency(t *testing.T) {
+	injector, err := NewInjector([]Rule{{Percent: 100, Latency: 100 * time.Millisecond, LatencyDistribution: DistributionExponential}}, false)
+	require.NoError(t, err)
+	injector.randFloat = func() float64 { return 0 }
+	injector.randExp = func() float64 { return 2.5 }
+
+	got, ok := injector.Pick("any")
+	require.True(t, ok)
+	require.Equal(t, 250*time.Millisecond, got.Latency)
+}
+
+func TestNewInjectorInvalidRules(t *testing.T) {
+	_, err := NewInjector([]Rule{{Percent: 10, LatencyDistribution: "normal"}}, false)
+	require.Error(t, err)
+
+	_, err = NewInjector([]Rule{{Percent: 10, Latency: time.Second, LatencyMax: time.Millisecond, LatencyDistribution: DistributionUniform}}, false)
+	require.Error(t, err)
+
+	_, err = NewInjector([]Rule{{Percent: 10, Body: "gzip"}}, false)
+	require.Error(t, err)
+}
+
+func TestFaultContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	f := Fault{Body: BodyEmpty}
+	got, ok := FromContext(WithFault(context.Background(), f))
+	require.True(t, ok)
+	require.Equal(t, f, got)
+}
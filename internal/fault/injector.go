@@ -0,0 +1,95 @@
+package fault
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Latency distributions of a rule
+const (
+	DistributionFixed       = "fixed"
+	DistributionUniform     = "uniform"
+	DistributionExponential = "exponential"
+)
+
+// Rule injects its fault into the given percentage of the requests of its vendors, or of all vendors if none
+// are set. The latency is fixed, uniform between Latency and LatencyMax, or exponential with Latency as mean.
+type Rule struct {
+	Vendors             []string
+	Percent             float64
+	Latency             time.Duration
+	LatencyMax          time.Duration
+	LatencyDistribution string
+	Error               string
+	HTTPStatus          int
+	Body                string
+}
+
+// Injector picks the fault of a request from the rules, the first rule matching the vendor is used
+type Injector struct {
+	rules       []Rule
+	allowHeader bool
+	randFloat   func() float64
+	randExp     func() float64
+	faults      []Fault
+}
+
+func NewInjector(rules []Rule, allowHeader bool) (*Injector, error) {
+	faults := make([]Fault, len(rules))
+	for i, r := range rules {
+		switch r.LatencyDistribution {
+		case "", DistributionFixed, DistributionExponential:
+		case DistributionUniform:
+			if r.LatencyMax < r.Latency {
+				return nil, fmt.Errorf("fault rule %d: latency_max must not be lower than latency", i)
+			}
+		default:
+			return nil, fmt.Errorf("fault rule %d: unknown latency distribution %q", i, r.LatencyDistribution)
+		}
+		faults[i] = Fault{Latency: r.Latency, Error: r.Error, HTTPStatus: r.HTTPStatus, Body: r.Body}
+		if err := faults[i].Validate(); err != nil {
+			return nil, fmt.Errorf("fault rule %d: %w", i, err)
+		}
+	}
+	return &Injector{
+		rules:       rules,
+		allowHeader: allowHeader,
+		randFloat:   rand.Float64,
+		randExp:     rand.ExpFloat64,
+		faults:      faults,
+	}, nil
+}
+
+// AllowHeader reports whether a fault can be set per request with the fault header
+func (i *Injector) AllowHeader() bool {
+	return i.allowHeader
+}
+
+// Pick returns the fault to inject into a request to the vendor, if any
+func (i *Injector) Pick(vendorName string) (Fault, bool) {
+	for idx, r := range i.rules {
+		if len(r.Vendors) > 0 && !slices.Contains(r.Vendors, vendorName) {
+			continue
+		}
+		if i.randFloat()*100 >= r.Percent {
+			return Fault{}, false
+		}
+		f := i.faults[idx]
+		f.Latency = i.latency(r)
+		return f, true
+	}
+	return Fault{}, false
+}
+
+func (i *Injector) latency(r Rule) time.Duration {
+	switch r.LatencyDistribution {
+	case DistributionUniform:
+		return r.Latency + time.Duration(i.randFloat()*float64(r.LatencyMax-r.Latency))
+	case DistributionExponential:
+		return time.Duration(i.randExp() * float64(r.Latency))
+	default:
+		return r.Latency
+	}
+}
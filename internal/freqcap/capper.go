@@ -0,0 +1,84 @@
+package freqcap
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Rule caps a product to MaxImpressions per user within Window.
+// A rule without site IDs and vendors applies to all requests.
+type Rule struct {
+	Vendors        []string
+	SiteIDs        []string
+	MaxImpressions int
+	Window         time.Duration
+}
+
+// Capper applies the first rule matching the vendor and site of a request
+type Capper struct {
+	store Store
+	rules []Rule
+}
+
+func NewCapper(store Store, rules []Rule) *Capper {
+	return &Capper{store: store, rules: rules}
+}
+
+// Close stops the background work of the store, such as the sweeping of a MemoryStore
+func (c *Capper) Close() {
+	if closer, ok := c.store.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// Allowed returns the product IDs that are still under the cap, in the given order.
+// Requests without a user ID or a matching rule are not capped, and neither are requests
+// for which the store fails, since frequency capping should not make a vendor unavailable.
+func (c *Capper) Allowed(ctx context.Context, vendor, siteID, userID string, productIDs []string) []string {
+	rule, ok := c.rule(vendor, siteID)
+	if !ok || userID == "" {
+		return productIDs
+	}
+
+	counts, err := c.store.Counts(ctx, userID, productIDs)
+	if err != nil {
+		log.WithContext(ctx).Errorf("Fail to get frequency cap counts, skip capping. err: %v", err)
+		return productIDs
+	}
+
+	allowed := make([]string, 0, len(productIDs))
+	for _, productID := range productIDs {
+		if counts[productID] < rule.MaxImpressions {
+			allowed = append(allowed, productID)
+		}
+	}
+	return allowed
+}
+
+// Record counts an impression of each product for the user
+func (c *Capper) Record(ctx context.Context, vendor, siteID, userID string, productIDs []string) {
+	rule, ok := c.rule(vendor, siteID)
+	if !ok || userID == "" || len(productIDs) == 0 {
+		return
+	}
+
+	if err := c.store.Incr(ctx, userID, productIDs, rule.Window); err != nil {
+		log.WithContext(ctx).Errorf("Fail to record frequency cap impressions. err: %v", err)
+	}
+}
+
+func (c *Capper) rule(vendor, siteID string) (Rule, bool) {
+	for _, rule := range c.rules {
+		if len(rule.Vendors) > 0 && !slices.Contains(rule.Vendors, vendor) {
+			continue
+		}
+		if len(rule.SiteIDs) > 0 && !slices.Contains(rule.SiteIDs, siteID) {
+			continue
+		}
+		return rule, true
+	}
+	return Rule{}, false
+}
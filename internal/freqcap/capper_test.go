@@ -0,0 +1,104 @@
+package freqcap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCapperAllowed(t *testing.T) {
+	rules := []Rule{
+		{Vendors: []string{"linkmine"}, MaxImpressions: 1, Window: time.Hour},
+		{SiteIDs: []string{"site_a"}, MaxImpressions: 2, Window: time.Hour},
+	}
+
+	tt := []struct {
+		name      string
+		vendor    string
+		siteID    string
+		userID    string
+		setupMock func(store *MockStore)
+		want      []string
+	}{
+		{
+			name:   "GIVEN a vendor rule THEN drop products served at least MaxImpressions times",
+			vendor: "linkmine",
+			userID: "u1",
+			setupMock: func(store *MockStore) {
+				store.EXPECT().Counts(gomock.Any(), "u1", []string{"p1", "p2", "p3"}).Return(map[string]int{"p1": 1, "p3": 0}, nil)
+			},
+			want: []string{"p2", "p3"},
+		},
+		{
+			name:   "GIVEN a site rule THEN apply the site limit",
+			vendor: "adpacker",
+			siteID: "site_a",
+			userID: "u1",
+			setupMock: func(store *MockStore) {
+				store.EXPECT().Counts(gomock.Any(), "u1", gomock.Any()).Return(map[string]int{"p1": 1, "p2": 2}, nil)
+			},
+			want: []string{"p1", "p3"},
+		},
+		{
+			name:      "GIVEN no matching rule THEN keep all products",
+			vendor:    "adpacker",
+			siteID:    "site_b",
+			userID:    "u1",
+			setupMock: func(store *MockStore) {},
+			want:      []string{"p1", "p2", "p3"},
+		},
+		{
+			name:      "GIVEN an empty user ID THEN keep all products",
+			vendor:    "linkmine",
+			setupMock: func(store *MockStore) {},
+			want:      []string{"p1", "p2", "p3"},
+		},
+		{
+			name:   "GIVEN a store error THEN keep all products",
+			vendor: "linkmine",
+			userID: "u1",
+			setupMock: func(store *MockStore) {
+				store.EXPECT().Counts(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("kv unavailable"))
+			},
+			want: []string{"p1", "p2", "p3"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewMockStore(gomock.NewController(t))
+			tc.setupMock(store)
+
+			capper := NewCapper(store, rules)
+			got := capper.Allowed(context.Background(), tc.vendor, tc.siteID, tc.userID, []string{"p1", "p2", "p3"})
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCapperRecord(t *testing.T) {
+	store := NewMockStore(gomock.NewController(t))
+	capper := NewCapper(store, []Rule{{Vendors: []string{"linkmine"}, MaxImpressions: 1, Window: time.Hour}})
+
+	store.EXPECT().Incr(gomock.Any(), "u1", []string{"p1"}, time.Hour).Return(nil)
+	capper.Record(context.Background(), "linkmine", "", "u1", []string{"p1"})
+
+	// no matching rule, no user and no products are not recorded
+	capper.Record(context.Background(), "adpacker", "", "u1", []string{"p1"})
+	capper.Record(context.Background(), "linkmine", "", "", []string{"p1"})
+	capper.Record(context.Background(), "linkmine", "", "u1", nil)
+}
+
+func TestCapperClose(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 0)
+	NewCapper(store, nil).Close()
+	_, open := <-store.stop
+	require.False(t, open)
+
+	// stores without background work are left as they are
+	NewCapper(NewMockStore(gomock.NewController(t)), nil).Close()
+}
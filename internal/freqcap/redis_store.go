@@ -0,0 +1,74 @@
+package freqcap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"rec-vendor-api/internal/redis"
+)
+
+const redisKeyPrefix = "vendor-api:freqcap:"
+
+// RedisStore is a Store shared by the pods, the counters expire in redis
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Counts(ctx context.Context, userID string, productIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return counts, nil
+	}
+
+	cmd := make([]string, 0, len(productIDs)+1)
+	cmd = append(cmd, "MGET")
+	for _, productID := range productIDs {
+		cmd = append(cmd, redisKeyPrefix+key(userID, productID))
+	}
+	replies, err := s.client.Do(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := replies[0].([]any)
+	if !ok || len(values) != len(productIDs) {
+		return nil, fmt.Errorf("unexpected MGET reply %v", replies[0])
+	}
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		count, err := strconv.Atoi(fmt.Sprint(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter of product %s: %w", productIDs[i], err)
+		}
+		counts[productIDs[i]] = count
+	}
+	return counts, nil
+}
+
+// Incr creates the missing counters with the ttl before incrementing them, so an increment never
+// extends the window of an existing counter
+func (s *RedisStore) Incr(ctx context.Context, userID string, productIDs []string, ttl time.Duration) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	ttlMillis := strconv.FormatInt(max(ttl.Milliseconds(), 1), 10)
+	cmds := make([][]string, 0, 2*len(productIDs))
+	for _, productID := range productIDs {
+		k := redisKeyPrefix + key(userID, productID)
+		cmds = append(cmds, []string{"SET", k, "0", "PX", ttlMillis, "NX"}, []string{"INCR", k})
+	}
+	_, err := s.client.Do(ctx, cmds...)
+	return err
+}
+
+// Close closes the connections to redis
+func (s *RedisStore) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,138 @@
+package freqcap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"rec-vendor-api/internal/telemetry"
+)
+
+//go:generate mockgen -source=./store.go -destination=./store_mock.go -package=freqcap
+
+// Store keeps the number of times each product was served to a user.
+// Counters expire after the ttl given when they are first incremented, so an external KV
+// can implement it with INCR and EXPIRE.
+type Store interface {
+	Counts(ctx context.Context, userID string, productIDs []string) (map[string]int, error)
+	Incr(ctx context.Context, userID string, productIDs []string, ttl time.Duration) error
+}
+
+type entry struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryStore is a Store local to the process, so each pod caps the impressions it served itself.
+// Expired counters are swept at sweepInterval, and a random counter is evicted to add one beyond maxEntries.
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]entry
+	maxEntries int
+	now        func() time.Time
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+func NewMemoryStore(sweepInterval time.Duration, maxEntries int) *MemoryStore {
+	s := &MemoryStore{
+		entries:    map[string]entry{},
+		maxEntries: maxEntries,
+		now:        time.Now,
+		stop:       make(chan struct{}),
+	}
+	if sweepInterval > 0 {
+		go s.sweep(sweepInterval)
+	}
+	return s
+}
+
+func (s *MemoryStore) Counts(_ context.Context, userID string, productIDs []string) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	counts := make(map[string]int, len(productIDs))
+	for _, productID := range productIDs {
+		if e, ok := s.entries[key(userID, productID)]; ok && now.Before(e.expiresAt) {
+			counts[productID] = e.count
+		}
+	}
+	return counts, nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, userID string, productIDs []string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, productID := range productIDs {
+		k := key(userID, productID)
+		e, ok := s.entries[k]
+		if !ok {
+			s.makeRoom(now)
+		}
+		if !ok || !now.Before(e.expiresAt) {
+			e = entry{expiresAt: now.Add(ttl)}
+		}
+		e.count++
+		s.entries[k] = e
+	}
+	return nil
+}
+
+// Close stops sweeping expired counters
+func (s *MemoryStore) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func (s *MemoryStore) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.removeExpired()
+		}
+	}
+}
+
+func (s *MemoryStore) removeExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpiredAt(s.now())
+}
+
+func (s *MemoryStore) removeExpiredAt(now time.Time) {
+	for k, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+// makeRoom must be called with the lock held, it removes the expired counters once the store is full and
+// evicts a random one if none expired
+func (s *MemoryStore) makeRoom(now time.Time) {
+	if s.maxEntries <= 0 || len(s.entries) < s.maxEntries {
+		return
+	}
+	s.removeExpiredAt(now)
+	for k := range s.entries {
+		if len(s.entries) < s.maxEntries {
+			return
+		}
+		delete(s.entries, k)
+		telemetry.Metrics.StoreEvictedTotal.WithLabelValues(storeName).Inc()
+	}
+}
+
+const storeName = "freq_cap"
+
+func key(userID, productID string) string {
+	return userID + "\x00" + productID
+}
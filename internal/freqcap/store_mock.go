@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./store.go -destination=./store_mock.go -package=freqcap
+//
+
+// Package freqcap is a generated GoMock package.
+package freqcap
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// Counts mocks base method.
+func (m *MockStore) Counts(ctx context.Context, userID string, productIDs []string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Counts", ctx, userID, productIDs)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Counts indicates an expected call of Counts.
+func (mr *MockStoreMockRecorder) Counts(ctx, userID, productIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Counts", reflect.TypeOf((*MockStore)(nil).Counts), ctx, userID, productIDs)
+}
+
+// Incr mocks base method.
+func (m *MockStore) Incr(ctx context.Context, userID string, productIDs []string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", ctx, userID, productIDs, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockStoreMockRecorder) Incr(ctx, userID, productIDs, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockStore)(nil).Incr), ctx, userID, productIDs, ttl)
+}
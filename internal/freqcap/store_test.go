@@ -0,0 +1,89 @@
+package freqcap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/redis"
+	"rec-vendor-api/internal/redis/redistest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(0, 0)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p1", "p2"}, time.Hour))
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p1"}, time.Hour))
+	require.NoError(t, store.Incr(ctx, "u2", []string{"p1"}, time.Hour))
+
+	counts, err := store.Counts(ctx, "u1", []string{"p1", "p2", "p3"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"p1": 2, "p2": 1}, counts)
+
+	// counters expire after the ttl given at the first increment
+	now = now.Add(time.Hour)
+	counts, err = store.Counts(ctx, "u1", []string{"p1", "p2"})
+	require.NoError(t, err)
+	require.Empty(t, counts)
+
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p1"}, time.Hour))
+	counts, err = store.Counts(ctx, "u1", []string{"p1"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"p1": 1}, counts)
+
+	now = now.Add(2 * time.Hour)
+	store.removeExpired()
+	require.Empty(t, store.entries)
+}
+
+func TestMemoryStoreMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(0, 2)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p1"}, time.Minute))
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p2"}, time.Hour))
+
+	// the expired counter is removed first
+	now = now.Add(time.Minute)
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p3"}, time.Hour))
+	counts, err := store.Counts(ctx, "u1", []string{"p1", "p2", "p3"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"p2": 1, "p3": 1}, counts)
+
+	// a random counter is evicted if none expired, and existing counters are still incremented
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p4"}, time.Hour))
+	require.Len(t, store.entries, 2)
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p4"}, time.Hour))
+	counts, err = store.Counts(ctx, "u1", []string{"p4"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"p4": 2}, counts)
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	server := redistest.NewServer(t, "")
+	store := NewRedisStore(redis.NewClient(redis.Config{Addr: server.Addr()}))
+	defer store.Close()
+
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p1", "p2"}, time.Hour))
+	server.Advance(time.Minute)
+	require.NoError(t, store.Incr(ctx, "u1", []string{"p1"}, time.Hour))
+	require.NoError(t, store.Incr(ctx, "u2", []string{"p1"}, time.Hour))
+
+	counts, err := store.Counts(ctx, "u1", []string{"p1", "p2", "p3"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"p1": 2, "p2": 1}, counts)
+
+	// counters expire after the ttl given at the first increment
+	server.Advance(59 * time.Minute)
+	counts, err = store.Counts(ctx, "u1", []string{"p1", "p2"})
+	require.NoError(t, err)
+	require.Empty(t, counts)
+}
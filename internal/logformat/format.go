@@ -1,6 +1,7 @@
 package logformat
 
 import (
+	"rec-vendor-api/internal/redact"
 	"rec-vendor-api/internal/telemetry"
 
 	"github.com/plaxieappier/rec-go-kit/logkit"
@@ -17,13 +18,14 @@ type LogFormat struct {
 	BidObjID  string `json:"bid_obj_id"`
 	ReqID     string `json:"request_id"`
 	TraceID   string `json:"trace_id"`
+	Caller    string `json:"caller,omitempty"`
 }
 
 func (l *LogFormat) PrepareFormat(entry *log.Entry) any {
 	requestInfo := telemetry.RequestInfoFromContext(entry.Context)
 
 	return LogFormat{
-		BaseLogFormat: l.BaseLogFormat.PrepareFormat(entry).(logkit.BaseLogFormat),
+		BaseLogFormat: l.BaseLogFormat.PrepareFormat(redactEntry(entry, requestInfo.VendorKey)).(logkit.BaseLogFormat),
 		SiteId:        requestInfo.SiteID,
 		OID:           requestInfo.OID,
 		VendorKey:     requestInfo.VendorKey,
@@ -31,5 +33,21 @@ func (l *LogFormat) PrepareFormat(entry *log.Entry) any {
 		BidObjID:      requestInfo.BidObjID,
 		ReqID:         requestInfo.ReqID,
 		TraceID:       requestInfo.TraceID,
+		Caller:        requestInfo.Caller,
 	}
 }
+
+// redactEntry returns a copy of the entry with its message and fields redacted, unless the vendor is debugged
+func redactEntry(entry *log.Entry, vendorKey string) *log.Entry {
+	r := redact.Default()
+	if r.Debug(vendorKey) {
+		return entry
+	}
+	redacted := *entry
+	redacted.Message = r.Text(entry.Message)
+	redacted.Data = make(log.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		redacted.Data[key] = r.Field(key, value)
+	}
+	return &redacted
+}
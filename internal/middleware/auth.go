@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"rec-vendor-api/internal/auth"
+	"rec-vendor-api/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authStatusOK              = "ok"
+	authStatusUnauthenticated = "unauthenticated"
+	authStatusForbidden       = "forbidden"
+	unknownCaller             = "unknown"
+)
+
+// Auth authenticates the caller by the API key header and authorizes its vendor key, the caller is set on the
+// request info. It must be installed after RequestInfo.
+func Auth(a *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.Exempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		ctx, err := authenticate(c.Request.Context(), a, c.GetHeader(a.Header()), "")
+		if err != nil {
+			code := http.StatusUnauthorized
+			if errors.Is(err, auth.ErrForbidden) {
+				code = http.StatusForbidden
+			}
+			c.AbortWithStatusJSON(code, gin.H{"status": code, "detail": err.Error()})
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// AuthUnaryInterceptor is the gRPC counterpart of Auth, the caller is also identified by its verified client
+// certificate. It must be installed after the request info interceptor.
+func AuthUnaryInterceptor(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if a.Exempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		key := ""
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(a.Header()); len(values) > 0 {
+			key = values[0]
+		}
+		ctx, err := authenticate(ctx, a, key, peerCommonName(ctx))
+		if errors.Is(err, auth.ErrForbidden) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticate returns the context with the caller on its request info
+func authenticate(ctx context.Context, a *auth.Authenticator, key, commonName string) (context.Context, error) {
+	requestInfo := telemetry.RequestInfoFromContext(ctx)
+	caller, err := a.Authenticate(key, commonName)
+	if err != nil {
+		telemetry.Metrics.AuthTotal.WithLabelValues(unknownCaller, authStatusUnauthenticated).Inc()
+		return ctx, err
+	}
+	if err := a.Authorize(caller, requestInfo.VendorKey); err != nil {
+		telemetry.Metrics.AuthTotal.WithLabelValues(caller, authStatusForbidden).Inc()
+		return ctx, err
+	}
+	telemetry.Metrics.AuthTotal.WithLabelValues(caller, authStatusOK).Inc()
+	requestInfo.Caller = caller
+	return telemetry.RequestInfoToContext(ctx, requestInfo), nil
+}
+
+// peerCommonName returns the common name of the verified client certificate of the gRPC peer
+func peerCommonName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
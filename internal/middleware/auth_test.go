@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rec-vendor-api/internal/auth"
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/constants"
+	"rec-vendor-api/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func newTestAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+	a, err := auth.New(config.AuthConfig{
+		Enabled: true,
+		Callers: []config.AuthCaller{
+			{Name: "bidder", APIKeys: []config.Secret{"bidder-key"}},
+			{Name: "sdk", APIKeys: []config.Secret{"sdk-key"}, CommonNames: []string{"sdk.internal"}, Vendors: []string{"keeta"}},
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tt := []struct {
+		name       string
+		path       string
+		key        string
+		wantCode   int
+		wantCaller string
+	}{
+		{
+			name:       "GIVEN a valid key THEN expect the caller in the request info",
+			path:       "/r/replace",
+			key:        "bidder-key",
+			wantCode:   http.StatusOK,
+			wantCaller: "bidder",
+		},
+		{
+			name:     "GIVEN no key THEN expect unauthorized",
+			path:     "/r/replace",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "GIVEN a vendor outside the caller allowlist THEN expect forbidden",
+			path:     "/r/replace",
+			key:      "sdk-key",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:       "GIVEN a vendor of the caller allowlist THEN expect the caller in the request info",
+			path:       "/r/keeta",
+			key:        "sdk-key",
+			wantCode:   http.StatusOK,
+			wantCaller: "sdk",
+		},
+		{
+			name:     "GIVEN an exempt path THEN expect no authentication",
+			path:     "/healthz",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			handler := func(c *gin.Context) {
+				got = telemetry.RequestInfoFromContext(c.Request.Context()).Caller
+				c.Status(http.StatusOK)
+			}
+			r := gin.New()
+			r.Use(RequestInfo(), Auth(newTestAuthenticator(t)))
+			r.GET("/r/:vendor_key", handler)
+			r.GET("/healthz", handler)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.key != "" {
+				req.Header.Set(auth.DefaultHeader, tc.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Equal(t, tc.wantCaller, got)
+		})
+	}
+}
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	verified := func(commonName string) *peer.Peer {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+		return &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}}
+	}
+
+	tt := []struct {
+		name       string
+		method     string
+		vendorKey  string
+		key        string
+		peer       *peer.Peer
+		wantCode   codes.Code
+		wantCaller string
+	}{
+		{
+			name:       "GIVEN a valid key in the metadata THEN expect the caller in the request info",
+			method:     constants.FullMethodGetRecommendations,
+			vendorKey:  "replace",
+			key:        "bidder-key",
+			wantCode:   codes.OK,
+			wantCaller: "bidder",
+		},
+		{
+			name:       "GIVEN a verified client certificate THEN expect its caller",
+			method:     constants.FullMethodGetRecommendations,
+			vendorKey:  "keeta",
+			peer:       verified("sdk.internal"),
+			wantCode:   codes.OK,
+			wantCaller: "sdk",
+		},
+		{
+			name:      "GIVEN a certificate caller outside its allowlist THEN expect permission denied",
+			method:    constants.FullMethodGetRecommendations,
+			vendorKey: "replace",
+			peer:      verified("sdk.internal"),
+			wantCode:  codes.PermissionDenied,
+		},
+		{
+			name:      "GIVEN an unknown certificate THEN expect unauthenticated",
+			method:    constants.FullMethodGetRecommendations,
+			vendorKey: "replace",
+			peer:      verified("other.internal"),
+			wantCode:  codes.Unauthenticated,
+		},
+		{
+			name:     "GIVEN the health check THEN expect no authentication",
+			method:   constants.FullMethodHealthCheck,
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := telemetry.RequestInfoToContext(context.Background(), telemetry.RequestInfo{VendorKey: tc.vendorKey})
+			if tc.key != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(auth.DefaultHeader, tc.key))
+			}
+			if tc.peer != nil {
+				ctx = peer.NewContext(ctx, tc.peer)
+			}
+
+			var got string
+			interceptor := AuthUnaryInterceptor(newTestAuthenticator(t))
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, _ any) (any, error) {
+				got = telemetry.RequestInfoFromContext(ctx).Caller
+				return nil, nil
+			})
+
+			require.Equal(t, tc.wantCode, status.Code(err))
+			require.Equal(t, tc.wantCaller, got)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"rec-vendor-api/internal/fault"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// FaultInjection sets the fault of the fault header on the request context, it must only be installed when
+// header-driven fault injection is allowed
+func FaultInjection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(fault.Header)
+		if header == "" {
+			c.Next()
+			return
+		}
+		f, err := fault.ParseHeader(header)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "detail": err.Error()})
+			return
+		}
+		c.Request = c.Request.WithContext(fault.WithFault(c.Request.Context(), f))
+		c.Next()
+	}
+}
+
+// FaultUnaryInterceptor is the gRPC counterpart of FaultInjection
+func FaultUnaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(fault.Header)
+	if len(values) == 0 || values[0] == "" {
+		return handler(ctx, req)
+	}
+	f, err := fault.ParseHeader(values[0])
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid fault header: %v", err)
+	}
+	return handler(fault.WithFault(ctx, f), req)
+}
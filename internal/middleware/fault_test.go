@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/fault"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestFaultInjection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tt := []struct {
+		name      string
+		header    string
+		wantCode  int
+		wantFault *fault.Fault
+	}{
+		{
+			name:      "GIVEN a fault header THEN expect the fault in the request context",
+			header:    "latency=50ms; error=timeout",
+			wantCode:  http.StatusOK,
+			wantFault: &fault.Fault{Latency: 50 * time.Millisecond, Error: fault.ErrorTimeout},
+		},
+		{
+			name:     "GIVEN no fault header THEN expect no fault",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "GIVEN an invalid fault header THEN expect bad request",
+			header:   "error=dns",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var got *fault.Fault
+			r := gin.New()
+			r.Use(FaultInjection())
+			r.GET("/", func(c *gin.Context) {
+				if f, ok := fault.FromContext(c.Request.Context()); ok {
+					got = &f
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(fault.Header, tc.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code)
+			require.Equal(t, tc.wantFault, got)
+		})
+	}
+}
+
+func TestFaultUnaryInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, _ any) (any, error) {
+		f, _ := fault.FromContext(ctx)
+		return f, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(fault.Header, "body=empty"))
+	resp, err := FaultUnaryInterceptor(ctx, nil, nil, handler)
+	require.NoError(t, err)
+	require.Equal(t, fault.Fault{Body: fault.BodyEmpty}, resp)
+
+	resp, err = FaultUnaryInterceptor(context.Background(), nil, nil, handler)
+	require.NoError(t, err)
+	require.Equal(t, fault.Fault{}, resp)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(fault.Header, "body=gzip"))
+	_, err = FaultUnaryInterceptor(ctx, nil, nil, handler)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,129 @@
+// Package mockvendor emulates the vendor recommendation APIs for offline development and e2e tests
+package mockvendor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Family is a vendor response format understood by one of the unmarshalers
+type Family string
+
+const (
+	FamilyCoupang  Family = "coupang"  // plain array, unmarshaler.CoupangPartner
+	FamilyAdpacker Family = "adpacker" // {"data": [...]}, unmarshaler.Adpacker
+	FamilyReplace  Family = "replace"  // {"rCode", "data": {"result": [...]}}, unmarshaler.Replace
+	FamilyWrapped  Family = "wrapped"  // {"rCode", "data": [...]}, unmarshaler.WrappedCoupangPartner
+	FamilyKeeta    Family = "keeta"    // {"code", "data": {"items": [...]}}, unmarshaler.Keeta
+	FamilyAdforus  Family = "adforus"  // plain array with names and prices, unmarshaler.Adforus
+)
+
+var Families = []Family{FamilyCoupang, FamilyAdpacker, FamilyReplace, FamilyWrapped, FamilyKeeta, FamilyAdforus}
+
+func ParseFamily(s string) (Family, error) {
+	for _, f := range Families {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown response family %q", s)
+}
+
+// Product is a generated mock product, rendered into each family's own field names
+type Product struct {
+	ID        int
+	URL       string
+	Image     string
+	Name      string
+	Price     int
+	SalePrice int
+}
+
+// NewProducts generates n deterministic products served from baseURL
+func NewProducts(baseURL string, n int) []Product {
+	products := make([]Product, n)
+	for i := range products {
+		id := i + 1
+		products[i] = Product{
+			ID:        id,
+			URL:       fmt.Sprintf("%s/products/%d", baseURL, id),
+			Image:     fmt.Sprintf("%s/images/%d.jpg", baseURL, id),
+			Name:      fmt.Sprintf("Mock product %d", id),
+			Price:     10000 + 1000*id,
+			SalePrice: 9000 + 1000*id,
+		}
+	}
+	return products
+}
+
+type coupangItem struct {
+	ProductID    int    `json:"productId"`
+	ProductName  string `json:"productName"`
+	ProductURL   string `json:"productUrl"`
+	ProductImage string `json:"productImage"`
+}
+
+type keetaItem struct {
+	ID        string `json:"id"`
+	Deeplink  string `json:"deeplink"`
+	Price     string `json:"price"`
+	SalePrice string `json:"salePrice"`
+	Currency  string `json:"currency"`
+}
+
+type adforusItem struct {
+	ProductID    string `json:"productId"`
+	ProductName  string `json:"productName"`
+	ProductPrice int    `json:"productPrice"`
+	ProductImage string `json:"productImage"`
+	ProductURL   string `json:"productUrl"`
+}
+
+// Render encodes the products in the response format of the family
+func Render(family Family, products []Product) ([]byte, error) {
+	switch family {
+	case FamilyCoupang:
+		return json.Marshal(coupangItems(products))
+	case FamilyAdpacker:
+		return json.Marshal(map[string]any{"data": coupangItems(products)})
+	case FamilyReplace:
+		return json.Marshal(map[string]any{"rCode": "0", "rMessage": "", "data": map[string]any{"result": coupangItems(products)}})
+	case FamilyWrapped:
+		return json.Marshal(map[string]any{"rCode": "0", "rMessage": "", "data": coupangItems(products)})
+	case FamilyKeeta:
+		items := make([]keetaItem, len(products))
+		for i, p := range products {
+			items[i] = keetaItem{
+				ID:        strconv.Itoa(p.ID),
+				Deeplink:  p.URL,
+				Price:     strconv.Itoa(p.Price),
+				SalePrice: strconv.Itoa(p.SalePrice),
+				Currency:  "HKD",
+			}
+		}
+		return json.Marshal(map[string]any{"code": 0, "msg": "success", "data": map[string]any{"bid": len(items) > 0, "items": items}})
+	case FamilyAdforus:
+		items := make([]adforusItem, len(products))
+		for i, p := range products {
+			items[i] = adforusItem{
+				ProductID:    strconv.Itoa(p.ID),
+				ProductName:  p.Name,
+				ProductPrice: p.SalePrice,
+				ProductImage: p.Image,
+				ProductURL:   p.URL,
+			}
+		}
+		return json.Marshal(items)
+	default:
+		return nil, fmt.Errorf("unknown response family %q", family)
+	}
+}
+
+func coupangItems(products []Product) []coupangItem {
+	items := make([]coupangItem, len(products))
+	for i, p := range products {
+		items[i] = coupangItem{ProductID: p.ID, ProductName: p.Name, ProductURL: p.URL, ProductImage: p.Image}
+	}
+	return items
+}
@@ -0,0 +1,167 @@
+package mockvendor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/strategy/header"
+	"rec-vendor-api/internal/strategy/unmarshaler"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	products := NewProducts("https://mock.test", 2)
+	tests := []struct {
+		family      Family
+		unmarshaler unmarshaler.Strategy
+		want        unmarshaler.PartnerResp
+	}{
+		{family: FamilyCoupang, unmarshaler: &unmarshaler.CoupangPartner{}, want: unmarshaler.PartnerResp{ProductID: "1", ProductURL: "https://mock.test/products/1", ProductImage: "https://mock.test/images/1.jpg", ProductTitle: "Mock product 1"}},
+		{family: FamilyAdpacker, unmarshaler: &unmarshaler.Adpacker{}, want: unmarshaler.PartnerResp{ProductID: "1", ProductURL: "https://mock.test/products/1", ProductImage: "https://mock.test/images/1.jpg", ProductTitle: "Mock product 1"}},
+		{family: FamilyReplace, unmarshaler: &unmarshaler.Replace{}, want: unmarshaler.PartnerResp{ProductID: "1", ProductURL: "https://mock.test/products/1", ProductImage: "https://mock.test/images/1.jpg", ProductTitle: "Mock product 1"}},
+		{family: FamilyWrapped, unmarshaler: &unmarshaler.WrappedCoupangPartner{}, want: unmarshaler.PartnerResp{ProductID: "1", ProductURL: "https://mock.test/products/1", ProductImage: "https://mock.test/images/1.jpg", ProductTitle: "Mock product 1"}},
+		{family: FamilyKeeta, unmarshaler: &unmarshaler.Keeta{}, want: unmarshaler.PartnerResp{ProductID: "1", ProductURL: "https://mock.test/products/1", ProductPrice: "11000", ProductSalePrice: "10000", ProductCurrency: "HKD"}},
+		{family: FamilyAdforus, unmarshaler: &unmarshaler.Adforus{}, want: unmarshaler.PartnerResp{ProductID: "1", ProductURL: "https://mock.test/products/1", ProductTitle: "Mock product 1", ProductSalePrice: "10000"}},
+	}
+	for _, tt := range tests {
+		t.Run("GIVEN the "+string(tt.family)+" family THEN its unmarshaler parses the response", func(t *testing.T) {
+			body, err := Render(tt.family, products)
+			require.NoError(t, err)
+
+			got, err := tt.unmarshaler.UnmarshalResponse(context.Background(), body)
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+			require.Equal(t, tt.want, got[0])
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer(Config{Products: 3, ProductBaseURL: "https://mock.test"})
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "GIVEN a family path THEN serve its products",
+			target:     "/adpacker/reco/subid",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":[{"productId":1,`,
+		},
+		{
+			name:       "GIVEN mock_status THEN answer with the status",
+			target:     "/coupang?mock_status=503",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "GIVEN mock_empty THEN serve no products",
+			target:     "/keeta?mock_empty=true",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"code":0,"data":{"bid":false,"items":[]},"msg":"success"}`,
+		},
+		{
+			name:       "GIVEN mock_malformed THEN serve a truncated body",
+			target:     "/wrapped?mock_malformed=1",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data": [{"productId": `,
+		},
+		{
+			name:       "GIVEN an invalid mock parameter THEN return 400",
+			target:     "/coupang?mock_latency=soon",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "GIVEN an unknown family THEN return 404",
+			target:     "/unknown",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			require.True(t, strings.HasPrefix(w.Body.String(), tt.wantBody), w.Body.String())
+		})
+	}
+}
+
+func TestServerScenario(t *testing.T) {
+	server := NewServer(Config{Products: 1})
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/_scenario/replace?mock_status=500&mock_latency=10ms", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, Scenario{Latency: 10 * time.Millisecond, Status: http.StatusInternalServerError}, server.Scenario(FamilyReplace))
+	require.Equal(t, Scenario{}, server.Scenario(FamilyKeeta))
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/replace", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// the query parameters override the family scenario
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/replace?mock_status=200", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_scenario", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"replace":{"latency":"10ms","status":500,"empty":false,"malformed":false}`)
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/_scenario/replace", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, Scenario{}, server.Scenario(FamilyReplace))
+}
+
+func TestVerifySignatures(t *testing.T) {
+	server := httptest.NewServer(NewServer(Config{
+		Products:         1,
+		KeetaApp:         "app",
+		KeetaSecret:      "keeta-secret",
+		ReplaceAccessKey: "access",
+		ReplaceSecret:    "replace-secret",
+	}))
+	defer server.Close()
+
+	send := func(method, target string, headers map[string]string) int {
+		req, err := http.NewRequest(method, target, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode
+	}
+
+	keetaURL := server.URL + "/keeta/api/rl-recommend?reqId=c1&ip=127.0.0.1&sceneType=s&ver=1"
+	keeta := &header.KeetaHeader{SCaApp: "app", SCaSecret: "keeta-secret", Clock: &header.ClockImpl{}}
+	require.Equal(t, http.StatusOK, send(http.MethodGet, keetaURL, keeta.GenerateHeaders(header.Params{RequestURL: keetaURL, UserID: "u1"})))
+	wrongKeeta := &header.KeetaHeader{SCaApp: "app", SCaSecret: "wrong", Clock: &header.ClockImpl{}}
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, keetaURL, wrongKeeta.GenerateHeaders(header.Params{RequestURL: keetaURL, UserID: "u1"})))
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodGet, keetaURL, nil))
+
+	replaceURL := server.URL + "/replace/v2/products/reco"
+	replace := &header.ReplaceHeader{AccessKey: "access", SecretKey: "replace-secret", Clock: &header.ClockImpl{}}
+	params := header.Params{RequestURL: replaceURL, HTTPMethod: http.MethodPost}
+	require.Equal(t, http.StatusOK, send(http.MethodPost, replaceURL, replace.GenerateHeaders(params)))
+	wrongReplace := &header.ReplaceHeader{AccessKey: "other", SecretKey: "replace-secret", Clock: &header.ClockImpl{}}
+	require.Equal(t, http.StatusUnauthorized, send(http.MethodPost, replaceURL, wrongReplace.GenerateHeaders(params)))
+
+	// families without signatures are served as is
+	require.Equal(t, http.StatusOK, send(http.MethodGet, server.URL+"/coupang", nil))
+}
@@ -0,0 +1,50 @@
+package mockvendor
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Scenario controls how a mock vendor answers. The zero value answers 200 with the configured products.
+type Scenario struct {
+	Latency   time.Duration
+	Status    int
+	Empty     bool
+	Malformed bool
+}
+
+// WithQuery overrides the scenario with the mock_latency, mock_status, mock_empty and mock_malformed query
+// parameters, so a single vendor config can pin its own scenario through its request URL
+func (s Scenario) WithQuery(q url.Values) (Scenario, error) {
+	if v := q.Get("mock_latency"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return s, fmt.Errorf("invalid mock_latency: %w", err)
+		}
+		s.Latency = d
+	}
+	if v := q.Get("mock_status"); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil || code < 100 || code > 599 {
+			return s, fmt.Errorf("invalid mock_status: %s", v)
+		}
+		s.Status = code
+	}
+	if v := q.Get("mock_empty"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("invalid mock_empty: %w", err)
+		}
+		s.Empty = b
+	}
+	if v := q.Get("mock_malformed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return s, fmt.Errorf("invalid mock_malformed: %w", err)
+		}
+		s.Malformed = b
+	}
+	return s, nil
+}
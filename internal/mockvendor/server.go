@@ -0,0 +1,192 @@
+package mockvendor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const ScenarioPath = "/_scenario/"
+
+// Config of the mock vendor server. The signatures are only verified when the secrets are set.
+type Config struct {
+	Products         int
+	ProductBaseURL   string
+	KeetaApp         string
+	KeetaSecret      string
+	ReplaceAccessKey string
+	ReplaceSecret    string
+	Default          Scenario
+}
+
+// Server answers /<family>[/...] with the products of the family's response format, shaped by the
+// scenario set for the family through /_scenario/<family> and the mock_* query parameters
+type Server struct {
+	cfg       Config
+	products  []Product
+	mu        sync.RWMutex
+	scenarios map[Family]Scenario
+}
+
+func NewServer(cfg Config) *Server {
+	return &Server{
+		cfg:       cfg,
+		products:  NewProducts(cfg.ProductBaseURL, cfg.Products),
+		scenarios: make(map[Family]Scenario),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, ScenarioPath) || r.URL.Path == strings.TrimSuffix(ScenarioPath, "/") {
+		s.serveScenario(w, r)
+		return
+	}
+
+	head, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if head == "healthz" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+	family, err := ParseFamily(head)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	scenario, err := s.Scenario(family).WithQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.verify(family, r); err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if scenario.Latency > 0 {
+		select {
+		case <-time.After(scenario.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if scenario.Status != 0 && scenario.Status != http.StatusOK {
+		writeError(w, scenario.Status, errors.New("mock vendor error"))
+		return
+	}
+	if scenario.Malformed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data": [{"productId": `))
+		return
+	}
+
+	products := s.products
+	if scenario.Empty {
+		products = nil
+	}
+	body, err := Render(family, products)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// Scenario returns the scenario set for the family, or the default one
+func (s *Server) Scenario(family Family) Scenario {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if scenario, ok := s.scenarios[family]; ok {
+		return scenario
+	}
+	return s.cfg.Default
+}
+
+func (s *Server) SetScenario(family Family, scenario Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios[family] = scenario
+}
+
+func (s *Server) ResetScenario(family Family) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.scenarios, family)
+}
+
+func (s *Server) verify(family Family, r *http.Request) error {
+	switch family {
+	case FamilyKeeta:
+		if s.cfg.KeetaSecret != "" {
+			return VerifyKeeta(r, s.cfg.KeetaApp, s.cfg.KeetaSecret)
+		}
+	case FamilyReplace:
+		if s.cfg.ReplaceSecret != "" {
+			return VerifyReplace(r, s.cfg.ReplaceAccessKey, s.cfg.ReplaceSecret)
+		}
+	}
+	return nil
+}
+
+type scenarioView struct {
+	Latency   string `json:"latency"`
+	Status    int    `json:"status"`
+	Empty     bool   `json:"empty"`
+	Malformed bool   `json:"malformed"`
+}
+
+// serveScenario lists the scenarios on GET /_scenario, sets the scenario of a family from the mock_* query
+// parameters on PUT or POST /_scenario/<family>, and resets it to the default on DELETE /_scenario/<family>
+func (s *Server) serveScenario(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(ScenarioPath, "/")), "/")
+	if name == "" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		views := make(map[Family]scenarioView, len(Families))
+		for _, f := range Families {
+			sc := s.Scenario(f)
+			views[f] = scenarioView{Latency: sc.Latency.String(), Status: sc.Status, Empty: sc.Empty, Malformed: sc.Malformed}
+		}
+		writeJSON(w, http.StatusOK, views)
+		return
+	}
+
+	family, err := ParseFamily(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		scenario, err := s.cfg.Default.WithQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.SetScenario(family, scenario)
+	case http.MethodDelete:
+		s.ResetScenario(family)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]any{"status": code, "detail": err.Error()})
+}
@@ -0,0 +1,79 @@
+package mockvendor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// VerifyKeeta checks the S-Ca-Signature of the Keeta Real-time DPA API: an HMAC-SHA256 over the method,
+// an empty MD5 line, the signed headers in S-Ca-Signature-Headers and the path with query
+func VerifyKeeta(r *http.Request, app, secret string) error {
+	if app != "" && r.Header.Get("S-Ca-App") != app {
+		return fmt.Errorf("%w: unknown S-Ca-App %q", ErrInvalidSignature, r.Header.Get("S-Ca-App"))
+	}
+	signature := r.Header.Get("S-Ca-Signature")
+	signedHeaders := r.Header.Get("S-Ca-Signature-Headers")
+	if signature == "" || signedHeaders == "" {
+		return fmt.Errorf("%w: missing S-Ca-Signature or S-Ca-Signature-Headers", ErrInvalidSignature)
+	}
+
+	keys := strings.Split(signedHeaders, ",")
+	sort.Strings(keys)
+
+	var data strings.Builder
+	data.WriteString(r.Method)
+	data.WriteString("\n\n")
+	for _, k := range keys {
+		data.WriteString(k)
+		data.WriteString(":")
+		data.WriteString(r.Header.Get(k))
+		data.WriteString("\n")
+	}
+	data.WriteString(r.URL.RequestURI())
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data.String()))
+	want := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyReplace checks the CEA Authorization header of the Coupang Partners API: an HMAC-SHA256 over the
+// signed date, method, path and encoded query
+func VerifyReplace(r *http.Request, accessKey, secret string) error {
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "CEA ")
+	if !ok {
+		return fmt.Errorf("%w: missing CEA Authorization header", ErrInvalidSignature)
+	}
+	fields := map[string]string{}
+	for _, part := range strings.Split(auth, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		fields[k] = v
+	}
+	if fields["algorithm"] != "HmacSHA256" {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidSignature, fields["algorithm"])
+	}
+	if accessKey != "" && fields["access-key"] != accessKey {
+		return fmt.Errorf("%w: unknown access key %q", ErrInvalidSignature, fields["access-key"])
+	}
+
+	message := fields["signed-date"] + r.Method + r.URL.Path + r.URL.Query().Encode()
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	want := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(fields["signature"]), []byte(want)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
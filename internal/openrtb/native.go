@@ -0,0 +1,110 @@
+// Package openrtb maps recommended products to OpenRTB Native 1.2 response objects
+package openrtb
+
+const (
+	NativeVersion = "1.2"
+
+	// asset IDs of the native response, shared with the ad server's native request template
+	AssetIDTitle     = 1
+	AssetIDImage     = 2
+	AssetIDPrice     = 3
+	AssetIDSalePrice = 4
+
+	imageTypeMain       = 3
+	dataTypePrice       = 6
+	dataTypeSalePrice   = 7
+	eventTypeImpression = 1
+	eventMethodImage    = 1
+)
+
+type Response struct {
+	Native Native `json:"native"`
+}
+
+type Native struct {
+	Ver           string         `json:"ver"`
+	Assets        []Asset        `json:"assets"`
+	Link          Link           `json:"link"`
+	EventTrackers []EventTracker `json:"eventtrackers,omitempty"`
+}
+
+type Asset struct {
+	ID       int    `json:"id"`
+	Required int    `json:"required,omitempty"`
+	Title    *Title `json:"title,omitempty"`
+	Img      *Image `json:"img,omitempty"`
+	Data     *Data  `json:"data,omitempty"`
+}
+
+type Title struct {
+	Text string `json:"text"`
+}
+
+type Image struct {
+	Type int    `json:"type"`
+	URL  string `json:"url"`
+	W    int    `json:"w,omitempty"`
+	H    int    `json:"h,omitempty"`
+}
+
+type Data struct {
+	Type  int    `json:"type"`
+	Value string `json:"value"`
+}
+
+type Link struct {
+	URL string `json:"url"`
+}
+
+type EventTracker struct {
+	Event  int    `json:"event"`
+	Method int    `json:"method"`
+	URL    string `json:"url"`
+}
+
+// Product is the part of a recommended product rendered in a native response, its impression URL
+// is added to the configured impression trackers if set
+type Product struct {
+	Title         string
+	ImageURL      string
+	Price         string
+	SalePrice     string
+	ClickURL      string
+	ImpressionURL string
+}
+
+// FromProducts builds one native response per product. The image asset carries the requested size,
+// and the impression trackers are added to every response. Empty optional assets are omitted.
+func FromProducts(products []Product, w, h int, impTrackers []string) []Response {
+	responses := make([]Response, 0, len(products))
+	for _, p := range products {
+		assets := []Asset{{ID: AssetIDImage, Required: 1, Img: &Image{Type: imageTypeMain, URL: p.ImageURL, W: w, H: h}}}
+		if p.Title != "" {
+			assets = append(assets, Asset{ID: AssetIDTitle, Title: &Title{Text: p.Title}})
+		}
+		if p.Price != "" {
+			assets = append(assets, Asset{ID: AssetIDPrice, Data: &Data{Type: dataTypePrice, Value: p.Price}})
+		}
+		if p.SalePrice != "" {
+			assets = append(assets, Asset{ID: AssetIDSalePrice, Data: &Data{Type: dataTypeSalePrice, Value: p.SalePrice}})
+		}
+
+		trackers := make([]EventTracker, 0, len(impTrackers)+1)
+		for _, tracker := range impTrackers {
+			trackers = append(trackers, EventTracker{Event: eventTypeImpression, Method: eventMethodImage, URL: tracker})
+		}
+		if p.ImpressionURL != "" {
+			trackers = append(trackers, EventTracker{Event: eventTypeImpression, Method: eventMethodImage, URL: p.ImpressionURL})
+		}
+
+		responses = append(responses, Response{
+			Native: Native{
+				Ver:           NativeVersion,
+				Assets:        assets,
+				Link:          Link{URL: p.ClickURL},
+				EventTrackers: trackers,
+			},
+		})
+	}
+	return responses
+}
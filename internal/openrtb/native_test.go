@@ -0,0 +1,56 @@
+package openrtb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromProducts(t *testing.T) {
+	tests := []struct {
+		name        string
+		products    []Product
+		impTrackers []string
+		want        []Response
+	}{
+		{
+			name:        "GIVEN a complete product THEN expect all assets and trackers",
+			products:    []Product{{Title: "shoe", ImageURL: "https://img/1", Price: "100", SalePrice: "80", ClickURL: "https://click/1", ImpressionURL: "https://rec/i/1"}},
+			impTrackers: []string{"https://imp/1", "https://imp/2"},
+			want: []Response{{Native: Native{
+				Ver: NativeVersion,
+				Assets: []Asset{
+					{ID: AssetIDImage, Required: 1, Img: &Image{Type: imageTypeMain, URL: "https://img/1", W: 300, H: 250}},
+					{ID: AssetIDTitle, Title: &Title{Text: "shoe"}},
+					{ID: AssetIDPrice, Data: &Data{Type: dataTypePrice, Value: "100"}},
+					{ID: AssetIDSalePrice, Data: &Data{Type: dataTypeSalePrice, Value: "80"}},
+				},
+				Link: Link{URL: "https://click/1"},
+				EventTrackers: []EventTracker{
+					{Event: eventTypeImpression, Method: eventMethodImage, URL: "https://imp/1"},
+					{Event: eventTypeImpression, Method: eventMethodImage, URL: "https://imp/2"},
+					{Event: eventTypeImpression, Method: eventMethodImage, URL: "https://rec/i/1"},
+				},
+			}}},
+		},
+		{
+			name:     "GIVEN a product without optional fields THEN expect only the image asset",
+			products: []Product{{ImageURL: "https://img/1", ClickURL: "https://click/1"}},
+			want: []Response{{Native: Native{
+				Ver:           NativeVersion,
+				Assets:        []Asset{{ID: AssetIDImage, Required: 1, Img: &Image{Type: imageTypeMain, URL: "https://img/1", W: 300, H: 250}}},
+				Link:          Link{URL: "https://click/1"},
+				EventTrackers: []EventTracker{},
+			}}},
+		},
+		{
+			name: "GIVEN no products THEN expect no responses",
+			want: []Response{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, FromProducts(tt.products, 300, 250, tt.impTrackers))
+		})
+	}
+}
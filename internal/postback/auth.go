@@ -0,0 +1,102 @@
+package postback
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+const DefaultSecretParam = "secret"
+
+// Authenticator checks the shared secret and the client IP of a postback, each check only applies if configured
+type Authenticator struct {
+	secret      string
+	secretParam string
+	allowedIPs  []netip.Prefix
+}
+
+// NewAuthenticator requires a secret or an IP allowlist, the allowlist entries are IPs or CIDRs
+func NewAuthenticator(secret, secretParam string, allowedIPs []string) (*Authenticator, error) {
+	if secret == "" && len(allowedIPs) == 0 {
+		return nil, errors.New("postback requires a secret or allowed IPs")
+	}
+	if secretParam == "" {
+		secretParam = DefaultSecretParam
+	}
+
+	prefixes, err := ParsePrefixes(allowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	return &Authenticator{secret: secret, secretParam: secretParam, allowedIPs: prefixes}, nil
+}
+
+// ParsePrefixes parses a list of IPs or CIDRs
+func ParsePrefixes(ips []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(ips))
+	for _, ip := range ips {
+		if !strings.Contains(ip, "/") {
+			addr, err := netip.ParseAddr(ip)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP %s: %w", ip, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(ip)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", ip, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ClientIP returns the IP of the peer, or when the peer is a trusted proxy, the last X-Forwarded-For address
+// that is not a trusted proxy. The other addresses are set by the client and cannot be trusted.
+func ClientIP(remoteAddr, forwardedFor string, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	if forwardedFor == "" || !containsIP(trustedProxies, host) {
+		return host
+	}
+	forwarded := strings.Split(forwardedFor, ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if !containsIP(trustedProxies, ip) || i == 0 {
+			return ip
+		}
+	}
+	return host
+}
+
+func containsIP(prefixes []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Authenticator) Allow(values url.Values, clientIP string) bool {
+	if a.secret != "" && subtle.ConstantTimeCompare([]byte(values.Get(a.secretParam)), []byte(a.secret)) != 1 {
+		return false
+	}
+	if len(a.allowedIPs) == 0 {
+		return true
+	}
+
+	return containsIP(a.allowedIPs, clientIP)
+}
@@ -0,0 +1,88 @@
+// Package postback parses and authenticates the conversion postbacks sent by vendors
+package postback
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/strategy/utils"
+)
+
+const (
+	ClickIDEncodingBase64 = "base64"
+	ClickIDEncodingRaw    = "raw"
+	ClickIDEncodingSealed = "sealed"
+)
+
+// Mapping names the postback parameters of a vendor, revenue and currency are optional
+type Mapping struct {
+	ClickIDParam    string
+	ClickIDEncoding string
+	OrderIDParam    string
+	RevenueParam    string
+	CurrencyParam   string
+	// Sealer opens the click IDs of the sealed encoding
+	Sealer *clickseal.Sealer
+}
+
+type Conversion struct {
+	Vendor   string
+	ClickID  string
+	OrderID  string
+	Revenue  float64
+	Currency string
+}
+
+// Parse reads a conversion from the postback parameters. The click ID is decoded from the
+// {click_id_base64} macro, or opened from the {click_id_sealed} macro, unless the encoding is raw.
+func Parse(vendor string, m Mapping, values url.Values) (Conversion, error) {
+	c := Conversion{
+		Vendor:   vendor,
+		OrderID:  values.Get(m.OrderIDParam),
+		Currency: values.Get(m.CurrencyParam),
+	}
+	if c.OrderID == "" {
+		return Conversion{}, fmt.Errorf("missing order id parameter %s", m.OrderIDParam)
+	}
+
+	clickID := values.Get(m.ClickIDParam)
+	if clickID == "" {
+		return Conversion{}, fmt.Errorf("missing click id parameter %s", m.ClickIDParam)
+	}
+	decoded, err := decodeClickID(clickID, m)
+	if err != nil {
+		return Conversion{}, fmt.Errorf("invalid click id %s: %w", clickID, err)
+	}
+	c.ClickID = decoded
+
+	if revenue := values.Get(m.RevenueParam); m.RevenueParam != "" && revenue != "" {
+		parsed, err := strconv.ParseFloat(revenue, 64)
+		if err != nil {
+			return Conversion{}, fmt.Errorf("invalid revenue %s: %w", revenue, err)
+		}
+		// the revenue is added to a counter, which cannot decrease nor recover from NaN or Inf
+		if parsed < 0 || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return Conversion{}, fmt.Errorf("invalid revenue %s: must be a finite non-negative number", revenue)
+		}
+		c.Revenue = parsed
+	}
+	return c, nil
+}
+
+func decodeClickID(clickID string, m Mapping) (string, error) {
+	switch m.ClickIDEncoding {
+	case ClickIDEncodingRaw:
+		return clickID, nil
+	case ClickIDEncodingSealed:
+		if m.Sealer == nil {
+			return "", errors.New("sealed click id without click seal_keys")
+		}
+		return m.Sealer.Open(clickID)
+	default:
+		return utils.DecodeClickID(clickID)
+	}
+}
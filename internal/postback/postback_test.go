@@ -0,0 +1,195 @@
+package postback
+
+import (
+	"net/url"
+	"testing"
+
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/strategy/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	mapping := Mapping{ClickIDParam: "sub1", OrderIDParam: "order", RevenueParam: "amount", CurrencyParam: "cur"}
+	sealer, err := clickseal.NewSealer([]clickseal.Key{{ID: "k1", Key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}})
+	require.NoError(t, err)
+	sealed, err := sealer.Seal("click-1")
+	require.NoError(t, err)
+	sealedMapping := Mapping{ClickIDParam: "sub1", ClickIDEncoding: ClickIDEncodingSealed, OrderIDParam: "order", Sealer: sealer}
+
+	tt := []struct {
+		name    string
+		mapping Mapping
+		values  url.Values
+		want    Conversion
+		wantErr string
+	}{
+		{
+			name:    "GIVEN a complete postback THEN expect the decoded conversion",
+			mapping: mapping,
+			values:  url.Values{"sub1": {utils.EncodeClickID("click-1")}, "order": {"o1"}, "amount": {"12.5"}, "cur": {"KRW"}},
+			want:    Conversion{Vendor: "linkmine", ClickID: "click-1", OrderID: "o1", Revenue: 12.5, Currency: "KRW"},
+		},
+		{
+			name:    "GIVEN a raw click ID encoding THEN expect the click ID as is",
+			mapping: Mapping{ClickIDParam: "sub1", ClickIDEncoding: ClickIDEncodingRaw, OrderIDParam: "order"},
+			values:  url.Values{"sub1": {"click-1"}, "order": {"o1"}},
+			want:    Conversion{Vendor: "linkmine", ClickID: "click-1", OrderID: "o1"},
+		},
+		{
+			name:    "GIVEN a sealed click ID encoding THEN expect the opened click ID",
+			mapping: sealedMapping,
+			values:  url.Values{"sub1": {sealed}, "order": {"o1"}},
+			want:    Conversion{Vendor: "linkmine", ClickID: "click-1", OrderID: "o1"},
+		},
+		{
+			name:    "GIVEN a forged sealed click ID THEN expect error",
+			mapping: sealedMapping,
+			values:  url.Values{"sub1": {"k1." + utils.EncodeClickID("click-1-forged-click-id")}, "order": {"o1"}},
+			wantErr: "invalid click id k1.Y2xpY2stMS1mb3JnZWQtY2xpY2staWQ: invalid sealed click id",
+		},
+		{
+			name:    "GIVEN no order id THEN expect error",
+			mapping: mapping,
+			values:  url.Values{"sub1": {utils.EncodeClickID("click-1")}},
+			wantErr: "missing order id parameter order",
+		},
+		{
+			name:    "GIVEN no click id THEN expect error",
+			mapping: mapping,
+			values:  url.Values{"order": {"o1"}},
+			wantErr: "missing click id parameter sub1",
+		},
+		{
+			name:    "GIVEN an invalid revenue THEN expect error",
+			mapping: mapping,
+			values:  url.Values{"sub1": {utils.EncodeClickID("click-1")}, "order": {"o1"}, "amount": {"abc"}},
+			wantErr: `invalid revenue abc: strconv.ParseFloat: parsing "abc": invalid syntax`,
+		},
+		{
+			name:    "GIVEN a negative revenue THEN expect error",
+			mapping: mapping,
+			values:  url.Values{"sub1": {utils.EncodeClickID("click-1")}, "order": {"o1"}, "amount": {"-5"}},
+			wantErr: "invalid revenue -5: must be a finite non-negative number",
+		},
+		{
+			name:    "GIVEN a NaN revenue THEN expect error",
+			mapping: mapping,
+			values:  url.Values{"sub1": {utils.EncodeClickID("click-1")}, "order": {"o1"}, "amount": {"NaN"}},
+			wantErr: "invalid revenue NaN: must be a finite non-negative number",
+		},
+		{
+			name:    "GIVEN an infinite revenue THEN expect error",
+			mapping: mapping,
+			values:  url.Values{"sub1": {utils.EncodeClickID("click-1")}, "order": {"o1"}, "amount": {"+Inf"}},
+			wantErr: "invalid revenue +Inf: must be a finite non-negative number",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse("linkmine", tc.mapping, tc.values)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	tt := []struct {
+		name       string
+		secret     string
+		allowedIPs []string
+		values     url.Values
+		clientIP   string
+		want       bool
+	}{
+		{
+			name:   "GIVEN a matching secret THEN expect allowed",
+			secret: "s3cret",
+			values: url.Values{"secret": {"s3cret"}},
+			want:   true,
+		},
+		{
+			name:   "GIVEN a wrong secret THEN expect denied",
+			secret: "s3cret",
+			values: url.Values{"secret": {"wrong"}},
+		},
+		{
+			name:       "GIVEN a client IP in an allowed CIDR THEN expect allowed",
+			allowedIPs: []string{"10.0.0.0/8", "192.168.1.1"},
+			clientIP:   "10.1.2.3",
+			want:       true,
+		},
+		{
+			name:       "GIVEN a client IP not allowed THEN expect denied",
+			allowedIPs: []string{"10.0.0.0/8", "192.168.1.1"},
+			clientIP:   "192.168.1.2",
+		},
+		{
+			name:       "GIVEN both checks and a wrong IP THEN expect denied",
+			secret:     "s3cret",
+			allowedIPs: []string{"192.168.1.1"},
+			values:     url.Values{"secret": {"s3cret"}},
+			clientIP:   "192.168.1.2",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := NewAuthenticator(tc.secret, "", tc.allowedIPs)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, a.Allow(tc.values, tc.clientIP))
+		})
+	}
+
+	_, err := NewAuthenticator("", "", nil)
+	require.EqualError(t, err, "postback requires a secret or allowed IPs")
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParsePrefixes([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		want         string
+	}{
+		{
+			name:       "GIVEN no X-Forwarded-For THEN expect the peer IP",
+			remoteAddr: "203.0.113.9:5555",
+			want:       "203.0.113.9",
+		},
+		{
+			name:         "GIVEN an X-Forwarded-For from an untrusted peer THEN expect the peer IP",
+			remoteAddr:   "203.0.113.9:5555",
+			forwardedFor: "10.1.2.3",
+			want:         "203.0.113.9",
+		},
+		{
+			name:         "GIVEN an X-Forwarded-For from a trusted proxy THEN expect the last untrusted address",
+			remoteAddr:   "10.0.0.1:5555",
+			forwardedFor: "10.9.9.9, 198.51.100.7, 192.168.1.1",
+			want:         "198.51.100.7",
+		},
+		{
+			name:         "GIVEN only trusted addresses THEN expect the first address",
+			remoteAddr:   "10.0.0.1:5555",
+			forwardedFor: "10.2.2.2, 10.3.3.3",
+			want:         "10.2.2.2",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, ClientIP(tc.remoteAddr, tc.forwardedFor, trusted))
+		})
+	}
+}
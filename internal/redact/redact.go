@@ -0,0 +1,250 @@
+// Package redact masks the user identifiers and secrets in the logs: query params, headers, JSON fields and the
+// vendor secrets, and truncates the bodies
+package redact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/telemetry"
+)
+
+const (
+	Mask = "****"
+
+	DefaultMaxBodySize = 1024
+
+	// MaxDebugWindow is the longest a vendor can be logged unredacted, from the service start
+	MaxDebugWindow = 24 * time.Hour
+)
+
+var (
+	// DefaultQueryParams are the user identifiers of /r/:vendor_key and of the vendor requests
+	DefaultQueryParams = []string{"user_id", "click_id", "adid", "idfa", "gaid", "device_id", "token", "secret"}
+	DefaultHeaders     = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "S-Ca-Signature"}
+	DefaultJSONFields  = []string{"user_id", "click_id", "adid", "idfa", "gaid", "device_id"}
+)
+
+var defaultRedactor atomic.Pointer[Redactor]
+
+func init() {
+	// the defaults never fail
+	r, _ := New(config.RedactionConfig{}, nil, time.Now)
+	SetDefault(r)
+}
+
+// SetDefault sets the redactor of the log format and of the package functions
+func SetDefault(r *Redactor) {
+	defaultRedactor.Store(r)
+}
+
+func Default() *Redactor {
+	return defaultRedactor.Load()
+}
+
+type Redactor struct {
+	queryParams map[string]bool
+	headers     map[string]bool
+	jsonFields  map[string]bool
+	// paramRegExp matches the query params in a text, such as a log message with a request URI
+	paramRegExp *regexp.Regexp
+	// fieldRegExp matches the JSON fields in a body that is not valid JSON
+	fieldRegExp *regexp.Regexp
+	secrets     []string
+	maxBodySize int
+	debug       map[string]time.Time
+	now         func() time.Time
+}
+
+// New returns the redactor of the config, with the secrets of the vendors. A debug override ending more than
+// MaxDebugWindow from now is refused, so that a forgotten override does not disable the redaction for good.
+func New(cfg config.RedactionConfig, vendors []config.Vendor, now func() time.Time) (*Redactor, error) {
+	r := &Redactor{
+		queryParams: set(append(DefaultQueryParams, cfg.QueryParams...), strings.ToLower),
+		headers:     set(append(DefaultHeaders, cfg.Headers...), http.CanonicalHeaderKey),
+		jsonFields:  set(append(DefaultJSONFields, cfg.JSONFields...), strings.ToLower),
+		maxBodySize: cfg.MaxBodySize,
+		debug:       map[string]time.Time{},
+		now:         now,
+	}
+	if r.maxBodySize == 0 {
+		r.maxBodySize = DefaultMaxBodySize
+	}
+
+	r.paramRegExp = regexp.MustCompile(`(?i)([?&\s]|^)(` + alternation(r.queryParams) + `)=[^&\s"']*`)
+	r.fieldRegExp = regexp.MustCompile(`(?i)"(` + alternation(r.jsonFields) + `)"\s*:\s*("(?:[^"\\]|\\.)*"|[^,}\]\s]*)`)
+
+	for _, v := range vendors {
+		r.secrets = append(r.secrets, v.SecretValues()...)
+	}
+
+	for _, d := range cfg.Debug {
+		until, err := time.Parse(time.RFC3339, d.Until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction debug time of %s: %w", d.Vendor, err)
+		}
+		if until.Sub(now()) > MaxDebugWindow {
+			return nil, fmt.Errorf("redaction debug of %s ends at %s, more than %s from now", d.Vendor, d.Until, MaxDebugWindow)
+		}
+		r.debug[d.Vendor] = until
+	}
+	return r, nil
+}
+
+func alternation(values map[string]bool) string {
+	quoted := make([]string, 0, len(values))
+	for v := range values {
+		quoted = append(quoted, regexp.QuoteMeta(v))
+	}
+	sort.Strings(quoted)
+	return strings.Join(quoted, "|")
+}
+
+func set(values []string, normalize func(string) string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[normalize(v)] = true
+	}
+	return m
+}
+
+// Debug reports whether the vendor is logged unredacted, i.e. its debug override is not over
+func (r *Redactor) Debug(vendor string) bool {
+	until, ok := r.debug[vendor]
+	return ok && r.now().Before(until)
+}
+
+// DebugContext reports whether the vendor of the request is logged unredacted
+func (r *Redactor) DebugContext(ctx context.Context) bool {
+	return r.Debug(telemetry.RequestInfoFromContext(ctx).VendorKey)
+}
+
+// Text masks the secrets and the query params of a free text, such as a log message
+func (r *Redactor) Text(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	return r.paramRegExp.ReplaceAllString(s, "${1}${2}="+Mask)
+}
+
+// URL masks the query params of a URL or a request URI, keeping the order of the params
+func (r *Redactor) URL(raw string) string {
+	base, rawQuery, ok := strings.Cut(raw, "?")
+	if !ok {
+		return r.Text(raw)
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil && r.queryParams[strings.ToLower(unescaped)] {
+			params[i] = key + "=" + Mask
+		}
+	}
+	return r.Text(base + "?" + strings.Join(params, "&"))
+}
+
+// Header returns a copy of the header with the values masked
+func (r *Redactor) Header(h http.Header) http.Header {
+	masked := h.Clone()
+	for key := range masked {
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			masked[key] = []string{Mask}
+		}
+	}
+	return masked
+}
+
+// Body masks the JSON fields of a JSON body, or the query params of another body, and truncates it
+func (r *Redactor) Body(b []byte) string {
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	s := r.fieldRegExp.ReplaceAllString(string(b), `"${1}":"`+Mask+`"`)
+	if err := decoder.Decode(&doc); err == nil {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		// keep the & of the product URLs readable
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(r.maskFields(doc)); err == nil {
+			s = strings.TrimSuffix(buf.String(), "\n")
+		}
+	}
+	return r.truncate(r.Text(s))
+}
+
+func (r *Redactor) maskFields(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if r.jsonFields[strings.ToLower(key)] {
+				v[key] = Mask
+				continue
+			}
+			v[key] = r.maskFields(value)
+		}
+	case []any:
+		for i := range v {
+			v[i] = r.maskFields(v[i])
+		}
+	}
+	return v
+}
+
+func (r *Redactor) truncate(s string) string {
+	if len(s) <= r.maxBodySize {
+		return s
+	}
+	// cut at a rune boundary
+	cut := r.maxBodySize
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(%d bytes)", s[:cut], len(s))
+}
+
+// Field masks the value of a log field: by its key for the query params and JSON fields, and the secrets of
+// the strings and errors
+func (r *Redactor) Field(key string, value any) any {
+	lower := strings.ToLower(key)
+	if r.queryParams[lower] || r.jsonFields[lower] {
+		return Mask
+	}
+	switch v := value.(type) {
+	case string:
+		return r.Text(v)
+	case error:
+		return r.Text(v.Error())
+	case http.Header:
+		return r.Header(v)
+	}
+	return value
+}
+
+// Body masks and truncates the body with the default redactor, unless the vendor of the request is debugged
+func Body(ctx context.Context, b []byte) string {
+	r := Default()
+	if r.DebugContext(ctx) {
+		return string(b)
+	}
+	return r.Body(b)
+}
+
+// URL masks the URL with the default redactor, unless the vendor of the request is debugged
+func URL(ctx context.Context, raw string) string {
+	r := Default()
+	if r.DebugContext(ctx) {
+		return raw
+	}
+	return r.URL(raw)
+}
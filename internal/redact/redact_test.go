@@ -0,0 +1,132 @@
+package redact
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/telemetry"
+
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newTestRedactor(t *testing.T, cfg config.RedactionConfig) *Redactor {
+	t.Helper()
+	vendors := []config.Vendor{{Name: "keeta", SCaSecret: "keeta-secret"}}
+	r, err := New(cfg, vendors, func() time.Time { return now })
+	require.NoError(t, err)
+	return r
+}
+
+func TestURL(t *testing.T) {
+	r := newTestRedactor(t, config.RedactionConfig{QueryParams: []string{"Email"}})
+
+	tt := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "GIVEN user identifiers THEN expect them masked in order",
+			url:  "/r/keeta?user_id=38400000-8cf0-11bd-b23e-10b96e40000d&subid=s1&click_id=c1&email=a%40b.c",
+			want: "/r/keeta?user_id=****&subid=s1&click_id=****&email=****",
+		},
+		{
+			name: "GIVEN a vendor secret THEN expect it masked",
+			url:  "https://keeta.com/reco?sig=keeta-secret",
+			want: "https://keeta.com/reco?sig=****",
+		},
+		{
+			name: "GIVEN no query THEN expect the URL unchanged",
+			url:  "/c/token",
+			want: "/c/token",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, r.URL(tc.url))
+		})
+	}
+}
+
+func TestBody(t *testing.T) {
+	r := newTestRedactor(t, config.RedactionConfig{JSONFields: []string{"email"}, MaxBodySize: 81})
+
+	tt := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "GIVEN JSON fields THEN expect them masked at any depth",
+			body: `{"data": [{"adid": "a1", "url": "https://shop/1?a=1&b=2"}], "Email": "x@y.z"}`,
+			want: `{"Email":"****","data":[{"adid":"****","url":"https://shop/1?a=1&b=2"}]}`,
+		},
+		{
+			name: "GIVEN invalid JSON THEN expect the fields masked in the text",
+			body: `{"user_id": "u1", "price": 1`,
+			want: `{"user_id":"****", "price": 1`,
+		},
+		{
+			name: "GIVEN a body over the max size THEN expect it truncated",
+			body: `<html>` + strings.Repeat("é", 40) + `</html>`,
+			want: `<html>` + strings.Repeat("é", 37) + `...(93 bytes)`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, r.Body([]byte(tc.body)))
+		})
+	}
+}
+
+func TestHeaderAndField(t *testing.T) {
+	r := newTestRedactor(t, config.RedactionConfig{Headers: []string{"x-vendor-token"}})
+
+	h := http.Header{"Authorization": {"Bearer t"}, "X-Vendor-Token": {"v"}, "Accept": {"application/json"}}
+	require.Equal(t, http.Header{"Authorization": {Mask}, "X-Vendor-Token": {Mask}, "Accept": {"application/json"}}, r.Header(h))
+	require.Equal(t, "Bearer t", h.Get("Authorization"))
+
+	require.Equal(t, Mask, r.Field("user_id", "u1"))
+	require.Equal(t, "Get \"https://keeta.com/reco?adid=****\": timeout", r.Field("error", errors.New(`Get "https://keeta.com/reco?adid=a1": timeout`)))
+	require.Equal(t, 3, r.Field("count", 3))
+}
+
+func TestDebug(t *testing.T) {
+	r := newTestRedactor(t, config.RedactionConfig{Debug: []config.RedactionDebug{{Vendor: "keeta", Until: "2026-10-19T14:00:00Z"}}})
+	require.True(t, r.Debug("keeta"))
+	require.False(t, r.Debug("replace"))
+
+	ctx := telemetry.RequestInfoToContext(context.Background(), telemetry.RequestInfo{VendorKey: "keeta"})
+	require.True(t, r.DebugContext(ctx))
+
+	r.now = func() time.Time { return now.Add(3 * time.Hour) }
+	require.False(t, r.Debug("keeta"))
+
+	_, err := New(config.RedactionConfig{Debug: []config.RedactionDebug{{Vendor: "keeta", Until: "2026-10-21T12:00:00Z"}}}, nil, func() time.Time { return now })
+	require.Error(t, err)
+}
+
+func TestPackageFunctions(t *testing.T) {
+	r := newTestRedactor(t, config.RedactionConfig{Debug: []config.RedactionDebug{{Vendor: "keeta", Until: "2026-10-19T14:00:00Z"}}})
+	SetDefault(r)
+	t.Cleanup(func() {
+		r, _ := New(config.RedactionConfig{}, nil, time.Now)
+		SetDefault(r)
+	})
+
+	keeta := telemetry.RequestInfoToContext(context.Background(), telemetry.RequestInfo{VendorKey: "keeta"})
+	replace := telemetry.RequestInfoToContext(context.Background(), telemetry.RequestInfo{VendorKey: "replace"})
+	require.Equal(t, "/r/keeta?user_id=u1", URL(keeta, "/r/keeta?user_id=u1"))
+	require.Equal(t, "/r/replace?user_id=****", URL(replace, "/r/replace?user_id=u1"))
+	require.Equal(t, `{"adid": "a1"}`, Body(keeta, []byte(`{"adid": "a1"}`)))
+	require.Equal(t, `{"adid":"****"}`, Body(replace, []byte(`{"adid": "a1"}`)))
+}
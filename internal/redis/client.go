@@ -0,0 +1,254 @@
+// Package redis is a minimal RESP client for the stores shared between the pods, it only supports the
+// commands and replies they use
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPoolSize = 16
+	defaultTimeout  = 100 * time.Millisecond
+)
+
+// Error is an error reply of the server
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+var errClosed = errors.New("redis: client closed")
+
+type Config struct {
+	Addr     string
+	Password string
+	DB       int
+	// PoolSize is the number of idle connections kept open
+	PoolSize int
+	// Timeout bounds the dial and each round trip, it is shortened by the context deadline
+	Timeout time.Duration
+}
+
+// Client sends pipelines of commands over a pool of connections
+type Client struct {
+	cfg       Config
+	idle      chan *conn
+	mu        sync.Mutex
+	closed    bool
+	closeOnce sync.Once
+}
+
+func NewClient(cfg Config) *Client {
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = defaultPoolSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Client{cfg: cfg, idle: make(chan *conn, cfg.PoolSize)}
+}
+
+// Do sends the commands in a single round trip and returns their replies, each one is nil, a string,
+// an int64 or a []any. An error reply to any of the commands is returned as an Error.
+func (c *Client) Do(ctx context.Context, cmds ...[]string) ([]any, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := cn.do(c.deadline(ctx), cmds)
+	if err != nil {
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+
+	for _, reply := range replies {
+		if e, ok := reply.(Error); ok {
+			return nil, e
+		}
+	}
+	return replies, nil
+}
+
+// Close closes the idle connections, the connections in use are closed when they are released
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		c.mu.Unlock()
+		for {
+			select {
+			case cn := <-c.idle:
+				cn.Close()
+			default:
+				return
+			}
+		}
+	})
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, errClosed
+	}
+
+	dialer := net.Dialer{Timeout: c.cfg.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: netConn, r: bufio.NewReader(netConn), w: bufio.NewWriter(netConn)}
+
+	var setup [][]string
+	if c.cfg.Password != "" {
+		setup = append(setup, []string{"AUTH", c.cfg.Password})
+	}
+	if c.cfg.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.cfg.DB)})
+	}
+	if len(setup) > 0 {
+		replies, err := cn.do(c.deadline(ctx), setup)
+		if err == nil {
+			for _, reply := range replies {
+				if e, ok := reply.(Error); ok {
+					err = e
+				}
+			}
+		}
+		if err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		cn.Close()
+		return
+	}
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+func (c *Client) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(c.cfg.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func (cn *conn) do(deadline time.Time, cmds [][]string) ([]any, error) {
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	for _, cmd := range cmds {
+		writeCommand(cn.w, cmd)
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]any, 0, len(cmds))
+	for range cmds {
+		reply, err := readReply(cn.r)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+func writeCommand(w *bufio.Writer, cmd []string) {
+	fmt.Fprintf(w, "*%d\r\n", len(cmd))
+	for _, arg := range cmd {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, 0, n)
+		for range n {
+			item, err := readReply(r)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed reply %q", line)
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/redis/redistest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientDo(t *testing.T) {
+	server := redistest.NewServer(t, "pass")
+	client := NewClient(Config{Addr: server.Addr(), Password: "pass", DB: 1})
+	defer client.Close()
+	ctx := context.Background()
+
+	replies, err := client.Do(ctx,
+		[]string{"SET", "k", "0", "PX", "60000", "NX"},
+		[]string{"INCR", "k"},
+		[]string{"SET", "k", "0", "PX", "60000", "NX"},
+		[]string{"MGET", "k", "missing"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []any{"OK", int64(1), nil, []any{"1", nil}}, replies)
+	require.Equal(t, time.Minute, server.TTL("k"))
+
+	// the connection is reused after an error reply
+	_, err = client.Do(ctx, []string{"UNKNOWN"})
+	require.ErrorContains(t, err, "unknown command")
+	replies, err = client.Do(ctx, []string{"GET", "k"})
+	require.NoError(t, err)
+	require.Equal(t, []any{"1"}, replies)
+}
+
+func TestClientAuth(t *testing.T) {
+	server := redistest.NewServer(t, "pass")
+	client := NewClient(Config{Addr: server.Addr(), Password: "wrong"})
+	defer client.Close()
+
+	_, err := client.Do(context.Background(), []string{"PING"})
+	require.ErrorContains(t, err, "WRONGPASS")
+}
+
+func TestClientClose(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	client := NewClient(Config{Addr: server.Addr()})
+	_, err := client.Do(context.Background(), []string{"PING"})
+	require.NoError(t, err)
+
+	client.Close()
+	client.Close()
+	_, err = client.Do(context.Background(), []string{"PING"})
+	require.Error(t, err)
+}
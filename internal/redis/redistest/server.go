@@ -0,0 +1,236 @@
+// Package redistest is an in-memory redis server for the tests of the stores shared between the pods,
+// it implements the commands they use
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type value struct {
+	data      string
+	expiresAt time.Time
+}
+
+type Server struct {
+	listener net.Listener
+	password string
+	mu       sync.Mutex
+	values   map[string]value
+	now      func() time.Time
+}
+
+// NewServer listens on a random local port until the end of the test, clients must AUTH with the
+// password if it is set. Its clock is stopped, see Advance.
+func NewServer(t *testing.T, password string) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	s := &Server{listener: listener, password: password, values: map[string]value{}, now: func() time.Time { return start }}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Advance moves the clock of the expirations forward
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.now = func() time.Time { return now.Add(d) }
+}
+
+// TTL returns the remaining time to live of the key, 0 if it does not expire or does not exist
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.get(key)
+	if !ok || v.expiresAt.IsZero() {
+		return 0
+	}
+	return v.expiresAt.Sub(s.now())
+}
+
+func (s *Server) serve() {
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	authed := s.password == ""
+	for {
+		cmd, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if len(cmd) == 0 {
+			continue
+		}
+		name := strings.ToUpper(cmd[0])
+		switch {
+		case name == "AUTH":
+			authed = len(cmd) == 2 && cmd[1] == s.password
+			if !authed {
+				w.WriteString("-WRONGPASS invalid password\r\n")
+				break
+			}
+			w.WriteString("+OK\r\n")
+		case !authed:
+			w.WriteString("-NOAUTH Authentication required.\r\n")
+		default:
+			s.exec(w, name, cmd[1:])
+		}
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) exec(w *bufio.Writer, name string, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "SELECT":
+		w.WriteString("+OK\r\n")
+	case "GET":
+		v, ok := s.get(args[0])
+		writeBulk(w, v.data, ok)
+	case "MGET":
+		fmt.Fprintf(w, "*%d\r\n", len(args))
+		for _, key := range args {
+			v, ok := s.get(key)
+			writeBulk(w, v.data, ok)
+		}
+	case "SET":
+		s.set(w, args)
+	case "INCR":
+		v, _ := s.get(args[0])
+		n, err := strconv.ParseInt(v.data, 10, 64)
+		if v.data != "" && err != nil {
+			w.WriteString("-ERR value is not an integer or out of range\r\n")
+			return
+		}
+		n++
+		v.data = strconv.FormatInt(n, 10)
+		s.values[args[0]] = v
+		fmt.Fprintf(w, ":%d\r\n", n)
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.get(key); ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	default:
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", name)
+	}
+}
+
+// set supports the NX and PX options
+func (s *Server) set(w *bufio.Writer, args []string) {
+	key, data := args[0], args[1]
+	nx := false
+	var expiresAt time.Time
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "PX":
+			i++
+			ms, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil || ms <= 0 {
+				w.WriteString("-ERR invalid expire time in 'set' command\r\n")
+				return
+			}
+			expiresAt = s.now().Add(time.Duration(ms) * time.Millisecond)
+		default:
+			w.WriteString("-ERR syntax error\r\n")
+			return
+		}
+	}
+	if _, ok := s.get(key); ok && nx {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	s.values[key] = value{data: data, expiresAt: expiresAt}
+	w.WriteString("+OK\r\n")
+}
+
+// get must be called with the lock held, it removes the expired key
+func (s *Server) get(key string) (value, bool) {
+	v, ok := s.values[key]
+	if ok && !v.expiresAt.IsZero() && !s.now().Before(v.expiresAt) {
+		delete(s.values, key)
+		return value{}, false
+	}
+	return v, ok
+}
+
+func writeBulk(w *bufio.Writer, data string, ok bool) {
+	if !ok {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(data), data)
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	cmd := make([]string, 0, n)
+	for range n {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSuffix(header, "\r\n")[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		cmd = append(cmd, string(buf[:size]))
+	}
+	return cmd, nil
+}
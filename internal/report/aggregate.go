@@ -0,0 +1,182 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"rec-vendor-api/internal/event"
+)
+
+const (
+	DimensionVendor = "vendor"
+	DimensionSite   = "site"
+	DimensionHour   = "hour"
+
+	outcomeOK = "ok"
+)
+
+var Dimensions = []string{DimensionVendor, DimensionSite, DimensionHour}
+
+// Row holds the statistics of one group of events, the request statistics are of the serve events
+type Row struct {
+	Keys        []string
+	Requests    int
+	Filled      int
+	FillRate    float64
+	P50Ms       float64
+	P95Ms       float64
+	P99Ms       float64
+	AvgItems    float64
+	Impressions int
+	Clicks      int
+	Conversions int
+	// Revenue sums the conversion revenues as reported, whatever their currency
+	Revenue    float64
+	Errors     map[string]int
+	latencies  []float64
+	totalItems int
+}
+
+// Overlap is the Jaccard similarity of the distinct products served by two vendors
+type Overlap struct {
+	VendorA   string
+	VendorB   string
+	ProductsA int
+	ProductsB int
+	Shared    int
+	Jaccard   float64
+}
+
+// Aggregator groups the events by the given dimensions, and collects the products served by each vendor. The
+// events of other types are ignored.
+type Aggregator struct {
+	groupBy  []string
+	rows     map[string]*Row
+	products map[string]map[string]struct{}
+}
+
+func NewAggregator(groupBy []string) (*Aggregator, error) {
+	for _, d := range groupBy {
+		if !slices.Contains(Dimensions, d) {
+			return nil, fmt.Errorf("unknown dimension %s (supported: %s)", d, strings.Join(Dimensions, ", "))
+		}
+	}
+	return &Aggregator{groupBy: groupBy, rows: map[string]*Row{}, products: map[string]map[string]struct{}{}}, nil
+}
+
+func (a *Aggregator) Add(e event.Event) {
+	switch e.Type {
+	case event.TypeServe:
+		a.addServe(a.row(e), e)
+	case event.TypeImpression:
+		a.row(e).Impressions++
+	case event.TypeClick:
+		a.row(e).Clicks++
+	case event.TypeConversion:
+		row := a.row(e)
+		row.Conversions++
+		row.Revenue += e.Revenue
+	}
+}
+
+// row returns the row of the event, created on its first event
+func (a *Aggregator) row(e event.Event) *Row {
+	keys := make([]string, 0, len(a.groupBy))
+	for _, d := range a.groupBy {
+		switch d {
+		case DimensionVendor:
+			keys = append(keys, e.Vendor)
+		case DimensionSite:
+			keys = append(keys, e.SiteID)
+		case DimensionHour:
+			keys = append(keys, e.Timestamp.UTC().Format("2006-01-02T15"))
+		}
+	}
+
+	id := strings.Join(keys, "\x00")
+	row, ok := a.rows[id]
+	if !ok {
+		row = &Row{Keys: keys, Errors: map[string]int{}}
+		a.rows[id] = row
+	}
+	return row
+}
+
+func (a *Aggregator) addServe(row *Row, e event.Event) {
+	row.Requests++
+	row.latencies = append(row.latencies, e.LatencyMs)
+	row.totalItems += len(e.ProductIDs)
+	if e.Outcome == outcomeOK && len(e.ProductIDs) > 0 {
+		row.Filled++
+	} else if e.Outcome != outcomeOK {
+		row.Errors[e.Outcome]++
+	}
+
+	if _, ok := a.products[e.Vendor]; !ok {
+		a.products[e.Vendor] = map[string]struct{}{}
+	}
+	for _, id := range e.ProductIDs {
+		a.products[e.Vendor][id] = struct{}{}
+	}
+}
+
+// Rows returns the statistics of each group, sorted by keys
+func (a *Aggregator) Rows() []Row {
+	rows := make([]Row, 0, len(a.rows))
+	for _, row := range a.rows {
+		slices.Sort(row.latencies)
+		// a group can have tracking events only
+		if row.Requests > 0 {
+			row.FillRate = float64(row.Filled) / float64(row.Requests)
+			row.AvgItems = float64(row.totalItems) / float64(row.Requests)
+		}
+		row.P50Ms = percentile(row.latencies, 50)
+		row.P95Ms = percentile(row.latencies, 95)
+		row.P99Ms = percentile(row.latencies, 99)
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return slices.Compare(rows[i].Keys, rows[j].Keys) < 0
+	})
+	return rows
+}
+
+// Overlaps returns the product overlap of each pair of vendors, sorted by vendor names
+func (a *Aggregator) Overlaps() []Overlap {
+	vendors := make([]string, 0, len(a.products))
+	for v := range a.products {
+		vendors = append(vendors, v)
+	}
+	slices.Sort(vendors)
+
+	var overlaps []Overlap
+	for i, vendorA := range vendors {
+		for _, vendorB := range vendors[i+1:] {
+			productsA, productsB := a.products[vendorA], a.products[vendorB]
+			shared := 0
+			for id := range productsA {
+				if _, ok := productsB[id]; ok {
+					shared++
+				}
+			}
+			o := Overlap{VendorA: vendorA, VendorB: vendorB, ProductsA: len(productsA), ProductsB: len(productsB), Shared: shared}
+			if union := len(productsA) + len(productsB) - shared; union > 0 {
+				o.Jaccard = float64(shared) / float64(union)
+			}
+			overlaps = append(overlaps, o)
+		}
+	}
+	return overlaps
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,66 @@
+// Package report aggregates the event log into per-vendor statistics
+package report
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rec-vendor-api/internal/event"
+)
+
+// maxLineSize bounds a single event line, events with many product IDs can exceed bufio's default
+const maxLineSize = 1024 * 1024
+
+// ReadFiles calls fn with the events of each JSON-lines file, files ending with .gz are gunzipped
+func ReadFiles(paths []string, fn func(event.Event)) error {
+	for _, path := range paths {
+		if err := readFile(path, fn); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func readFile(path string, fn func(event.Event)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return Read(r, fn)
+}
+
+// Read calls fn with the events of a JSON-lines stream
+func Read(r io.Reader, fn func(event.Event)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e event.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		fn(e)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var statsHeader = []string{"requests", "fill_rate", "p50_ms", "p95_ms", "p99_ms", "avg_items", "impressions", "clicks", "conversions", "revenue", "errors"}
+
+var overlapHeader = []string{"vendor_a", "vendor_b", "products_a", "products_b", "shared", "jaccard"}
+
+// WriteTable writes the statistics and the vendor overlaps as aligned text tables
+func WriteTable(w io.Writer, groupBy []string, rows []Row, overlaps []Overlap) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	writeTabLine(tw, append(slices.Clone(groupBy), statsHeader...))
+	for _, row := range rows {
+		writeTabLine(tw, append(slices.Clone(row.Keys), statsRecord(row)...))
+	}
+	if len(overlaps) > 0 {
+		fmt.Fprintln(tw)
+		writeTabLine(tw, overlapHeader)
+		for _, o := range overlaps {
+			writeTabLine(tw, overlapRecord(o))
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes the statistics and the vendor overlaps as two CSV sections separated by an empty line
+func WriteCSV(w io.Writer, groupBy []string, rows []Row, overlaps []Overlap) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(append(slices.Clone(groupBy), statsHeader...))
+	for _, row := range rows {
+		_ = cw.Write(append(slices.Clone(row.Keys), statsRecord(row)...))
+	}
+	if len(overlaps) > 0 {
+		cw.Flush()
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+		_ = cw.Write(overlapHeader)
+		for _, o := range overlaps {
+			_ = cw.Write(overlapRecord(o))
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func statsRecord(row Row) []string {
+	return []string{
+		strconv.Itoa(row.Requests),
+		formatFloat(row.FillRate, 4),
+		formatFloat(row.P50Ms, 1),
+		formatFloat(row.P95Ms, 1),
+		formatFloat(row.P99Ms, 1),
+		formatFloat(row.AvgItems, 2),
+		strconv.Itoa(row.Impressions),
+		strconv.Itoa(row.Clicks),
+		strconv.Itoa(row.Conversions),
+		formatFloat(row.Revenue, 2),
+		formatErrors(row.Errors),
+	}
+}
+
+func overlapRecord(o Overlap) []string {
+	return []string{
+		o.VendorA,
+		o.VendorB,
+		strconv.Itoa(o.ProductsA),
+		strconv.Itoa(o.ProductsB),
+		strconv.Itoa(o.Shared),
+		formatFloat(o.Jaccard, 4),
+	}
+}
+
+// formatErrors lists the error outcomes by name, e.g. "network timeout=2; no_products=5"
+func formatErrors(errors map[string]int) string {
+	outcomes := make([]string, 0, len(errors))
+	for outcome := range errors {
+		outcomes = append(outcomes, outcome)
+	}
+	slices.Sort(outcomes)
+
+	parts := make([]string, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		parts = append(parts, fmt.Sprintf("%s=%d", outcome, errors[outcome]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatFloat(f float64, prec int) string {
+	return strconv.FormatFloat(f, 'f', prec, 64)
+}
+
+func writeTabLine(w io.Writer, cells []string) {
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
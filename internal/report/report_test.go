@@ -0,0 +1,154 @@
+package report
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/event"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testEvents() []event.Event {
+	h10 := time.Date(2026, 1, 2, 10, 5, 0, 0, time.UTC)
+	h11 := time.Date(2026, 1, 2, 11, 5, 0, 0, time.UTC)
+	return []event.Event{
+		{Type: event.TypeServe, Timestamp: h10, Vendor: "linkmine", SiteID: "s1", LatencyMs: 10, Outcome: "ok", ProductIDs: []string{"1", "2"}},
+		{Type: event.TypeServe, Timestamp: h10, Vendor: "linkmine", SiteID: "s1", LatencyMs: 30, Outcome: "ok", ProductIDs: []string{"2", "3"}},
+		{Type: event.TypeServe, Timestamp: h11, Vendor: "linkmine", SiteID: "s2", LatencyMs: 20, Outcome: "no_products"},
+		{Type: event.TypeServe, Timestamp: h11, Vendor: "linkmine", SiteID: "s2", LatencyMs: 100, Outcome: "network timeout"},
+		{Type: event.TypeServe, Timestamp: h11, Vendor: "adpacker", SiteID: "s1", LatencyMs: 50, Outcome: "ok", ProductIDs: []string{"3", "4"}},
+		{Type: event.TypeImpression, Timestamp: h11, Vendor: "adpacker", SiteID: "s1", ClickID: "c3", Outcome: "ok", ProductIDs: []string{"3"}},
+		{Type: event.TypeImpression, Timestamp: h11, Vendor: "adpacker", SiteID: "s1", ClickID: "c3", Outcome: "ok", ProductIDs: []string{"4"}},
+		{Type: event.TypeClick, Timestamp: h11, Vendor: "adpacker", SiteID: "s1", ClickID: "c3", Outcome: "ok", ProductIDs: []string{"3"}},
+		{Type: event.TypeConversion, Timestamp: h11, Vendor: "linkmine", ClickID: "c1", Outcome: "ok", Revenue: 12.5, Currency: "KRW"},
+		{Type: event.TypeConversion, Timestamp: h11, Vendor: "linkmine", ClickID: "c2", Outcome: "ok", Revenue: 7.5, Currency: "KRW"},
+		{Type: "unknown", Timestamp: h11, Vendor: "keeta"},
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	tt := []struct {
+		name    string
+		groupBy []string
+		want    []Row
+	}{
+		{
+			name:    "GIVEN grouping by vendor THEN expect one row per vendor",
+			groupBy: []string{DimensionVendor},
+			want: []Row{
+				{Keys: []string{"adpacker"}, Requests: 1, Filled: 1, FillRate: 1, P50Ms: 50, P95Ms: 50, P99Ms: 50, AvgItems: 2, Impressions: 2, Clicks: 1, Errors: map[string]int{}},
+				{Keys: []string{"linkmine"}, Requests: 4, Filled: 2, FillRate: 0.5, P50Ms: 20, P95Ms: 100, P99Ms: 100, AvgItems: 1,
+					Conversions: 2, Revenue: 20, Errors: map[string]int{"no_products": 1, "network timeout": 1}},
+			},
+		},
+		{
+			name:    "GIVEN grouping by vendor, site and hour THEN expect one row per combination, the conversions without a site apart",
+			groupBy: []string{DimensionVendor, DimensionSite, DimensionHour},
+			want: []Row{
+				{Keys: []string{"adpacker", "s1", "2026-01-02T11"}, Requests: 1, Filled: 1, FillRate: 1, P50Ms: 50, P95Ms: 50, P99Ms: 50, AvgItems: 2, Impressions: 2, Clicks: 1,
+					Errors: map[string]int{}},
+				{Keys: []string{"linkmine", "", "2026-01-02T11"}, Conversions: 2, Revenue: 20, Errors: map[string]int{}},
+				{Keys: []string{"linkmine", "s1", "2026-01-02T10"}, Requests: 2, Filled: 2, FillRate: 1, P50Ms: 10, P95Ms: 30, P99Ms: 30, AvgItems: 2, Errors: map[string]int{}},
+				{Keys: []string{"linkmine", "s2", "2026-01-02T11"}, Requests: 2, FillRate: 0, P50Ms: 20, P95Ms: 100, P99Ms: 100, AvgItems: 0,
+					Errors: map[string]int{"no_products": 1, "network timeout": 1}},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := NewAggregator(tc.groupBy)
+			require.NoError(t, err)
+			for _, e := range testEvents() {
+				a.Add(e)
+			}
+
+			got := a.Rows()
+			for i := range got {
+				got[i].latencies, got[i].totalItems = nil, 0
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := NewAggregator([]string{"country"})
+	require.EqualError(t, err, "unknown dimension country (supported: vendor, site, hour)")
+}
+
+func TestOverlaps(t *testing.T) {
+	a, err := NewAggregator([]string{DimensionVendor})
+	require.NoError(t, err)
+	for _, e := range testEvents() {
+		a.Add(e)
+	}
+
+	// linkmine served 1, 2, 3 and adpacker 3, 4
+	require.Equal(t, []Overlap{{VendorA: "adpacker", VendorB: "linkmine", ProductsA: 2, ProductsB: 3, Shared: 1, Jaccard: 0.25}}, a.Overlaps())
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	lines := `{"type":"serve","vendor":"linkmine","outcome":"ok","product_ids":["1"]}
+{"type":"conversion","vendor":"linkmine","revenue":10}
+
+{"type":"serve","vendor":"adpacker","outcome":"no_products","product_ids":[]}
+`
+	plainPath := filepath.Join(dir, "events.jsonl")
+	require.NoError(t, os.WriteFile(plainPath, []byte(lines), 0o644))
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err := gw.Write([]byte(lines))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	gzPath := filepath.Join(dir, "events.jsonl.1.gz")
+	require.NoError(t, os.WriteFile(gzPath, gz.Bytes(), 0o644))
+
+	var vendors []string
+	require.NoError(t, ReadFiles([]string{plainPath, gzPath}, func(e event.Event) { vendors = append(vendors, e.Vendor) }))
+	require.Equal(t, []string{"linkmine", "linkmine", "adpacker", "linkmine", "linkmine", "adpacker"}, vendors)
+
+	badPath := filepath.Join(dir, "bad.jsonl")
+	require.NoError(t, os.WriteFile(badPath, []byte("{\"type\":\"serve\"}\nnot json\n"), 0o644))
+	err = ReadFiles([]string{badPath}, func(event.Event) {})
+	require.ErrorContains(t, err, "bad.jsonl: line 2")
+}
+
+func TestWrite(t *testing.T) {
+	a, err := NewAggregator([]string{DimensionVendor})
+	require.NoError(t, err)
+	for _, e := range testEvents() {
+		a.Add(e)
+	}
+	groupBy := []string{DimensionVendor}
+
+	var table bytes.Buffer
+	require.NoError(t, WriteTable(&table, groupBy, a.Rows(), a.Overlaps()))
+	require.Equal(t, strings.Join([]string{
+		"vendor    requests  fill_rate  p50_ms  p95_ms  p99_ms  avg_items  impressions  clicks  conversions  revenue  errors",
+		"adpacker  1         1.0000     50.0    50.0    50.0    2.00       2            1       0            0.00     ",
+		"linkmine  4         0.5000     20.0    100.0   100.0   1.00       0            0       2            20.00    network timeout=1; no_products=1",
+		"",
+		"vendor_a  vendor_b  products_a  products_b  shared  jaccard",
+		"adpacker  linkmine  2           3           1       0.2500",
+		"",
+	}, "\n"), table.String())
+
+	var csv bytes.Buffer
+	require.NoError(t, WriteCSV(&csv, groupBy, a.Rows(), a.Overlaps()))
+	require.Equal(t, strings.Join([]string{
+		"vendor,requests,fill_rate,p50_ms,p95_ms,p99_ms,avg_items,impressions,clicks,conversions,revenue,errors",
+		"adpacker,1,1.0000,50.0,50.0,50.0,2.00,2,1,0,0.00,",
+		"linkmine,4,0.5000,20.0,100.0,100.0,1.00,0,0,2,20.00,network timeout=1; no_products=1",
+		"",
+		"vendor_a,vendor_b,products_a,products_b,shared,jaccard",
+		"adpacker,linkmine,2,3,1,0.2500",
+		"",
+	}, "\n"), csv.String())
+}
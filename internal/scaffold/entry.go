@@ -0,0 +1,69 @@
+package scaffold
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"rec-vendor-api/internal/config"
+)
+
+// VendorEntry renders the vendor as an entry of the vendors list of vendors.yaml. The queries without a macro
+// are commented, since they were copied from the sample request.
+func VendorEntry(v config.Vendor) string {
+	var buf bytes.Buffer
+	// the template only fails on an invalid field, which the tests cover
+	_ = entryTemplate.Execute(&buf, v)
+	return buf.String()
+}
+
+// namedPattern is the request or tracking URL pattern with its key
+type namedPattern struct {
+	Name string
+	config.URLPattern
+}
+
+var entryTemplate = template.Must(template.New("entry").Funcs(template.FuncMap{
+	"quote":   strconv.Quote,
+	"pattern": func(name string, p config.URLPattern) namedPattern { return namedPattern{Name: name, URLPattern: p} },
+	"literal": func(value string) bool { return value != "" && !strings.Contains(value, "{") },
+}).Parse(`  - name: {{.Name}}
+    with_proxy: {{.WithProxy}}
+    http_method: {{.HTTPMethod}}
+{{- template "pattern" (pattern "request" .Request)}}
+{{- template "pattern" (pattern "tracking" .Tracking)}}
+{{- with .Response}}{{if .ID}}
+    response:
+      items: {{quote .Items}}
+      id: {{quote .ID}}
+      url: {{quote .URL}}
+{{- if .Image}}
+      image: {{quote .Image}}
+{{- end}}
+{{- if .Title}}
+      title: {{quote .Title}}
+{{- end}}
+{{- if .Price}}
+      price: {{quote .Price}}
+{{- end}}
+{{- if .SalePrice}}
+      sale_price: {{quote .SalePrice}}
+{{- end}}
+{{- if .Currency}}
+      currency: {{quote .Currency}}
+{{- end}}
+{{- end}}{{end}}
+{{define "pattern"}}
+    {{.Name}}:
+      url: {{quote .URL}}
+{{- if .Queries}}
+      queries:
+{{- range .Queries}}
+        - key: {{.Key}}
+          value: {{quote .Value}}{{if literal .Value}} # from the sample request{{end}}
+{{- end}}
+{{- else}}
+      queries: []
+{{- end}}
+{{- end}}`))
@@ -0,0 +1,399 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"rec-vendor-api/internal/config"
+)
+
+// Go types of the product fields
+const (
+	kindString = "string"
+	kindInt    = "int"
+	kindFloat  = "float64"
+)
+
+// field is a product field of the generated unmarshaler
+type field struct {
+	Resp   string // the PartnerResp field
+	Key    string // the JSON key in the product
+	GoName string
+	Kind   string
+}
+
+func (f field) Conversion() string {
+	switch f.Kind {
+	case kindInt:
+		return fmt.Sprintf("strconv.Itoa(item.%s)", f.GoName)
+	case kindFloat:
+		return fmt.Sprintf("strconv.FormatFloat(item.%s, 'f', -1, 64)", f.GoName)
+	default:
+		return "item." + f.GoName
+	}
+}
+
+// wrapper is a struct on the path to the product list, e.g. keetaResp and keetaData for data.items
+type wrapper struct {
+	Type      string
+	Field     string
+	FieldType string
+	Key       string
+}
+
+type unmarshalerData struct {
+	Name      string
+	ItemType  string
+	RespType  string
+	ItemsExpr string
+	Wrappers  []wrapper
+	Fields    []field
+	Strconv   bool
+}
+
+type testCase struct {
+	Name        string
+	Body        string
+	Want        [][]wantField
+	WantedError bool
+}
+
+type wantField struct {
+	Resp  string
+	Value string
+}
+
+// GenerateUnmarshaler generates the source of an unmarshaler for the response mapping, in the style of the
+// unmarshaler package, with a table-driven test of the sample response. The fields must be top-level keys of
+// the products, as inferred by InferResponse.
+func GenerateUnmarshaler(name string, r config.Response, sample []byte) (code, test []byte, err error) {
+	doc, err := decode(sample)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, ok := lookupItems(doc, r.Items)
+	if !ok {
+		return nil, nil, fmt.Errorf("no list of objects at %q in the sample response", r.Items)
+	}
+
+	typeName := TypeName(name)
+	lower := strings.ToLower(typeName[:1]) + typeName[1:]
+	data := unmarshalerData{Name: typeName, RespType: lower + "Resp", ItemType: lower + "Resp"}
+	if r.Items == "" {
+		data.RespType = "[]" + data.ItemType
+	} else {
+		data.ItemType = lower + "Item"
+		segments := strings.Split(r.Items, ".")
+		typ, prefix := lower+"Resp", lower
+		for i, seg := range segments {
+			prefix += goName(seg)
+			w := wrapper{Type: typ, Field: goName(seg), FieldType: prefix, Key: seg}
+			if i == len(segments)-1 {
+				w.FieldType = "[]" + data.ItemType
+			}
+			data.Wrappers = append(data.Wrappers, w)
+			data.ItemsExpr += "." + w.Field
+			typ = prefix
+		}
+	}
+
+	mapped := []struct{ resp, key string }{
+		{"ProductID", r.ID}, {"ProductURL", r.URL}, {"ProductImage", r.Image}, {"ProductTitle", r.Title},
+		{"ProductPrice", r.Price}, {"ProductSalePrice", r.SalePrice}, {"ProductCurrency", r.Currency},
+	}
+	goNames := map[string]string{}
+	for _, m := range mapped {
+		if m.key == "" {
+			continue
+		}
+		if strings.Contains(m.key, ".") {
+			return nil, nil, fmt.Errorf("%s: nested key %q is not supported, use the response mapping instead", m.resp, m.key)
+		}
+		kind, err := fieldKind(items, m.key)
+		if err != nil {
+			return nil, nil, err
+		}
+		f := field{Resp: m.resp, Key: m.key, GoName: goName(m.key), Kind: kind}
+		if other, ok := goNames[f.GoName]; ok && other != f.Key {
+			return nil, nil, fmt.Errorf("keys %q and %q have the same Go name %s", other, f.Key, f.GoName)
+		}
+		goNames[f.GoName] = f.Key
+		data.Fields = append(data.Fields, f)
+		data.Strconv = data.Strconv || kind != kindString
+	}
+
+	if code, err = render(unmarshalerTemplate, data); err != nil {
+		return nil, nil, err
+	}
+
+	want := make([][]wantField, 0, len(items))
+	for _, item := range items {
+		var product []wantField
+		for _, f := range data.Fields {
+			if v := fieldValue(item[f.Key], f.Kind); v != "" {
+				product = append(product, wantField{Resp: f.Resp, Value: strconv.Quote(v)})
+			}
+		}
+		want = append(want, product)
+	}
+	cases := []testCase{
+		{Name: "GIVEN the sample response THEN return parsed products", Body: rawString(indent(sample)), Want: want},
+		{Name: "GIVEN empty response THEN return error", Body: rawString(emptyResponse(r.Items)), WantedError: true},
+		{Name: "GIVEN invalid JSON THEN return error", Body: "`invalid json`", WantedError: true},
+	}
+	if test, err = render(testTemplate, struct {
+		Name  string
+		Cases []testCase
+	}{typeName, cases}); err != nil {
+		return nil, nil, err
+	}
+	return code, test, nil
+}
+
+// lookupItems returns the objects of the list at the dot-separated path
+func lookupItems(doc any, path string) ([]map[string]any, bool) {
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			obj, ok := doc.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			doc = obj[key]
+		}
+	}
+	list, ok := doc.([]any)
+	if !ok {
+		return nil, false
+	}
+	items := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, obj)
+	}
+	return items, true
+}
+
+// fieldKind returns the Go type of the key, it fails if the products have different JSON types for it
+func fieldKind(items []map[string]any, key string) (string, error) {
+	kind := ""
+	for _, item := range items {
+		var k string
+		switch v := item[key].(type) {
+		case nil:
+			continue
+		case string:
+			k = kindString
+		case json.Number:
+			k = kindFloat
+			if _, err := v.Int64(); err == nil {
+				k = kindInt
+			}
+		default:
+			return "", fmt.Errorf("key %q is not a string or a number", key)
+		}
+		if kind != "" && kind != k {
+			// an int and a float are parsed as float
+			if (kind == kindInt && k == kindFloat) || (kind == kindFloat && k == kindInt) {
+				kind = kindFloat
+				continue
+			}
+			return "", fmt.Errorf("key %q is a %s and a %s in the sample, use the response mapping instead", key, kind, k)
+		}
+		kind = k
+	}
+	if kind == "" {
+		return kindString, nil
+	}
+	return kind, nil
+}
+
+// fieldValue returns the value the generated unmarshaler parses from the JSON value
+func fieldValue(v any, kind string) string {
+	// a missing number is parsed as 0
+	number, _ := v.(json.Number)
+	switch kind {
+	case kindInt:
+		n, _ := number.Int64()
+		return strconv.Itoa(int(n))
+	case kindFloat:
+		n, _ := number.Float64()
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	default:
+		s, _ := v.(string)
+		return s
+	}
+}
+
+// TypeName returns the name of the unmarshaler generated for the vendor, e.g. AcmeShop for acme_shop
+func TypeName(vendor string) string {
+	return goName(vendor)
+}
+
+// goName returns the exported Go name of a vendor name or JSON key, e.g. ProductURL for productUrl
+func goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name := b.String()
+	for _, initialism := range []string{"Id", "Url"} {
+		if strings.HasSuffix(name, initialism) {
+			name = strings.TrimSuffix(name, initialism) + strings.ToUpper(initialism)
+		}
+	}
+	return name
+}
+
+// indent indents the sample like the response bodies of the unmarshaler tests
+func indent(sample []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(sample), "\t\t\t", "\t"); err != nil {
+		return string(sample)
+	}
+	return buf.String()
+}
+
+func emptyResponse(path string) string {
+	body := "[]"
+	if path == "" {
+		return body
+	}
+	segments := strings.Split(path, ".")
+	for i := len(segments) - 1; i >= 0; i-- {
+		key, _ := json.Marshal(segments[i])
+		body = fmt.Sprintf("{%s: %s}", key, body)
+	}
+	return body
+}
+
+// rawString returns a Go raw string literal of s, or an interpreted one if s has a backquote
+func rawString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func render(tmpl *template.Template, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go source: %w", err)
+	}
+	return src, nil
+}
+
+var unmarshalerTemplate = template.Must(template.New("unmarshaler").Parse(`package unmarshaler
+
+import (
+	"context"
+	"encoding/json"
+{{- if .Strconv}}
+	"strconv"
+{{- end}}
+
+	"rec-vendor-api/internal/redact"
+
+	log "github.com/sirupsen/logrus"
+)
+{{range .Wrappers}}
+type {{.Type}} struct {
+	{{.Field}} {{.FieldType}} ` + "`json:\"{{.Key}}\"`" + `
+}
+{{end}}
+type {{.ItemType}} struct {
+{{- range .Fields}}
+	{{.GoName}} {{.Kind}} ` + "`json:\"{{.Key}}\"`" + `
+{{- end}}
+}
+
+type {{.Name}} struct{}
+
+func (s *{{.Name}}) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
+	var resp {{.RespType}}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
+		return nil, newInvalidFormatError(body)
+	}
+	if len(resp{{.ItemsExpr}}) == 0 {
+		return nil, ErrNoProducts
+	}
+
+	res := make([]PartnerResp, 0, len(resp{{.ItemsExpr}}))
+	for _, item := range resp{{.ItemsExpr}} {
+		res = append(res, PartnerResp{
+{{- range .Fields}}
+			{{.Resp}}: {{.Conversion}},
+{{- end}}
+		})
+	}
+
+	return res, nil
+}
+`))
+
+var testTemplate = template.Must(template.New("test").Parse(`package unmarshaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test{{.Name}}_UnmarshalResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        []byte
+		want        []PartnerResp
+		wantedError bool
+	}{
+{{- range .Cases}}
+		{
+			name: "{{.Name}}",
+			body: []byte({{.Body}}),
+{{- if .WantedError}}
+			wantedError: true,
+{{- else}}
+			want: []PartnerResp{
+{{- range .Want}}
+				{
+{{- range .}}
+					{{.Resp}}: {{.Value}},
+{{- end}}
+				},
+{{- end}}
+			},
+{{- end}}
+		},
+{{- end}}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unmarshaler := &{{.Name}}{}
+
+			result, err := unmarshaler.UnmarshalResponse(context.Background(), tt.body)
+
+			if tt.wantedError {
+				require.Error(t, err)
+				require.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, result)
+			}
+		})
+	}
+}
+`))
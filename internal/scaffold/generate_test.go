@@ -0,0 +1,127 @@
+package scaffold
+
+import (
+	"testing"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateUnmarshaler(t *testing.T) {
+	tt := []struct {
+		name      string
+		vendor    string
+		response  config.Response
+		body      string
+		wantCode  []string
+		wantTest  []string
+		wantError bool
+	}{
+		{
+			name:     "GIVEN a nested product list THEN expect the wrapper types and the parsed sample in the test",
+			vendor:   "acme_shop",
+			response: config.Response{Items: "data.items", ID: "id", URL: "deeplink", Price: "price"},
+			body:     `{"data": {"items": [{"id": 1, "deeplink": "https://shop/1", "price": 10.5}, {"id": 2, "deeplink": "https://shop/2", "price": 11}]}}`,
+			wantCode: []string{
+				"type acmeShopResp struct {\n\tData acmeShopData `json:\"data\"`\n}",
+				"type acmeShopData struct {\n\tItems []acmeShopItem `json:\"items\"`\n}",
+				"\tID       int     `json:\"id\"`\n",
+				"\tPrice    float64 `json:\"price\"`\n",
+				"type AcmeShop struct{}",
+				"ProductID:    strconv.Itoa(item.ID),",
+				"ProductPrice: strconv.FormatFloat(item.Price, 'f', -1, 64),",
+				"for _, item := range resp.Data.Items {",
+			},
+			wantTest: []string{
+				"func TestAcmeShop_UnmarshalResponse(t *testing.T) {",
+				"ProductID:    \"2\",\n\t\t\t\t\tProductURL:   \"https://shop/2\",\n\t\t\t\t\tProductPrice: \"11\",",
+				"body:        []byte(`{\"data\": {\"items\": []}}`),",
+				"unmarshaler := &AcmeShop{}",
+			},
+		},
+		{
+			name:     "GIVEN a top-level product list THEN expect the item type as response",
+			vendor:   "shop",
+			response: config.Response{ID: "productId", URL: "productUrl", Title: "productName"},
+			body:     `[{"productId": "p1", "productUrl": "https://shop/1", "productName": "Sofa"}]`,
+			wantCode: []string{
+				"type shopResp struct {\n\tProductID   string `json:\"productId\"`\n\tProductURL  string `json:\"productUrl\"`\n\tProductName string `json:\"productName\"`\n}",
+				"var resp []shopResp",
+				"ProductTitle: item.ProductName,",
+			},
+			wantTest: []string{
+				"body: []byte(`[\n\t\t\t\t{\n\t\t\t\t\t\"productId\": \"p1\",",
+				"body:        []byte(`[]`),",
+			},
+		},
+		{
+			name:      "GIVEN a key with a string and a number THEN expect error",
+			vendor:    "shop",
+			response:  config.Response{ID: "id", URL: "url"},
+			body:      `[{"id": "p1", "url": "https://shop/1"}, {"id": 2, "url": "https://shop/2"}]`,
+			wantError: true,
+		},
+		{
+			name:      "GIVEN a nested key THEN expect error",
+			vendor:    "shop",
+			response:  config.Response{ID: "id", URL: "link.url"},
+			body:      `[{"id": "p1", "link": {"url": "https://shop/1"}}]`,
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			code, test, err := GenerateUnmarshaler(tc.vendor, tc.response, []byte(tc.body))
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, want := range tc.wantCode {
+				require.Contains(t, string(code), want)
+			}
+			for _, want := range tc.wantTest {
+				require.Contains(t, string(test), want)
+			}
+		})
+	}
+}
+
+func TestVendorEntry(t *testing.T) {
+	v := config.Vendor{
+		Name:       "acme",
+		WithProxy:  true,
+		HTTPMethod: "GET",
+		Request: config.URLPattern{URL: "https://api.example.com/reco", Queries: []config.Query{
+			{Key: "adid", Value: "{user_id_lower}"},
+			{Key: "code", Value: "AF1"},
+		}},
+		Tracking: config.URLPattern{URL: "{product_url}", Queries: []config.Query{}},
+		Response: config.Response{Items: "data", ID: "id", URL: "url", Price: "price"},
+	}
+
+	require.Equal(t, `  - name: acme
+    with_proxy: true
+    http_method: GET
+    request:
+      url: "https://api.example.com/reco"
+      queries:
+        - key: adid
+          value: "{user_id_lower}"
+        - key: code
+          value: "AF1" # from the sample request
+    tracking:
+      url: "{product_url}"
+      queries: []
+    response:
+      items: "data"
+      id: "id"
+      url: "url"
+      price: "price"
+`, VendorEntry(v))
+
+	v.Response = config.Response{}
+	require.NotContains(t, VendorEntry(v), "response:")
+}
@@ -0,0 +1,93 @@
+// Package scaffold infers the vendors.yaml entry and the unmarshaler of a new vendor from a sample exchange
+package scaffold
+
+import (
+	"fmt"
+	urlpkg "net/url"
+	"regexp"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+)
+
+var (
+	uuidRegExp      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	imageSizeRegExp = regexp.MustCompile(`^\d+x\d+$`)
+
+	// keyNormalizer normalizes the lowercase keys, e.g. device_id and deviceId to deviceid
+	keyNormalizer = strings.NewReplacer("_", "", "-", "")
+)
+
+// macroRules map the sample query values to the macros of url.Default, by their normalized key, or by the
+// shape of their value
+var macroRules = []struct {
+	macro string
+	keys  []string
+	value *regexp.Regexp
+}{
+	{macro: "{user_id_lower}", keys: []string{"deviceid", "adid", "gaid", "idfa", "ifa", "advertisingid", "userid", "uid"}, value: uuidRegExp},
+	{macro: "{click_id_base64}", keys: []string{"clickid", "reqid", "requestid", "transactionid"}},
+	{macro: "{subid}", keys: []string{"subid"}},
+	{macro: "{width}x{height}", value: imageSizeRegExp},
+	{macro: "{width}", keys: []string{"w", "width", "imgwidth"}},
+	{macro: "{height}", keys: []string{"h", "height", "imgheight"}},
+	{macro: "{adtype}", keys: []string{"adtype", "impadtype"}},
+	{macro: "{bundle_id}", keys: []string{"bundleid", "bundle", "appbundleid"}},
+	{macro: "{web_host}", keys: []string{"webhost", "sitehost"}},
+	{macro: "{latitude}", keys: []string{"lat", "latitude"}},
+	{macro: "{longitude}", keys: []string{"lon", "lng", "longitude"}},
+	{macro: "{client_ip}", keys: []string{"ip", "clientip", "userip"}},
+}
+
+// InferRequest splits the sample request URL into the URL and its queries, in their order, and replaces the
+// sample values of the known queries by their macros. The other values, and the empty ones, are kept as is.
+func InferRequest(rawURL string) (config.URLPattern, error) {
+	u, err := urlpkg.Parse(rawURL)
+	if err != nil {
+		return config.URLPattern{}, fmt.Errorf("invalid request url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return config.URLPattern{}, fmt.Errorf("invalid request url %q: expected an absolute http(s) url", rawURL)
+	}
+
+	queries := []config.Query{}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := urlpkg.QueryUnescape(rawKey)
+		if err != nil {
+			return config.URLPattern{}, fmt.Errorf("invalid query %q: %w", pair, err)
+		}
+		value, err := urlpkg.QueryUnescape(rawValue)
+		if err != nil {
+			return config.URLPattern{}, fmt.Errorf("invalid query %q: %w", pair, err)
+		}
+		queries = append(queries, config.Query{Key: key, Value: inferMacro(key, value)})
+	}
+
+	u.RawQuery, u.Fragment = "", ""
+	return config.URLPattern{URL: u.String(), Queries: queries}, nil
+}
+
+func inferMacro(key, value string) string {
+	if value == "" {
+		return value
+	}
+	// the keys take precedence over the values, e.g. a click ID can be a UUID too
+	normalized := keyNormalizer.Replace(strings.ToLower(key))
+	for _, rule := range macroRules {
+		for _, k := range rule.keys {
+			if normalized == k {
+				return rule.macro
+			}
+		}
+	}
+	for _, rule := range macroRules {
+		if rule.value != nil && rule.value.MatchString(value) {
+			return rule.macro
+		}
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package scaffold
+
+import (
+	"testing"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferRequest(t *testing.T) {
+	tt := []struct {
+		name      string
+		url       string
+		want      config.URLPattern
+		wantError bool
+	}{
+		{
+			name: "GIVEN known query keys and values THEN expect their macros in the query order",
+			url:  "https://api.example.com/reco/v2?subId=s1&device_id=abcd&click_id=3F2504E0-4F89-11D3-9A0C-0305E82C3301&imp_imageSize=300x250&lat=37.5&lon=127.0",
+			want: config.URLPattern{URL: "https://api.example.com/reco/v2", Queries: []config.Query{
+				{Key: "subId", Value: "{subid}"},
+				{Key: "device_id", Value: "{user_id_lower}"},
+				{Key: "click_id", Value: "{click_id_base64}"},
+				{Key: "imp_imageSize", Value: "{width}x{height}"},
+				{Key: "lat", Value: "{latitude}"},
+				{Key: "lon", Value: "{longitude}"},
+			}},
+		},
+		{
+			name: "GIVEN a UUID under an unknown key THEN expect the user ID macro",
+			url:  "https://api.example.com/reco?ad=3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+			want: config.URLPattern{URL: "https://api.example.com/reco", Queries: []config.Query{{Key: "ad", Value: "{user_id_lower}"}}},
+		},
+		{
+			name: "GIVEN unknown and empty values THEN expect them kept",
+			url:  "https://api.example.com/reco?trackingCode=AF3617420&app_bundleId=&name=a%20b",
+			want: config.URLPattern{URL: "https://api.example.com/reco", Queries: []config.Query{
+				{Key: "trackingCode", Value: "AF3617420"},
+				{Key: "app_bundleId", Value: ""},
+				{Key: "name", Value: "a b"},
+			}},
+		},
+		{
+			name: "GIVEN no queries THEN expect an empty query list",
+			url:  "https://api.example.com/reco",
+			want: config.URLPattern{URL: "https://api.example.com/reco", Queries: []config.Query{}},
+		},
+		{
+			name:      "GIVEN a relative URL THEN expect error",
+			url:       "/reco?w=300",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := InferRequest(tc.url)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
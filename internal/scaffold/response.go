@@ -0,0 +1,154 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+)
+
+var ErrNoProductList = errors.New("no list of objects found in the sample response")
+
+// fieldRules find the product fields by the fragments of their normalized keys, in the order of PartnerResp
+var fieldRules = []struct {
+	field    string
+	set      func(r *config.Response, key string)
+	hints    []string
+	excludes []string
+}{
+	{field: "id", set: func(r *config.Response, key string) { r.ID = key }, hints: []string{"productid", "itemid", "id"}, excludes: []string{"category", "campaign", "shop", "seller", "brand"}},
+	{field: "url", set: func(r *config.Response, key string) { r.URL = key }, hints: []string{"deeplink", "url", "link"}, excludes: []string{"image", "img", "thumbnail"}},
+	{field: "image", set: func(r *config.Response, key string) { r.Image = key }, hints: []string{"image", "img", "thumbnail"}},
+	{field: "title", set: func(r *config.Response, key string) { r.Title = key }, hints: []string{"title", "name"}, excludes: []string{"brand", "shop", "seller", "category"}},
+	{field: "sale_price", set: func(r *config.Response, key string) { r.SalePrice = key }, hints: []string{"saleprice", "discountprice"}},
+	{field: "price", set: func(r *config.Response, key string) { r.Price = key }, hints: []string{"price"}, excludes: []string{"sale", "discount"}},
+	{field: "currency", set: func(r *config.Response, key string) { r.Currency = key }, hints: []string{"currency"}},
+}
+
+// InferResponse finds the product list of the sample response, the first list of objects in breadth-first
+// order, and maps the scalar keys of its products to the product fields. The id and url are required.
+func InferResponse(body []byte) (config.Response, error) {
+	doc, err := decode(body)
+	if err != nil {
+		return config.Response{}, err
+	}
+	path, items, ok := findProductList(doc)
+	if !ok {
+		return config.Response{}, ErrNoProductList
+	}
+
+	keys := scalarKeys(items)
+	r := config.Response{Items: path}
+	used := map[string]bool{}
+	for _, rule := range fieldRules {
+		// the hints are tried in order, so that productId is preferred over another key ending with id
+		for _, hint := range rule.hints {
+			key := findKey(keys, used, hint, rule.excludes)
+			if key != "" {
+				rule.set(&r, key)
+				used[key] = true
+				break
+			}
+		}
+	}
+	if r.ID == "" || r.URL == "" {
+		return config.Response{}, fmt.Errorf("no product id or url found in the keys %s of the products at %q", strings.Join(keys, ", "), path)
+	}
+	return r, nil
+}
+
+func decode(body []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid sample response: %w", err)
+	}
+	return doc, nil
+}
+
+// findProductList returns the dot-separated path of the first list of objects, "" for a top-level list
+func findProductList(doc any) (string, []map[string]any, bool) {
+	type node struct {
+		path  string
+		value any
+	}
+	queue := []node{{value: doc}}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		switch v := n.value.(type) {
+		case []any:
+			items := make([]map[string]any, 0, len(v))
+			for _, item := range v {
+				if obj, ok := item.(map[string]any); ok {
+					items = append(items, obj)
+				}
+			}
+			if len(items) > 0 && len(items) == len(v) {
+				return n.path, items, true
+			}
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				path := key
+				if n.path != "" {
+					path = n.path + "." + key
+				}
+				queue = append(queue, node{path: path, value: v[key]})
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// scalarKeys returns the sorted keys with a string or number value in any product
+func scalarKeys(items []map[string]any) []string {
+	seen := map[string]bool{}
+	for _, item := range items {
+		for key, v := range item {
+			switch v.(type) {
+			case string, json.Number:
+				seen[key] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func findKey(keys []string, used map[string]bool, hint string, excludes []string) string {
+	for _, key := range keys {
+		lower := keyNormalizer.Replace(strings.ToLower(key))
+		if used[key] || !strings.Contains(lower, hint) || containsAny(lower, excludes) {
+			continue
+		}
+		// a short hint such as id must end the key, so that e.g. width is not taken for an id
+		if len(hint) <= 2 && !strings.HasSuffix(lower, hint) {
+			continue
+		}
+		return key
+	}
+	return ""
+}
+
+func containsAny(s string, fragments []string) bool {
+	for _, f := range fragments {
+		if strings.Contains(s, f) {
+			return true
+		}
+	}
+	return false
+}
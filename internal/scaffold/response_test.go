@@ -0,0 +1,61 @@
+package scaffold
+
+import (
+	"testing"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferResponse(t *testing.T) {
+	tt := []struct {
+		name      string
+		body      string
+		want      config.Response
+		wantError bool
+	}{
+		{
+			name: "GIVEN a top-level product list THEN expect the product keys",
+			body: `[{"productId": "1", "productName": "Sofa", "productPrice": 12000, "productImage": "https://img/1.jpg", "productUrl": "https://shop/1"}]`,
+			want: config.Response{ID: "productId", URL: "productUrl", Image: "productImage", Title: "productName", Price: "productPrice"},
+		},
+		{
+			name: "GIVEN a nested product list THEN expect its path",
+			body: `{"code": 0, "data": {"bid": true, "items": [{"id": "1", "categoryId": 7, "deeplink": "https://shop/1", "price": "10", "salePrice": "9", "currency": "HKD"}]}}`,
+			want: config.Response{Items: "data.items", ID: "id", URL: "deeplink", Price: "price", SalePrice: "salePrice", Currency: "currency"},
+		},
+		{
+			name: "GIVEN snake case keys THEN expect them matched",
+			body: `{"result": [{"item_id": 1, "landing_url": "https://shop/1", "thumbnail_url": "https://img/1.jpg", "discount_price": 900}]}`,
+			want: config.Response{Items: "result", ID: "item_id", URL: "landing_url", Image: "thumbnail_url", SalePrice: "discount_price"},
+		},
+		{
+			name:      "GIVEN no list of objects THEN expect error",
+			body:      `{"data": {"ids": [1, 2]}}`,
+			wantError: true,
+		},
+		{
+			name:      "GIVEN products without a URL THEN expect error",
+			body:      `[{"id": 1, "title": "Sofa"}]`,
+			wantError: true,
+		},
+		{
+			name:      "GIVEN invalid JSON THEN expect error",
+			body:      `invalid json`,
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := InferResponse([]byte(tc.body))
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
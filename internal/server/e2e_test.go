@@ -0,0 +1,406 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/mockvendor"
+	"rec-vendor-api/internal/vendor"
+
+	schema "github.com/plaxieappier/rec-schema/go/vendorapi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	e2eOID    = "e2e-oid"
+	e2eUserID = "E2E-USER"
+)
+
+// product is the part of the response compared between the servers
+type product struct {
+	ID  string
+	URL string
+}
+
+type result struct {
+	products []product
+	httpCode int
+	grpcCode codes.Code
+}
+
+type e2eTestSuite struct {
+	suite.Suite
+	vendorServer *httptest.Server
+	server       *Server
+	listeners    Listeners
+	grpcConn     *grpc.ClientConn
+	eventsPath   string
+}
+
+func TestE2ETestSuite(t *testing.T) {
+	suite.Run(t, &e2eTestSuite{})
+}
+
+func (ts *e2eTestSuite) SetupSuite() {
+	ts.vendorServer = httptest.NewServer(mockvendor.NewServer(mockvendor.Config{Products: 3, ProductBaseURL: "https://mock.test"}))
+	ts.eventsPath = filepath.Join(ts.T().TempDir(), "events.jsonl")
+
+	vendorConfig := func(name, requestURL string) config.Vendor {
+		return config.Vendor{
+			Name:       name,
+			HTTPMethod: http.MethodGet,
+			Request:    config.URLPattern{URL: requestURL, Queries: []config.Query{{Key: "adid", Value: "{user_id_lower}"}}},
+			Tracking:   config.URLPattern{URL: "{product_url}", Queries: []config.Query{{Key: "click_id", Value: "{click_id}"}}},
+		}
+	}
+	cfg := &config.Config{
+		VendorConfig: config.VendorConfig{
+			Timeout: time.Second,
+			Vendors: []config.Vendor{
+				vendorConfig("linkmine", ts.vendorServer.URL+"/coupang/reco"),
+				vendorConfig("adpacker", ts.vendorServer.URL+"/adpacker/reco/{subid}"),
+				vendorConfig("broken", ts.vendorServer.URL+"/coupang/reco?mock_status=503"),
+				vendorConfig("empty", ts.vendorServer.URL+"/adpacker/reco?mock_empty=true"),
+			},
+		},
+		Grpc:   config.GrpcConfig{WriteBufferSizeKb: 32, ReadBufferSizeKb: 32},
+		Events: config.EventsConfig{Sink: "file", FlushInterval: 10 * time.Millisecond, File: config.EventFileConfig{Path: ts.eventsPath}},
+	}
+
+	var err error
+	ts.server, err = New(cfg)
+	ts.Require().NoError(err)
+	ts.listeners = Listeners{Gin: ts.listen(), GRPC: ts.listen(), Gateway: ts.listen()}
+	ts.Require().NoError(ts.server.Start(ts.listeners))
+
+	ts.grpcConn, err = grpc.NewClient(ts.listeners.GRPC.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	ts.Require().NoError(err)
+}
+
+func (ts *e2eTestSuite) TearDownSuite() {
+	_ = ts.grpcConn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ts.Require().NoError(ts.server.Stop(ctx))
+	ts.vendorServer.Close()
+}
+
+func (ts *e2eTestSuite) listen() net.Listener {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	ts.Require().NoError(err)
+	return lis
+}
+
+func (ts *e2eTestSuite) TestRecommendations() {
+	tests := []struct {
+		name      string
+		vendorKey string
+		want      result
+	}{
+		{
+			name:      "GIVEN a vendor with products THEN every server returns the same products",
+			vendorKey: "linkmine",
+			want: result{
+				products: []product{
+					{ID: "1", URL: "https://mock.test/products/1?click_id=e2e-click"},
+					{ID: "2", URL: "https://mock.test/products/2?click_id=e2e-click"},
+					{ID: "3", URL: "https://mock.test/products/3?click_id=e2e-click"},
+				},
+				httpCode: http.StatusOK,
+				grpcCode: codes.OK,
+			},
+		},
+		{
+			name:      "GIVEN a vendor with the subid in its path THEN every server returns the same products",
+			vendorKey: "adpacker",
+			want: result{
+				products: []product{
+					{ID: "1", URL: "https://mock.test/products/1?click_id=e2e-click"},
+					{ID: "2", URL: "https://mock.test/products/2?click_id=e2e-click"},
+					{ID: "3", URL: "https://mock.test/products/3?click_id=e2e-click"},
+				},
+				httpCode: http.StatusOK,
+				grpcCode: codes.OK,
+			},
+		},
+		{
+			name:      "GIVEN a failing vendor THEN every server returns an internal error",
+			vendorKey: "broken",
+			want:      result{httpCode: http.StatusInternalServerError, grpcCode: codes.Internal},
+		},
+		{
+			name:      "GIVEN a vendor without products THEN every server returns an internal error",
+			vendorKey: "empty",
+			want:      result{httpCode: http.StatusInternalServerError, grpcCode: codes.Internal},
+		},
+		{
+			name:      "GIVEN an unknown vendor THEN every server returns an invalid argument error",
+			vendorKey: "unknown",
+			want:      result{httpCode: http.StatusBadRequest, grpcCode: codes.InvalidArgument},
+		},
+	}
+	for _, tt := range tests {
+		ts.Run(tt.name, func() {
+			gin := ts.recommendGin(tt.vendorKey, "site-gin")
+			ts.Equal(tt.want.httpCode, gin.httpCode)
+			ts.Equal(tt.want.products, gin.products)
+
+			grpcResult := ts.recommendGRPC(tt.vendorKey, "site-grpc")
+			ts.Equal(tt.want.grpcCode, grpcResult.grpcCode)
+			ts.Equal(tt.want.products, grpcResult.products)
+
+			gateway := ts.recommendGateway(tt.vendorKey, "site-gateway")
+			ts.Equal(tt.want.httpCode, gateway.httpCode)
+			ts.Equal(tt.want.products, gateway.products)
+		})
+	}
+}
+
+// TestRequestInfo checks that x-rec-siteid and x-rec-oid reach the vendor metrics and the serve events
+// through every server
+func (ts *e2eTestSuite) TestRequestInfo() {
+	sites := map[string]func(vendorKey, siteID string) result{
+		"e2e-site-gin":     ts.recommendGin,
+		"e2e-site-grpc":    ts.recommendGRPC,
+		"e2e-site-gateway": ts.recommendGateway,
+	}
+	for siteID, recommend := range sites {
+		res := recommend("linkmine", siteID)
+		ts.Len(res.products, 3, siteID)
+		ts.GreaterOrEqual(ts.restAPIRequestCount("linkmine", siteID, e2eOID), uint64(1), siteID)
+	}
+
+	// the metrics endpoint exposes them as well
+	resp, err := http.Get("http://" + ts.listeners.Gin.Addr().String() + "/metrics")
+	ts.Require().NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	ts.Require().NoError(err)
+	for siteID := range sites {
+		ts.Contains(string(body), `oid="`+e2eOID+`",site="`+siteID+`",vendor="linkmine"`)
+	}
+
+	ts.Eventually(func() bool {
+		events := ts.readEvents()
+		for siteID := range sites {
+			if !containsServeEvent(events, "linkmine", siteID) {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func (ts *e2eTestSuite) recommendGin(vendorKey, siteID string) result {
+	return ts.recommendHTTP("http://"+ts.listeners.Gin.Addr().String()+"/r/"+vendorKey, siteID, func(body []byte) []product {
+		var products []vendor.ProductInfo
+		ts.Require().NoError(json.Unmarshal(body, &products))
+		res := make([]product, 0, len(products))
+		for _, p := range products {
+			res = append(res, product{ID: p.ProductID, URL: p.Url})
+		}
+		return res
+	})
+}
+
+// recommendGateway calls the REST route the gateway maps to GetRecommendations, the same /r/{vendor_key} as gin
+func (ts *e2eTestSuite) recommendGateway(vendorKey, siteID string) result {
+	return ts.recommendHTTP("http://"+ts.listeners.Gateway.Addr().String()+"/r/"+vendorKey, siteID, func(body []byte) []product {
+		resp := &schema.GetRecommendationsResponse{}
+		ts.Require().NoError(protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, resp))
+		return fromProto(resp)
+	})
+}
+
+func (ts *e2eTestSuite) recommendHTTP(target, siteID string, decode func([]byte) []product) result {
+	req, err := http.NewRequest(http.MethodGet, target+"?user_id="+e2eUserID+"&click_id=e2e-click&w=300&h=300&subid=e2e-subid", nil)
+	ts.Require().NoError(err)
+	req.Header.Set("x-rec-siteid", siteID)
+	req.Header.Set("x-rec-oid", e2eOID)
+
+	resp, err := http.DefaultClient.Do(req)
+	ts.Require().NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	ts.Require().NoError(err)
+
+	res := result{httpCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusOK {
+		res.products = decode(body)
+	}
+	return res
+}
+
+func (ts *e2eTestSuite) recommendGRPC(vendorKey, siteID string) result {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-rec-siteid", siteID, "x-rec-oid", e2eOID)
+
+	resp, err := schema.NewVendorAPIClient(ts.grpcConn).GetRecommendations(ctx, &schema.GetRecommendationsRequest{
+		VendorKey: vendorKey,
+		UserId:    e2eUserID,
+		ClickId:   "e2e-click",
+		W:         300,
+		H:         300,
+		Subid:     "e2e-subid",
+	})
+	if err != nil {
+		return result{grpcCode: status.Code(err)}
+	}
+	return result{products: fromProto(resp), grpcCode: codes.OK}
+}
+
+func fromProto(resp *schema.GetRecommendationsResponse) []product {
+	res := make([]product, 0, len(resp.Products))
+	for _, p := range resp.Products {
+		res = append(res, product{ID: p.ProductId, URL: p.Url})
+	}
+	return res
+}
+
+// restAPIRequestCount is the number of vendor calls observed by the rest_api_duration_seconds histogram
+func (ts *e2eTestSuite) restAPIRequestCount(vendorKey, siteID, oid string) uint64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	ts.Require().NoError(err)
+	for _, family := range families {
+		if !strings.HasSuffix(family.GetName(), "rest_api_duration_seconds") {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["vendor"] == vendorKey && labels["site"] == siteID && labels["oid"] == oid {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func (ts *e2eTestSuite) readEvents() []event.Event {
+	f, err := os.Open(ts.eventsPath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var events []event.Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev event.Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err == nil {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func containsServeEvent(events []event.Event, vendorKey, siteID string) bool {
+	for _, ev := range events {
+		if ev.Type == event.TypeServe && ev.Vendor == vendorKey && ev.SiteID == siteID && ev.OID == e2eOID &&
+			ev.Outcome == "ok" && len(ev.ProductIDs) == 3 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStartRequiresGRPCListenerForGateway(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	s := &Server{}
+	require.Error(t, s.Start(Listeners{Gateway: lis}))
+}
+
+func TestE2EAuth(t *testing.T) {
+	vendorServer := httptest.NewServer(mockvendor.NewServer(mockvendor.Config{Products: 3, ProductBaseURL: "https://mock.test"}))
+	defer vendorServer.Close()
+
+	cfg := &config.Config{
+		VendorConfig: config.VendorConfig{
+			Timeout: time.Second,
+			Vendors: []config.Vendor{{
+				Name:       "linkmine",
+				HTTPMethod: http.MethodGet,
+				Request:    config.URLPattern{URL: vendorServer.URL + "/coupang/reco", Queries: []config.Query{{Key: "adid", Value: "{user_id_lower}"}}},
+				Tracking:   config.URLPattern{URL: "{product_url}"},
+			}},
+		},
+		Grpc: config.GrpcConfig{WriteBufferSizeKb: 32, ReadBufferSizeKb: 32},
+		Auth: config.AuthConfig{Enabled: true, Callers: []config.AuthCaller{{Name: "bidder", APIKeys: []config.Secret{"bidder-key"}}}},
+	}
+	s, err := New(cfg)
+	require.NoError(t, err)
+	listen := func() net.Listener {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		return lis
+	}
+	listeners := Listeners{Gin: listen(), GRPC: listen(), Gateway: listen()}
+	require.NoError(t, s.Start(listeners))
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, s.Stop(ctx))
+	}()
+
+	httpCode := func(target, key string) int {
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		require.NoError(t, err)
+		if key != "" {
+			req.Header.Set("x-api-key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	for _, lis := range []net.Listener{listeners.Gin, listeners.Gateway} {
+		target := "http://" + lis.Addr().String() + "/r/linkmine?user_id=" + e2eUserID + "&click_id=e2e-click&w=300&h=300&subid=e2e-subid"
+		require.Equal(t, http.StatusUnauthorized, httpCode(target, ""), lis.Addr())
+		require.Equal(t, http.StatusUnauthorized, httpCode(target, "other-key"), lis.Addr())
+		require.Equal(t, http.StatusOK, httpCode(target, "bidder-key"), lis.Addr())
+	}
+	require.Equal(t, http.StatusOK, httpCode("http://"+listeners.Gin.Addr().String()+"/healthz", ""))
+
+	conn, err := grpc.NewClient(listeners.GRPC.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	recommend := func(key string) codes.Code {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", key)
+		}
+		_, err := schema.NewVendorAPIClient(conn).GetRecommendations(ctx, &schema.GetRecommendationsRequest{
+			VendorKey: "linkmine", UserId: e2eUserID, ClickId: "e2e-click", W: 300, H: 300, Subid: "e2e-subid",
+		})
+		return status.Code(err)
+	}
+	require.Equal(t, codes.Unauthenticated, recommend(""))
+	require.Equal(t, codes.OK, recommend("bidder-key"))
+}
@@ -0,0 +1,402 @@
+// Package server wires the vendor registry and the tracking endpoints into the gin, gRPC and gateway servers
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strings"
+
+	"rec-vendor-api/internal/auth"
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/constants"
+	"rec-vendor-api/internal/controller"
+	"rec-vendor-api/internal/event"
+	"rec-vendor-api/internal/fault"
+	"rec-vendor-api/internal/freqcap"
+	"rec-vendor-api/internal/middleware"
+	"rec-vendor-api/internal/strategy"
+	"rec-vendor-api/internal/strategy/url"
+	"rec-vendor-api/internal/telemetry"
+	"rec-vendor-api/internal/vendor"
+
+	grpc_request_info "rec-vendor-api/internal/middleware/grpc_request_info"
+
+	"github.com/gin-gonic/gin"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	grpc_realip "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/realip"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/plaxieappier/rec-go-kit/tracekit"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+
+	schema "github.com/plaxieappier/rec-schema/go/vendorapi"
+)
+
+var headerMatcher = map[string]struct{}{
+	grpc_request_info.HeaderRequester:   {},
+	grpc_request_info.HeaderSiteID:      {},
+	grpc_request_info.HeaderBidObjID:    {},
+	grpc_request_info.HeaderOID:         {},
+	grpc_request_info.HeaderReqID:       {},
+	grpc_request_info.HeaderRequestTs:   {},
+	grpc_request_info.HeaderTraceparent: {},
+	fault.Header:                        {},
+}
+
+// Listeners of the servers to start, a nil listener leaves its server stopped. The gateway forwards to the
+// gRPC server, so it requires the gRPC listener.
+type Listeners struct {
+	Gin     net.Listener
+	GRPC    net.Listener
+	Gateway net.Listener
+}
+
+// Server holds the gin, gRPC and gateway servers and the resources they share
+type Server struct {
+	cfg             *config.Config
+	eventEmitter    event.Emitter
+	postProcessDeps strategy.PostProcessDeps
+	vendorRegistry  map[string]vendor.Client
+	trackers        *trackingHandlers
+	authenticator   *auth.Authenticator
+	grpcTLS         *tls.Config
+
+	ginServer     *http.Server
+	grpcServer    *grpc.Server
+	gatewayServer *http.Server
+	cancelGateway context.CancelFunc
+	errs          chan error
+}
+
+// trackingHandlers are the first-party tracking endpoints, served by both the gin and the gateway servers.
+// The click and impression endpoints are only set if first-party tracking is configured.
+type trackingHandlers struct {
+	click      *controller.ClickTracker
+	impression *controller.ImpressionTracker
+	postback   *controller.PostbackReceiver
+}
+
+func New(cfg *config.Config) (*Server, error) {
+	eventEmitter, err := event.NewEmitter(cfg.Events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build event emitter: %w", err)
+	}
+	s := &Server{cfg: cfg, eventEmitter: eventEmitter, errs: make(chan error, 3)}
+	if err := s.init(); err != nil {
+		s.postProcessDeps.Close()
+		_ = eventEmitter.Close(context.Background())
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) init() error {
+	var err error
+	s.postProcessDeps, err = vendor.BuildPostProcessDeps(s.cfg.VendorConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build post-process dependencies: %w", err)
+	}
+	if err := s.cfg.ValidateFaultInjection(); err != nil {
+		return err
+	}
+	injector, err := vendor.BuildFaultInjector(s.cfg.FaultInjection)
+	if err != nil {
+		return fmt.Errorf("failed to build fault injector: %w", err)
+	}
+	if injector != nil {
+		log.Warnf("Fault injection is enabled with %d rules, header-driven faults allowed: %t", len(s.cfg.FaultInjection.Rules), injector.AllowHeader())
+	}
+	s.vendorRegistry, err = vendor.BuildRegistry(s.cfg.VendorConfig, s.postProcessDeps, s.eventEmitter, injector)
+	if err != nil {
+		return fmt.Errorf("failed to build vendor registry: %w", err)
+	}
+
+	sealer, err := vendor.NewClickSealer(s.cfg.VendorConfig.Click)
+	if err != nil {
+		return fmt.Errorf("failed to build click sealer: %w", err)
+	}
+	s.trackers = &trackingHandlers{}
+	if s.cfg.VendorConfig.Click.BaseURL != "" {
+		signer := vendor.NewClickSigner(s.cfg.VendorConfig.Click)
+		var freqCap *freqcap.Capper
+		if s.postProcessDeps.FreqCapOnImpression {
+			freqCap = s.postProcessDeps.FreqCap
+		}
+		s.trackers.click = controller.NewClickTracker(signer, sealer, s.eventEmitter)
+		impressionDeduper, err := vendor.NewImpressionDeduper(s.cfg.VendorConfig)
+		if err != nil {
+			return fmt.Errorf("failed to build impression deduper: %w", err)
+		}
+		s.trackers.impression = controller.NewImpressionTracker(signer, freqCap, impressionDeduper, s.eventEmitter)
+	}
+	s.trackers.postback, err = controller.NewPostbackReceiver(s.cfg.VendorConfig, sealer, s.eventEmitter)
+	if err != nil {
+		return fmt.Errorf("failed to build postback receiver: %w", err)
+	}
+
+	s.authenticator, err = auth.New(s.cfg.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to build authenticator: %w", err)
+	}
+	s.grpcTLS, err = loadGRPCTLS(s.cfg.Grpc.TLS)
+	return err
+}
+
+// Start serves on the given listeners in the background, the serve errors are sent to Errors
+func (s *Server) Start(lis Listeners) error {
+	if lis.Gateway != nil && lis.GRPC == nil {
+		return errors.New("the gateway server requires the gRPC listener")
+	}
+	if lis.Gin != nil {
+		s.startGinServer(lis.Gin)
+	}
+	if lis.GRPC != nil {
+		s.startGRPCServer(lis.GRPC)
+	}
+	if lis.Gateway != nil {
+		if err := s.startGatewayServer(lis.GRPC.Addr().String(), lis.Gateway); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) Errors() <-chan error {
+	return s.errs
+}
+
+// Stop gracefully stops the servers, then stops the background work of the stores and flushes the events. The gRPC server is stopped forcefully once the
+// context is done.
+func (s *Server) Stop(ctx context.Context) error {
+	var errs []error
+	if s.gatewayServer != nil {
+		if err := s.gatewayServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shutdown gateway server: %w", err))
+		}
+		s.cancelGateway()
+	}
+	if s.grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			s.grpcServer.Stop()
+			errs = append(errs, fmt.Errorf("failed to gracefully stop grpc server: %w", ctx.Err()))
+		}
+	}
+	if s.ginServer != nil {
+		if err := s.ginServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shutdown gin server: %w", err))
+		}
+	}
+	if s.trackers.impression != nil {
+		s.trackers.impression.Close()
+	}
+	s.trackers.postback.Close()
+	s.postProcessDeps.Close()
+	if err := s.eventEmitter.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush events: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// allowFaultHeader reports whether a request can set its own vendor fault with the fault header
+func (s *Server) allowFaultHeader() bool {
+	return s.cfg.FaultInjection.Enabled && s.cfg.FaultInjection.AllowHeader
+}
+
+func (s *Server) startGinServer(lis net.Listener) {
+	log.Infof("Starting gin server on %s", lis.Addr())
+	r := gin.New()
+	// MUST be set to true for getting value from context
+	r.ContextWithFallback = true
+
+	r.Use(middleware.RequestInfo())
+	if s.allowFaultHeader() {
+		r.Use(middleware.FaultInjection())
+	}
+
+	if s.cfg.EnableGinLogger {
+		r.Use(gin.Logger())
+	}
+
+	if s.cfg.Logging.Format == "json" {
+		r.Use(gin.RecoveryWithWriter(io.Discard, jsonRecoveryHandler))
+	} else {
+		r.Use(gin.Recovery())
+	}
+
+	recommender := controller.NewRecommender(s.vendorRegistry, s.cfg.OpenRTB)
+	vendorManager := controller.NewVendorManager(s.cfg.VendorConfig, s.authenticator)
+
+	// the tracking endpoints are called by browsers and vendors, they are authenticated by their token or secret
+	api := r.Group("/")
+	if s.authenticator != nil {
+		api.Use(middleware.Auth(s.authenticator))
+	}
+	api.GET("/r/:vendor_key", recommender.Recommend)
+	api.GET("/vendors", vendorManager.GetVendors)
+	api.GET("/healthz", controller.HealthCheck)
+	api.GET("/metrics", telemetry.PromHandler())
+	if s.trackers.click != nil {
+		r.GET("/c/:token", gin.WrapH(s.trackers.click))
+		r.GET("/i/:token", gin.WrapH(s.trackers.impression))
+	}
+	r.GET("/pb/:vendor_key", gin.WrapH(s.trackers.postback))
+	r.POST("/pb/:vendor_key", gin.WrapH(s.trackers.postback))
+
+	s.ginServer = &http.Server{
+		Handler: tracekit.OtelHTTPHandler(r, "vendor-api", s.cfg.Tracing),
+	}
+	go func() {
+		if err := s.ginServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- fmt.Errorf("failed to serve gin server on %s: %w", lis.Addr(), err)
+		}
+	}()
+}
+
+func (s *Server) startGRPCServer(lis net.Listener) {
+	log.Infof("Starting grpc server on %s", lis.Addr())
+	// the handler never fails to build
+	handler, _ := controller.NewHandler(s.vendorRegistry, s.cfg.VendorConfig, s.authenticator)
+
+	// Trust all proxies to use X-Forwarded-For header
+	// since we do not know the client's IP address, we trust all proxies.
+	trustedPeers := []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/0"),
+		netip.MustParsePrefix("::/0"),
+	}
+
+	interceptors := []grpc.UnaryServerInterceptor{
+		grpc_recovery.UnaryServerInterceptor(getRecoveryOpts()...),
+		middleware.ValidationUnaryInterceptor,
+		grpc_realip.UnaryServerInterceptor(trustedPeers, []string{grpc_realip.XForwardedFor}),
+		grpc_request_info.UnaryServerInterceptor(),
+	}
+	if s.authenticator != nil {
+		interceptors = append(interceptors, middleware.AuthUnaryInterceptor(s.authenticator))
+	}
+	if s.allowFaultHeader() {
+		interceptors = append(interceptors, middleware.FaultUnaryInterceptor)
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(
+			getOtelOpts()...,
+		)),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionAge: s.cfg.Grpc.MaxConnectionAge,
+		}),
+		grpc.WriteBufferSize(s.cfg.Grpc.WriteBufferSizeKb * 1024),
+		grpc.ReadBufferSize(s.cfg.Grpc.ReadBufferSizeKb * 1024),
+	}
+	if s.grpcTLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.grpcTLS)))
+	}
+	s.grpcServer = grpc.NewServer(opts...)
+	schema.RegisterVendorAPIServer(s.grpcServer, handler)
+	reflection.Register(s.grpcServer)
+
+	go func() {
+		log.Infof("Serving gRPC server on %v", lis.Addr())
+		if err := s.grpcServer.Serve(lis); err != nil {
+			s.errs <- fmt.Errorf("failed to serve gRPC server on %s: %w", lis.Addr(), err)
+		}
+	}()
+}
+
+func (s *Server) startGatewayServer(grpcAddr string, lis net.Listener) error {
+	log.Infof("Starting gateway server on %s", lis.Addr())
+	gatewayMux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		// the metadata keys are lowercase, the header keys canonical
+		lower := strings.ToLower(key)
+		if _, ok := headerMatcher[lower]; ok {
+			return lower, true
+		}
+		if s.authenticator != nil && lower == s.authenticator.Header() {
+			return lower, true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}))
+	// the connection to the gRPC server is closed once the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	gatewayCreds := insecure.NewCredentials()
+	if s.grpcTLS != nil {
+		// the gateway dials the gRPC server of this process, without client certificate: its callers are
+		// authenticated by the API key header
+		gatewayCreds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	}
+	gatewayOpts := []grpc.DialOption{grpc.WithTransportCredentials(gatewayCreds)}
+	if err := schema.RegisterVendorAPIHandlerFromEndpoint(ctx, gatewayMux, grpcAddr, gatewayOpts); err != nil {
+		cancel()
+		return fmt.Errorf("failed to register gRPC gateway: %w", err)
+	}
+	s.cancelGateway = cancel
+
+	mux := http.NewServeMux()
+	mux.Handle("/", gatewayMux)
+	if s.trackers.click != nil {
+		mux.Handle(url.ClickPath, s.trackers.click)
+		mux.Handle(url.ImpressionPath, s.trackers.impression)
+	}
+	mux.Handle(controller.PostbackPath, s.trackers.postback)
+	s.gatewayServer = &http.Server{
+		Handler: mux,
+	}
+
+	go func() {
+		log.Infof("Serving gateway server on %v", lis.Addr())
+		if err := s.gatewayServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- fmt.Errorf("failed to serve gateway server on %s: %w", lis.Addr(), err)
+		}
+	}()
+	return nil
+}
+
+func jsonRecoveryHandler(ctx *gin.Context, recovered any) {
+	log.WithContext(ctx).WithField("stack", string(debug.Stack())).Error(fmt.Sprintf("%v", recovered))
+	ctx.AbortWithStatus(http.StatusInternalServerError)
+}
+
+func getOtelOpts() []otelgrpc.Option {
+	return []otelgrpc.Option{
+		otelgrpc.WithFilter(func(info *stats.RPCTagInfo) bool {
+			switch info.FullMethodName {
+			case constants.FullMethodHealthCheck:
+				return false
+			default:
+				return true
+			}
+		}),
+		otelgrpc.WithMeterProvider(noop.NewMeterProvider()), // disable meters since we collect metrics with prometheus
+	}
+}
+
+func getRecoveryOpts() []grpc_recovery.Option {
+	return []grpc_recovery.Option{
+		grpc_recovery.WithRecoveryHandler(func(p any) (err error) {
+			return status.Errorf(codes.Internal, "panic triggered: %v", p)
+		}),
+	}
+}
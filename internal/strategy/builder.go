@@ -1,34 +1,53 @@
 package strategy
 
 import (
+	"fmt"
+
+	"rec-vendor-api/internal/blocklist"
+	"rec-vendor-api/internal/clickseal"
 	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/freqcap"
 	"rec-vendor-api/internal/strategy/body"
 	"rec-vendor-api/internal/strategy/header"
+	"rec-vendor-api/internal/strategy/postprocess"
+	"rec-vendor-api/internal/strategy/ranker"
 	"rec-vendor-api/internal/strategy/unmarshaler"
 	"rec-vendor-api/internal/strategy/url"
+	"rec-vendor-api/internal/tracktoken"
+	"rec-vendor-api/internal/urlpolicy"
 )
 
 func BuildHeader(v config.Vendor) header.Strategy {
+	return BuildHeaderWithClock(v, &header.ClockImpl{})
+}
+
+// BuildHeaderWithClock builds the header strategy with the clock of its signatures
+func BuildHeaderWithClock(v config.Vendor, clock header.Clock) header.Strategy {
 	switch v.Name {
 	case "replace":
-		return &header.ReplaceHeader{AccessKey: v.AccessKey, SecretKey: v.SecretKey, Clock: &header.ClockImpl{}}
+		return &header.ReplaceHeader{AccessKey: v.AccessKey.Value(), SecretKey: v.SecretKey.Value(), Clock: clock}
 	case "adpopcorn":
 		return &header.AdpopcornHeader{UserAgent: v.UserAgent}
 	case "keeta":
-		return &header.KeetaHeader{SCaApp: v.SCaApp, SCaSecret: v.SCaSecret, Clock: &header.ClockImpl{}}
+		return &header.KeetaHeader{SCaApp: v.SCaApp.Value(), SCaSecret: v.SCaSecret.Value(), Clock: clock}
 	default:
 		return &header.NoHeader{}
 	}
 }
 
-func BuildRequest(v config.Vendor) url.Strategy {
+// BuildRequest builds the request URL strategy, the sealer encrypts the {click_id_sealed} macro
+func BuildRequest(v config.Vendor, sealer *clickseal.Sealer) url.Strategy {
 	switch v.Name {
 	default:
-		return &url.Default{}
+		return &url.Default{Sealer: sealer}
 	}
 }
 
 func BuildUnmarshaler(v config.Vendor) unmarshaler.Strategy {
+	// a response mapping in the config takes precedence over the unmarshaler of the vendor name
+	if v.Response.ID != "" {
+		return &unmarshaler.Mapping{Response: v.Response}
+	}
 	switch v.Name {
 	case "adpopcorn":
 		return &unmarshaler.WrappedCoupangPartner{}
@@ -45,11 +64,52 @@ func BuildUnmarshaler(v config.Vendor) unmarshaler.Strategy {
 	}
 }
 
-func BuildTracking(v config.Vendor) url.Strategy {
+// HasTitle reports whether the unmarshaler of the vendor sets the product titles, the keeta responses have none
+func HasTitle(v config.Vendor) bool {
+	if v.Response.ID != "" {
+		return v.Response.Title != ""
+	}
+	return v.Name != "keeta"
+}
+
+// TrackingDeps holds the tracking resources shared by all vendors
+type TrackingDeps struct {
+	ClickSigner  *tracktoken.Signer
+	ClickBaseURL string
+	// ClickSealer encrypts the {click_id_sealed} macro and the click ID of the first-party click tokens
+	ClickSealer *clickseal.Sealer
+}
+
+func BuildTracking(v config.Vendor, deps TrackingDeps) (url.Strategy, error) {
+	var tracking url.Strategy
 	switch v.Name {
 	default:
-		return &url.Default{}
+		tracking = &url.Default{Sealer: deps.ClickSealer}
+	}
+
+	if !v.FirstPartyClick {
+		return tracking, nil
+	}
+	if deps.ClickSigner == nil {
+		return nil, fmt.Errorf("vendor %s: first_party_click requires click.base_url and click.signing_key", v.Name)
+	}
+	return &url.FirstPartyClick{
+		Tracking:   tracking,
+		Signer:     deps.ClickSigner,
+		Sealer:     deps.ClickSealer,
+		BaseURL:    deps.ClickBaseURL,
+		VendorName: v.Name,
+	}, nil
+}
+
+func BuildImpression(v config.Vendor, deps TrackingDeps) (url.Strategy, error) {
+	if !v.ImpressionURL {
+		return &url.NoURL{}, nil
 	}
+	if deps.ClickSigner == nil {
+		return nil, fmt.Errorf("vendor %s: impression_url requires click.base_url and click.signing_key", v.Name)
+	}
+	return &url.ImpressionBeacon{Signer: deps.ClickSigner, BaseURL: deps.ClickBaseURL, VendorName: v.Name}, nil
 }
 
 func BuildBody(v config.Vendor) body.Strategy {
@@ -60,3 +120,100 @@ func BuildBody(v config.Vendor) body.Strategy {
 		return &body.NoBody{}
 	}
 }
+
+// PostProcessDeps holds the post-processing resources shared by all vendors
+type PostProcessDeps struct {
+	URLPolicy *urlpolicy.Policy
+	Blocklist *blocklist.Store
+	FreqCap   *freqcap.Capper
+	// frequency capping is recorded by the impression beacon instead of on serve
+	FreqCapOnImpression bool
+}
+
+// Close stops the reloading of the blocklists and the sweeping of the frequency cap store
+func (d PostProcessDeps) Close() {
+	if d.Blocklist != nil {
+		d.Blocklist.Close()
+	}
+	if d.FreqCap != nil {
+		d.FreqCap.Close()
+	}
+}
+
+func BuildPostProcess(v config.Vendor, deps PostProcessDeps) postprocess.Strategy {
+	stages := make([]postprocess.Stage, 0, len(v.PostProcess.Stages)+3)
+	// the URL policy, brand-safety blocklists and frequency capping apply to every vendor, before any configured stage
+	if deps.URLPolicy != nil {
+		stages = append(stages, &postprocess.URLPolicy{
+			Policy:       deps.URLPolicy,
+			ProductURL:   urlpolicy.Rule{Schemes: v.URLAllowlist.Schemes, Domains: v.URLAllowlist.Domains},
+			ProductImage: urlpolicy.Rule{Schemes: urlpolicy.WebSchemes(v.URLAllowlist.Schemes), Domains: v.URLAllowlist.ImageDomains},
+		})
+	}
+	if deps.Blocklist != nil {
+		stages = append(stages, &postprocess.Blocklist{Store: deps.Blocklist})
+	}
+	if deps.FreqCap != nil {
+		stages = append(stages, &postprocess.FreqCap{Capper: deps.FreqCap, RecordOnImpression: deps.FreqCapOnImpression})
+	}
+	for _, stage := range v.PostProcess.Stages {
+		switch stage.Type {
+		case "dedupe":
+			stages = append(stages, &postprocess.Dedupe{})
+		case "required_fields":
+			stages = append(stages, &postprocess.RequiredFields{Fields: stage.Fields})
+		case "force_https":
+			stages = append(stages, &postprocess.ForceHTTPS{})
+		}
+	}
+	return &postprocess.Pipeline{Stages: stages, MinItems: v.PostProcess.MinItems}
+}
+
+// BuildMaxItems returns the max_items stage of the vendor, with no limit if there is none. It is left out of the
+// pipeline and applied by the vendor client after ranking, so that the ranker picks the items kept.
+func BuildMaxItems(v config.Vendor) *postprocess.MaxItems {
+	maxItems := &postprocess.MaxItems{}
+	for _, stage := range v.PostProcess.Stages {
+		if stage.Type == "max_items" {
+			maxItems.Limit = stage.Limit
+		}
+	}
+	return maxItems
+}
+
+func BuildRanker(v config.Vendor) (ranker.Strategy, error) {
+	if len(v.Ranker.Arms) == 0 {
+		return buildRankerSpec(v.Ranker.RankerSpec)
+	}
+
+	arms := make([]ranker.Arm, 0, len(v.Ranker.Arms))
+	for _, arm := range v.Ranker.Arms {
+		strategy, err := buildRankerSpec(arm.RankerSpec)
+		if err != nil {
+			return nil, fmt.Errorf("vendor %s, ranker arm %s: %w", v.Name, arm.Name, err)
+		}
+		arms = append(arms, ranker.Arm{Name: arm.Name, Weight: arm.Weight, Strategy: strategy})
+	}
+	return &ranker.Experiment{Arms: arms}, nil
+}
+
+func buildRankerSpec(spec config.RankerSpec) (ranker.Strategy, error) {
+	switch spec.Type {
+	case "price_asc":
+		return &ranker.Price{}, nil
+	case "price_desc":
+		return &ranker.Price{Descending: true}, nil
+	case "discount_first":
+		return &ranker.DiscountFirst{}, nil
+	case "shuffle":
+		return &ranker.Shuffle{}, nil
+	case "weighted_score":
+		scores, err := ranker.LoadScores(spec.ScoreFile)
+		if err != nil {
+			return nil, err
+		}
+		return &ranker.WeightedScore{Scores: scores}, nil
+	default:
+		return &ranker.VendorOrder{}, nil
+	}
+}
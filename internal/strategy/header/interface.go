@@ -32,3 +32,16 @@ func (ClockImpl) getDatetimeGMT() string {
 func (ClockImpl) getCurrentMilliTimestamp() string {
 	return strconv.FormatInt(time.Now().UnixMilli(), 10)
 }
+
+// FixedClock always returns the same time, so that the signatures are reproducible, e.g. in config previews
+type FixedClock struct {
+	Time time.Time
+}
+
+func (c FixedClock) getDatetimeGMT() string {
+	return c.Time.UTC().Format("060102T150405Z")
+}
+
+func (c FixedClock) getCurrentMilliTimestamp() string {
+	return strconv.FormatInt(c.Time.UnixMilli(), 10)
+}
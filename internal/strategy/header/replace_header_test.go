@@ -2,6 +2,7 @@ package header
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -39,3 +40,19 @@ func (s *replaceHeaderTestSuite) TestGenerateHeaders() {
 	wantedSignature := "CEA algorithm=HmacSHA256, access-key=access_key, signed-date=250707T103117Z, signature=faf13b58f6cc013892a036b778465bdcb85326d418c11398139a0b80ade01624"
 	require.Equal(s.T(), map[string]string{"Authorization": wantedSignature}, result)
 }
+
+func (s *replaceHeaderTestSuite) TestGenerateHeadersWithFixedClock() {
+	header := &ReplaceHeader{
+		SecretKey: "secret_key",
+		AccessKey: "access_key",
+		Clock:     FixedClock{Time: time.Date(2025, 7, 7, 10, 31, 17, 0, time.UTC)},
+	}
+
+	result := header.GenerateHeaders(Params{
+		RequestURL: "https://api-gateway.coupang.com/v2/providers/affiliate_open_api/apis/openapi/v2/products/reco",
+		HTTPMethod: "POST",
+	})
+
+	wantedSignature := "CEA algorithm=HmacSHA256, access-key=access_key, signed-date=250707T103117Z, signature=faf13b58f6cc013892a036b778465bdcb85326d418c11398139a0b80ade01624"
+	require.Equal(s.T(), map[string]string{"Authorization": wantedSignature}, result)
+}
@@ -0,0 +1,35 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/blocklist"
+	"rec-vendor-api/internal/strategy/unmarshaler"
+	"rec-vendor-api/internal/telemetry"
+)
+
+// Blocklist drops the items matched by the blocklists scoped to the vendor and the request site
+type Blocklist struct {
+	Store *blocklist.Store
+}
+
+func (s *Blocklist) Name() string {
+	return "blocklist"
+}
+
+func (s *Blocklist) Apply(_ context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := make([]unmarshaler.PartnerResp, 0, len(items))
+	for _, item := range items {
+		reason, blocked := s.Store.Match(params.VendorName, params.SiteID, blocklist.Item{
+			ProductID:  item.ProductID,
+			LandingURL: item.ProductURL,
+			Title:      item.ProductTitle,
+		})
+		if blocked {
+			telemetry.Metrics.BlocklistFilteredTotal.WithLabelValues(params.VendorName, params.SiteID, reason).Inc()
+			continue
+		}
+		res = append(res, item)
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+package postprocess
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rec-vendor-api/internal/blocklist"
+	"rec-vendor-api/internal/strategy/unmarshaler"
+	"rec-vendor-api/internal/telemetry"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+product_ids: ["2"]
+domains: ["blocked.com"]
+keywords: ["casino"]
+`), 0o600))
+	store, err := blocklist.NewStore([]blocklist.Source{{Path: path}}, 0)
+	require.NoError(t, err)
+	defer store.Close()
+
+	stage := &Blocklist{Store: store}
+	params := Params{VendorName: "blocklist_vendor", SiteID: "blocklist_site"}
+	got := stage.Apply(context.Background(), params, []unmarshaler.PartnerResp{
+		{ProductID: "1", ProductURL: "https://ok.com/1"},
+		{ProductID: "2", ProductURL: "https://ok.com/2"},
+		{ProductID: "3", ProductURL: "https://blocked.com/3"},
+		{ProductID: "4", ProductURL: "https://ok.com/4", ProductTitle: "Casino night"},
+	})
+
+	require.Equal(t, []unmarshaler.PartnerResp{{ProductID: "1", ProductURL: "https://ok.com/1"}}, got)
+	for _, reason := range []string{blocklist.ReasonProductID, blocklist.ReasonDomain, blocklist.ReasonKeyword} {
+		metric := &dto.Metric{}
+		require.NoError(t, telemetry.Metrics.BlocklistFilteredTotal.WithLabelValues("blocklist_vendor", "blocklist_site", reason).Write(metric))
+		require.Equal(t, float64(1), metric.GetCounter().GetValue(), reason)
+	}
+}
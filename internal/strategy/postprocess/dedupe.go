@@ -0,0 +1,27 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// Dedupe keeps the first occurrence of each product ID
+type Dedupe struct{}
+
+func (s *Dedupe) Name() string {
+	return "dedupe"
+}
+
+func (s *Dedupe) Apply(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	seen := make(map[string]struct{}, len(items))
+	res := make([]unmarshaler.PartnerResp, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.ProductID]; ok {
+			continue
+		}
+		seen[item.ProductID] = struct{}{}
+		res = append(res, item)
+	}
+	return res
+}
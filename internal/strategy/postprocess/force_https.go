@@ -0,0 +1,55 @@
+package postprocess
+
+import (
+	"context"
+	urlpkg "net/url"
+	"strings"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// ForceHTTPS upgrades http product and image URLs to https.
+// Items whose URLs cannot be parsed or use any other scheme are dropped.
+type ForceHTTPS struct{}
+
+func (s *ForceHTTPS) Name() string {
+	return "force_https"
+}
+
+func (s *ForceHTTPS) Apply(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := make([]unmarshaler.PartnerResp, 0, len(items))
+	for _, item := range items {
+		productURL, ok := toHTTPS(item.ProductURL)
+		if !ok {
+			continue
+		}
+		imageURL, ok := toHTTPS(item.ProductImage)
+		if !ok {
+			continue
+		}
+		item.ProductURL = productURL
+		item.ProductImage = imageURL
+		res = append(res, item)
+	}
+	return res
+}
+
+// toHTTPS leaves empty values untouched, missing fields are handled by RequiredFields
+func toHTTPS(rawURL string) (string, bool) {
+	if rawURL == "" {
+		return "", true
+	}
+	parsedURL, err := urlpkg.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(parsedURL.Scheme) {
+	case "https":
+		return rawURL, true
+	case "http":
+		parsedURL.Scheme = "https"
+		return parsedURL.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,53 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/freqcap"
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// FreqCap drops the products already served to the user too many times, and records the served ones
+// unless the impressions are recorded by the impression beacon
+type FreqCap struct {
+	Capper             *freqcap.Capper
+	RecordOnImpression bool
+}
+
+func (s *FreqCap) Name() string {
+	return "freq_cap"
+}
+
+func (s *FreqCap) Apply(ctx context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	allowed := s.Capper.Allowed(ctx, params.VendorName, params.SiteID, params.UserID, productIDs(items))
+	if len(allowed) == len(items) {
+		return items
+	}
+
+	allowedSet := make(map[string]struct{}, len(allowed))
+	for _, id := range allowed {
+		allowedSet[id] = struct{}{}
+	}
+	res := make([]unmarshaler.PartnerResp, 0, len(allowed))
+	for _, item := range items {
+		if _, ok := allowedSet[item.ProductID]; ok {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func (s *FreqCap) Record(ctx context.Context, params Params, served []unmarshaler.PartnerResp) {
+	if s.RecordOnImpression {
+		return
+	}
+	s.Capper.Record(ctx, params.VendorName, params.SiteID, params.UserID, productIDs(served))
+}
+
+func productIDs(items []unmarshaler.PartnerResp) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
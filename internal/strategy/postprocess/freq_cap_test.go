@@ -0,0 +1,60 @@
+package postprocess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/freqcap"
+	"rec-vendor-api/internal/strategy/unmarshaler"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreqCap(t *testing.T) {
+	ctx := context.Background()
+	store := freqcap.NewMemoryStore(0, 0)
+	defer store.Close()
+	stage := &FreqCap{Capper: freqcap.NewCapper(store, []freqcap.Rule{{MaxImpressions: 1, Window: time.Hour}})}
+	pipeline := &Pipeline{Stages: []Stage{stage, &MaxItems{Limit: 1}}}
+	params := Params{VendorName: "freq_cap_vendor", UserID: "u1"}
+	items := []unmarshaler.PartnerResp{{ProductID: "1"}, {ProductID: "2"}}
+
+	got, err := pipeline.Process(ctx, params, items)
+	require.NoError(t, err)
+	require.Equal(t, []unmarshaler.PartnerResp{{ProductID: "1"}}, got)
+	pipeline.Record(ctx, params, got)
+
+	// only the served product is capped
+	got, err = pipeline.Process(ctx, params, items)
+	require.NoError(t, err)
+	require.Equal(t, []unmarshaler.PartnerResp{{ProductID: "2"}}, got)
+	pipeline.Record(ctx, params, got)
+
+	got, err = pipeline.Process(ctx, params, items)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	// other users are not affected
+	got, err = pipeline.Process(ctx, Params{VendorName: "freq_cap_vendor", UserID: "u2"}, items)
+	require.NoError(t, err)
+	require.Equal(t, []unmarshaler.PartnerResp{{ProductID: "1"}}, got)
+}
+
+func TestFreqCapRecordOnImpression(t *testing.T) {
+	ctx := context.Background()
+	store := freqcap.NewMemoryStore(0, 0)
+	defer store.Close()
+	capper := freqcap.NewCapper(store, []freqcap.Rule{{MaxImpressions: 1, Window: time.Hour}})
+	stage := &FreqCap{Capper: capper, RecordOnImpression: true}
+	params := Params{VendorName: "freq_cap_vendor", UserID: "u1"}
+	items := []unmarshaler.PartnerResp{{ProductID: "1"}, {ProductID: "2"}}
+
+	// serving does not count against the cap
+	stage.Record(ctx, params, items)
+	require.Equal(t, items, stage.Apply(ctx, params, items))
+
+	// the impression beacon does
+	capper.Record(ctx, params.VendorName, params.SiteID, params.UserID, []string{"1"})
+	require.Equal(t, []unmarshaler.PartnerResp{{ProductID: "2"}}, stage.Apply(ctx, params, items))
+}
@@ -0,0 +1,33 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+type Params struct {
+	VendorName string
+	UserID     string
+	SiteID     string
+}
+
+//go:generate mockgen -source=./interface.go -destination=./interface_mock.go -package=postprocess
+
+// Strategy post-processes the products returned by the unmarshaler before tracking URLs are generated.
+// Record is called with the products that were finally served.
+type Strategy interface {
+	Process(ctx context.Context, params Params, items []unmarshaler.PartnerResp) ([]unmarshaler.PartnerResp, error)
+	Record(ctx context.Context, params Params, served []unmarshaler.PartnerResp)
+}
+
+// Stage is a single step of a Pipeline. Implementations return the items to keep, in order.
+type Stage interface {
+	Name() string
+	Apply(ctx context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp
+}
+
+// Recorder is implemented by stages that need to know which items were finally served
+type Recorder interface {
+	Record(ctx context.Context, params Params, served []unmarshaler.PartnerResp)
+}
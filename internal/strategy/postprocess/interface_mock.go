@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./interface.go -destination=./interface_mock.go -package=postprocess
+//
+
+// Package postprocess is a generated GoMock package.
+package postprocess
+
+import (
+	context "context"
+	unmarshaler "rec-vendor-api/internal/strategy/unmarshaler"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStrategy is a mock of Strategy interface.
+type MockStrategy struct {
+	ctrl     *gomock.Controller
+	recorder *MockStrategyMockRecorder
+}
+
+// MockStrategyMockRecorder is the mock recorder for MockStrategy.
+type MockStrategyMockRecorder struct {
+	mock *MockStrategy
+}
+
+// NewMockStrategy creates a new mock instance.
+func NewMockStrategy(ctrl *gomock.Controller) *MockStrategy {
+	mock := &MockStrategy{ctrl: ctrl}
+	mock.recorder = &MockStrategyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStrategy) EXPECT() *MockStrategyMockRecorder {
+	return m.recorder
+}
+
+// Process mocks base method.
+func (m *MockStrategy) Process(ctx context.Context, params Params, items []unmarshaler.PartnerResp) ([]unmarshaler.PartnerResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", ctx, params, items)
+	ret0, _ := ret[0].([]unmarshaler.PartnerResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockStrategyMockRecorder) Process(ctx, params, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockStrategy)(nil).Process), ctx, params, items)
+}
+
+// Record mocks base method.
+func (m *MockStrategy) Record(ctx context.Context, params Params, served []unmarshaler.PartnerResp) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, params, served)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockStrategyMockRecorder) Record(ctx, params, served any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockStrategy)(nil).Record), ctx, params, served)
+}
+
+// MockStage is a mock of Stage interface.
+type MockStage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStageMockRecorder
+}
+
+// MockStageMockRecorder is the mock recorder for MockStage.
+type MockStageMockRecorder struct {
+	mock *MockStage
+}
+
+// NewMockStage creates a new mock instance.
+func NewMockStage(ctrl *gomock.Controller) *MockStage {
+	mock := &MockStage{ctrl: ctrl}
+	mock.recorder = &MockStageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStage) EXPECT() *MockStageMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockStage) Apply(ctx context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, params, items)
+	ret0, _ := ret[0].([]unmarshaler.PartnerResp)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockStageMockRecorder) Apply(ctx, params, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockStage)(nil).Apply), ctx, params, items)
+}
+
+// Name mocks base method.
+func (m *MockStage) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockStageMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockStage)(nil).Name))
+}
+
+// MockRecorder is a mock of Recorder interface.
+type MockRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecorderMockRecorder
+}
+
+// MockRecorderMockRecorder is the mock recorder for MockRecorder.
+type MockRecorderMockRecorder struct {
+	mock *MockRecorder
+}
+
+// NewMockRecorder creates a new mock instance.
+func NewMockRecorder(ctrl *gomock.Controller) *MockRecorder {
+	mock := &MockRecorder{ctrl: ctrl}
+	mock.recorder = &MockRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecorder) EXPECT() *MockRecorderMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockRecorder) Record(ctx context.Context, params Params, served []unmarshaler.PartnerResp) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, params, served)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockRecorderMockRecorder) Record(ctx, params, served any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockRecorder)(nil).Record), ctx, params, served)
+}
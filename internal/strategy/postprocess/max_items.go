@@ -0,0 +1,23 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// MaxItems keeps at most Limit items, a non-positive Limit keeps everything
+type MaxItems struct {
+	Limit int
+}
+
+func (s *MaxItems) Name() string {
+	return "max_items"
+}
+
+func (s *MaxItems) Apply(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	if s.Limit <= 0 || len(items) <= s.Limit {
+		return items
+	}
+	return items[:s.Limit]
+}
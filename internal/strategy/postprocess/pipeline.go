@@ -0,0 +1,38 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+	"rec-vendor-api/internal/telemetry"
+)
+
+// Pipeline applies its stages in order and reports the number of items dropped by each stage.
+// If fewer than MinItems items remain, the request is treated as if the vendor returned no products.
+type Pipeline struct {
+	Stages   []Stage
+	MinItems int
+}
+
+func (p *Pipeline) Process(ctx context.Context, params Params, items []unmarshaler.PartnerResp) ([]unmarshaler.PartnerResp, error) {
+	for _, stage := range p.Stages {
+		before := len(items)
+		items = stage.Apply(ctx, params, items)
+		if dropped := before - len(items); dropped > 0 {
+			telemetry.Metrics.PostProcessDroppedTotal.WithLabelValues(params.VendorName, stage.Name()).Add(float64(dropped))
+		}
+	}
+
+	if len(items) < p.MinItems {
+		return nil, unmarshaler.ErrNoProducts
+	}
+	return items, nil
+}
+
+func (p *Pipeline) Record(ctx context.Context, params Params, served []unmarshaler.PartnerResp) {
+	for _, stage := range p.Stages {
+		if recorder, ok := stage.(Recorder); ok {
+			recorder.Record(ctx, params, served)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package postprocess
+
+import (
+	"context"
+	"testing"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+	"rec-vendor-api/internal/telemetry"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	items := []unmarshaler.PartnerResp{
+		{ProductID: "1", ProductURL: "https://a.com/1", ProductImage: "https://a.com/1.jpg"},
+		{ProductID: "1", ProductURL: "https://a.com/1", ProductImage: "https://a.com/1.jpg"},
+		{ProductID: "2", ProductURL: "https://a.com/2"},
+		{ProductID: "3", ProductURL: "http://a.com/3", ProductImage: "http://a.com/3.jpg"},
+	}
+
+	tt := []struct {
+		name        string
+		vendorName  string
+		pipeline    *Pipeline
+		want        []unmarshaler.PartnerResp
+		wantDropped map[string]float64
+		wantErr     error
+	}{
+		{
+			name:       "GIVEN no stages THEN return items unchanged",
+			vendorName: "pipeline_vendor_empty",
+			pipeline:   &Pipeline{},
+			want:       items,
+		},
+		{
+			name:       "GIVEN all stages THEN apply them in order and count drops per stage",
+			vendorName: "pipeline_vendor_all",
+			pipeline: &Pipeline{
+				Stages: []Stage{
+					&Dedupe{},
+					&RequiredFields{Fields: []string{FieldURL, FieldImage}},
+					&ForceHTTPS{},
+					&MaxItems{Limit: 1},
+				},
+			},
+			want: []unmarshaler.PartnerResp{
+				{ProductID: "1", ProductURL: "https://a.com/1", ProductImage: "https://a.com/1.jpg"},
+			},
+			wantDropped: map[string]float64{"dedupe": 1, "required_fields": 1, "force_https": 0, "max_items": 1},
+		},
+		{
+			name:       "GIVEN fewer items than MinItems after the stages THEN return ErrNoProducts",
+			vendorName: "pipeline_vendor_min_items",
+			pipeline: &Pipeline{
+				Stages:   []Stage{&Dedupe{}},
+				MinItems: 4,
+			},
+			wantErr: unmarshaler.ErrNoProducts,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.pipeline.Process(context.Background(), Params{VendorName: tc.vendorName}, items)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				require.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+			for stage, want := range tc.wantDropped {
+				metric := &dto.Metric{}
+				require.NoError(t, telemetry.Metrics.PostProcessDroppedTotal.WithLabelValues(tc.vendorName, stage).Write(metric))
+				require.Equal(t, want, metric.GetCounter().GetValue(), stage)
+			}
+		})
+	}
+}
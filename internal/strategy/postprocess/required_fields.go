@@ -0,0 +1,57 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+const (
+	FieldID    = "id"
+	FieldURL   = "url"
+	FieldImage = "image"
+	FieldPrice = "price"
+)
+
+// RequiredFields drops items where any of the configured fields is empty
+type RequiredFields struct {
+	Fields []string
+}
+
+func (s *RequiredFields) Name() string {
+	return "required_fields"
+}
+
+func (s *RequiredFields) Apply(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := make([]unmarshaler.PartnerResp, 0, len(items))
+	for _, item := range items {
+		if s.hasAllFields(item) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func (s *RequiredFields) hasAllFields(item unmarshaler.PartnerResp) bool {
+	for _, field := range s.Fields {
+		if fieldValue(item, field) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldValue(item unmarshaler.PartnerResp, field string) string {
+	switch field {
+	case FieldID:
+		return item.ProductID
+	case FieldURL:
+		return item.ProductURL
+	case FieldImage:
+		return item.ProductImage
+	case FieldPrice:
+		return item.ProductPrice
+	default:
+		return ""
+	}
+}
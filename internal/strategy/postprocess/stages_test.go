@@ -0,0 +1,85 @@
+package postprocess
+
+import (
+	"context"
+	"testing"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStages(t *testing.T) {
+	tt := []struct {
+		name  string
+		stage Stage
+		input []unmarshaler.PartnerResp
+		want  []unmarshaler.PartnerResp
+	}{
+		{
+			name:  "GIVEN duplicated product IDs THEN Dedupe keeps the first occurrence",
+			stage: &Dedupe{},
+			input: []unmarshaler.PartnerResp{
+				{ProductID: "1", ProductURL: "url1"},
+				{ProductID: "2", ProductURL: "url2"},
+				{ProductID: "1", ProductURL: "url3"},
+			},
+			want: []unmarshaler.PartnerResp{
+				{ProductID: "1", ProductURL: "url1"},
+				{ProductID: "2", ProductURL: "url2"},
+			},
+		},
+		{
+			name:  "GIVEN items missing required fields THEN RequiredFields drops them",
+			stage: &RequiredFields{Fields: []string{FieldURL, FieldImage}},
+			input: []unmarshaler.PartnerResp{
+				{ProductID: "1", ProductURL: "url1", ProductImage: "img1"},
+				{ProductID: "2", ProductURL: "url2"},
+				{ProductID: "3", ProductImage: "img3"},
+			},
+			want: []unmarshaler.PartnerResp{
+				{ProductID: "1", ProductURL: "url1", ProductImage: "img1"},
+			},
+		},
+		{
+			name:  "GIVEN an unknown required field THEN RequiredFields drops every item",
+			stage: &RequiredFields{Fields: []string{"title"}},
+			input: []unmarshaler.PartnerResp{{ProductID: "1"}},
+			want:  []unmarshaler.PartnerResp{},
+		},
+		{
+			name:  "GIVEN http and non-http URLs THEN ForceHTTPS upgrades http and drops other schemes",
+			stage: &ForceHTTPS{},
+			input: []unmarshaler.PartnerResp{
+				{ProductID: "1", ProductURL: "http://a.com/1?x=1", ProductImage: "HTTP://a.com/1.jpg"},
+				{ProductID: "2", ProductURL: "https://a.com/2"},
+				{ProductID: "3", ProductURL: "javascript:alert(1)"},
+				{ProductID: "4", ProductURL: "https://a.com/4", ProductImage: "ftp://a.com/4.jpg"},
+				{ProductID: "5", ProductURL: "://bad"},
+			},
+			want: []unmarshaler.PartnerResp{
+				{ProductID: "1", ProductURL: "https://a.com/1?x=1", ProductImage: "https://a.com/1.jpg"},
+				{ProductID: "2", ProductURL: "https://a.com/2"},
+			},
+		},
+		{
+			name:  "GIVEN more items than the limit THEN MaxItems truncates",
+			stage: &MaxItems{Limit: 2},
+			input: []unmarshaler.PartnerResp{{ProductID: "1"}, {ProductID: "2"}, {ProductID: "3"}},
+			want:  []unmarshaler.PartnerResp{{ProductID: "1"}, {ProductID: "2"}},
+		},
+		{
+			name:  "GIVEN a zero limit THEN MaxItems keeps every item",
+			stage: &MaxItems{},
+			input: []unmarshaler.PartnerResp{{ProductID: "1"}, {ProductID: "2"}},
+			want:  []unmarshaler.PartnerResp{{ProductID: "1"}, {ProductID: "2"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.stage.Apply(context.Background(), Params{}, tc.input)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package postprocess
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+	"rec-vendor-api/internal/telemetry"
+	"rec-vendor-api/internal/urlpolicy"
+)
+
+// URLPolicy drops the items whose product or image URL is refused by the policy, so that no tracking URL
+// redirects to an internal host or an unexpected domain. Empty URLs are left to the required_fields stage.
+type URLPolicy struct {
+	Policy       *urlpolicy.Policy
+	ProductURL   urlpolicy.Rule
+	ProductImage urlpolicy.Rule
+}
+
+func (s *URLPolicy) Name() string {
+	return "url_policy"
+}
+
+func (s *URLPolicy) Apply(_ context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := make([]unmarshaler.PartnerResp, 0, len(items))
+	for _, item := range items {
+		if !s.allowed(params.VendorName, FieldURL, item.ProductURL, s.ProductURL) ||
+			!s.allowed(params.VendorName, FieldImage, item.ProductImage, s.ProductImage) {
+			continue
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+func (s *URLPolicy) allowed(vendor, field, rawURL string, rule urlpolicy.Rule) bool {
+	if rawURL == "" {
+		return true
+	}
+	if reason := s.Policy.Check(rawURL, rule); reason != "" {
+		telemetry.Metrics.URLRejectedTotal.WithLabelValues(vendor, field, reason).Inc()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package postprocess
+
+import (
+	"context"
+	"testing"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+	"rec-vendor-api/internal/telemetry"
+	"rec-vendor-api/internal/urlpolicy"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLPolicy(t *testing.T) {
+	policy, err := urlpolicy.NewPolicy(nil, nil)
+	require.NoError(t, err)
+
+	stage := &URLPolicy{
+		Policy:       policy,
+		ProductURL:   urlpolicy.Rule{Domains: []string{"shop.com"}},
+		ProductImage: urlpolicy.Rule{Domains: []string{"cdn.shop.com"}},
+	}
+	params := Params{VendorName: "url_policy_vendor"}
+	got := stage.Apply(context.Background(), params, []unmarshaler.PartnerResp{
+		{ProductID: "1", ProductURL: "https://shop.com/1", ProductImage: "https://cdn.shop.com/1.jpg"},
+		{ProductID: "2", ProductURL: "https://other.com/2"},
+		{ProductID: "3", ProductURL: "https://shop.com/3", ProductImage: "http://192.168.0.1/3.jpg"},
+		{ProductID: "4", ProductURL: "ftp://shop.com/4"},
+		{ProductID: "5"},
+	})
+
+	require.Equal(t, []unmarshaler.PartnerResp{
+		{ProductID: "1", ProductURL: "https://shop.com/1", ProductImage: "https://cdn.shop.com/1.jpg"},
+		{ProductID: "5"},
+	}, got)
+	for _, labels := range [][]string{
+		{FieldURL, urlpolicy.ReasonDomain},
+		{FieldImage, urlpolicy.ReasonPrivateIP},
+		{FieldURL, urlpolicy.ReasonScheme},
+	} {
+		metric := &dto.Metric{}
+		require.NoError(t, telemetry.Metrics.URLRejectedTotal.WithLabelValues("url_policy_vendor", labels[0], labels[1]).Write(metric))
+		require.Equal(t, float64(1), metric.GetCounter().GetValue(), labels)
+	}
+}
@@ -0,0 +1,52 @@
+package ranker
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+type Arm struct {
+	Name     string
+	Weight   int
+	Strategy Strategy
+}
+
+// Experiment assigns each user to one of the arms proportionally to their weights,
+// the assignment is stable for a given vendor and user ID
+type Experiment struct {
+	Arms []Arm
+}
+
+func (s *Experiment) Rank(ctx context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	arm, ok := s.arm(params)
+	if !ok {
+		return items
+	}
+	return arm.Strategy.Rank(ctx, params, items)
+}
+
+// ArmName returns the name of the arm the user is assigned to
+func (s *Experiment) ArmName(params Params) string {
+	arm, _ := s.arm(params)
+	return arm.Name
+}
+
+func (s *Experiment) arm(params Params) (Arm, bool) {
+	total := 0
+	for _, arm := range s.Arms {
+		total += arm.Weight
+	}
+	if total <= 0 {
+		return Arm{}, false
+	}
+
+	bucket := int(hashOf("experiment", params.VendorName, params.UserID) % uint64(total))
+	for _, arm := range s.Arms {
+		if bucket < arm.Weight {
+			return arm, true
+		}
+		bucket -= arm.Weight
+	}
+	return Arm{}, false
+}
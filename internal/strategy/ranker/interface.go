@@ -0,0 +1,29 @@
+package ranker
+
+import (
+	"context"
+	"hash/fnv"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+type Params struct {
+	VendorName string
+	UserID     string
+}
+
+//go:generate mockgen -source=./interface.go -destination=./interface_mock.go -package=ranker
+
+// Strategy reorders the post-processed products. Implementations must not drop or add items.
+type Strategy interface {
+	Rank(ctx context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp
+}
+
+func hashOf(values ...string) uint64 {
+	h := fnv.New64a()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./interface.go -destination=./interface_mock.go -package=ranker
+//
+
+// Package ranker is a generated GoMock package.
+package ranker
+
+import (
+	context "context"
+	unmarshaler "rec-vendor-api/internal/strategy/unmarshaler"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStrategy is a mock of Strategy interface.
+type MockStrategy struct {
+	ctrl     *gomock.Controller
+	recorder *MockStrategyMockRecorder
+}
+
+// MockStrategyMockRecorder is the mock recorder for MockStrategy.
+type MockStrategyMockRecorder struct {
+	mock *MockStrategy
+}
+
+// NewMockStrategy creates a new mock instance.
+func NewMockStrategy(ctrl *gomock.Controller) *MockStrategy {
+	mock := &MockStrategy{ctrl: ctrl}
+	mock.recorder = &MockStrategyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStrategy) EXPECT() *MockStrategyMockRecorder {
+	return m.recorder
+}
+
+// Rank mocks base method.
+func (m *MockStrategy) Rank(ctx context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rank", ctx, params, items)
+	ret0, _ := ret[0].([]unmarshaler.PartnerResp)
+	return ret0
+}
+
+// Rank indicates an expected call of Rank.
+func (mr *MockStrategyMockRecorder) Rank(ctx, params, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rank", reflect.TypeOf((*MockStrategy)(nil).Rank), ctx, params, items)
+}
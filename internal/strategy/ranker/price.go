@@ -0,0 +1,79 @@
+package ranker
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// Price sorts by the price the user pays, items without a valid price are moved to the end
+type Price struct {
+	Descending bool
+}
+
+func (s *Price) Rank(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := slices.Clone(items)
+	slices.SortStableFunc(res, func(a, b unmarshaler.PartnerResp) int {
+		priceA, okA := effectivePrice(a)
+		priceB, okB := effectivePrice(b)
+		if !okA || !okB {
+			return compareValidity(okA, okB)
+		}
+		if s.Descending {
+			return cmp.Compare(priceB, priceA)
+		}
+		return cmp.Compare(priceA, priceB)
+	})
+	return res
+}
+
+// DiscountFirst sorts by discount ratio in descending order, items without a discount keep their order at the end
+type DiscountFirst struct{}
+
+func (s *DiscountFirst) Rank(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := slices.Clone(items)
+	slices.SortStableFunc(res, func(a, b unmarshaler.PartnerResp) int {
+		return cmp.Compare(discount(b), discount(a))
+	})
+	return res
+}
+
+func effectivePrice(item unmarshaler.PartnerResp) (float64, bool) {
+	if price, ok := parsePrice(item.ProductSalePrice); ok {
+		return price, true
+	}
+	return parsePrice(item.ProductPrice)
+}
+
+func discount(item unmarshaler.PartnerResp) float64 {
+	price, okPrice := parsePrice(item.ProductPrice)
+	salePrice, okSale := parsePrice(item.ProductSalePrice)
+	if !okPrice || !okSale || price <= 0 || salePrice >= price {
+		return 0
+	}
+	return (price - salePrice) / price
+}
+
+// parsePrice accepts plain numbers and numbers with thousands separators, e.g. "12,900"
+func parsePrice(s string) (float64, bool) {
+	price, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	return price, true
+}
+
+func compareValidity(okA, okB bool) int {
+	switch {
+	case okA == okB:
+		return 0
+	case okA:
+		return -1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,133 @@
+package ranker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ids(items []unmarshaler.PartnerResp) []string {
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		res = append(res, item.ProductID)
+	}
+	return res
+}
+
+func TestRank(t *testing.T) {
+	items := []unmarshaler.PartnerResp{
+		{ProductID: "1", ProductPrice: "10000", ProductSalePrice: "9000"},
+		{ProductID: "2", ProductPrice: "abc"},
+		{ProductID: "3", ProductPrice: "5,000"},
+		{ProductID: "4", ProductPrice: "20000", ProductSalePrice: "10000"},
+		{ProductID: "5", ProductSalePrice: "7000"},
+	}
+
+	tt := []struct {
+		name     string
+		strategy Strategy
+		want     []string
+	}{
+		{
+			name:     "GIVEN VendorOrder THEN keep the vendor order",
+			strategy: &VendorOrder{},
+			want:     []string{"1", "2", "3", "4", "5"},
+		},
+		{
+			name:     "GIVEN Price ascending THEN sort by sale price or price, invalid prices last",
+			strategy: &Price{},
+			want:     []string{"3", "5", "1", "4", "2"},
+		},
+		{
+			name:     "GIVEN Price descending THEN sort by sale price or price, invalid prices last",
+			strategy: &Price{Descending: true},
+			want:     []string{"4", "1", "5", "3", "2"},
+		},
+		{
+			name:     "GIVEN DiscountFirst THEN sort by discount ratio and keep the order of undiscounted items",
+			strategy: &DiscountFirst{},
+			want:     []string{"4", "1", "2", "3", "5"},
+		},
+		{
+			name:     "GIVEN WeightedScore THEN sort by score, missing scores count as 0",
+			strategy: &WeightedScore{Scores: map[string]float64{"3": 0.9, "5": 0.5, "1": -1}},
+			want:     []string{"3", "5", "2", "4", "1"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.strategy.Rank(context.Background(), Params{UserID: "u1"}, items)
+			require.Equal(t, tc.want, ids(got))
+			require.Equal(t, []string{"1", "2", "3", "4", "5"}, ids(items), "input must not be modified")
+		})
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	items := make([]unmarshaler.PartnerResp, 0, 20)
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t"} {
+		items = append(items, unmarshaler.PartnerResp{ProductID: id})
+	}
+	strategy := &Shuffle{}
+
+	first := strategy.Rank(context.Background(), Params{VendorName: "v", UserID: "u1"}, items)
+	second := strategy.Rank(context.Background(), Params{VendorName: "v", UserID: "u1"}, items)
+	other := strategy.Rank(context.Background(), Params{VendorName: "v", UserID: "u2"}, items)
+
+	require.Equal(t, ids(first), ids(second))
+	require.NotEqual(t, ids(first), ids(other))
+	require.ElementsMatch(t, ids(items), ids(first))
+	require.NotEqual(t, ids(items), ids(first))
+}
+
+func TestExperiment(t *testing.T) {
+	items := []unmarshaler.PartnerResp{{ProductID: "1", ProductPrice: "2"}, {ProductID: "2", ProductPrice: "1"}}
+	experiment := &Experiment{Arms: []Arm{
+		{Name: "control", Weight: 1, Strategy: &VendorOrder{}},
+		{Name: "cheap_first", Weight: 1, Strategy: &Price{}},
+	}}
+
+	arms := map[string]int{}
+	for _, userID := range []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8", "u9", "u10", "u11", "u12"} {
+		params := Params{VendorName: "v", UserID: userID}
+		arm := experiment.ArmName(params)
+		require.Equal(t, arm, experiment.ArmName(params), "assignment must be stable")
+		arms[arm]++
+
+		got := experiment.Rank(context.Background(), params, items)
+		if arm == "control" {
+			require.Equal(t, []string{"1", "2"}, ids(got))
+		} else {
+			require.Equal(t, []string{"2", "1"}, ids(got))
+		}
+	}
+	require.Len(t, arms, 2)
+
+	empty := &Experiment{}
+	require.Equal(t, items, empty.Rank(context.Background(), Params{UserID: "u1"}, items))
+	require.Empty(t, empty.ArmName(Params{UserID: "u1"}))
+}
+
+func TestLoadScores(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "scores.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"1": 0.5, "2": 3}`), 0o600))
+	invalidPath := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalidPath, []byte(`{"1": "high"}`), 0o600))
+
+	scores, err := LoadScores(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"1": 0.5, "2": 3}, scores)
+
+	_, err = LoadScores(invalidPath)
+	require.Error(t, err)
+
+	_, err = LoadScores(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package ranker
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// Shuffle randomizes the order with a seed derived from the user ID,
+// so that the same user gets the same order for the same products
+type Shuffle struct{}
+
+func (s *Shuffle) Rank(_ context.Context, params Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := slices.Clone(items)
+	seed := hashOf(params.VendorName, params.UserID)
+	r := rand.New(rand.NewPCG(seed, seed>>1))
+	r.Shuffle(len(res), func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+	return res
+}
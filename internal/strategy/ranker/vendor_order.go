@@ -0,0 +1,14 @@
+package ranker
+
+import (
+	"context"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+)
+
+// VendorOrder keeps the order returned by the vendor
+type VendorOrder struct{}
+
+func (s *VendorOrder) Rank(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	return items
+}
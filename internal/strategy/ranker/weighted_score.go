@@ -0,0 +1,39 @@
+package ranker
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"rec-vendor-api/internal/strategy/unmarshaler"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WeightedScore sorts by the per-product scores in descending order, products without a score count as 0
+type WeightedScore struct {
+	Scores map[string]float64
+}
+
+// LoadScores reads a YAML or JSON file mapping product IDs to scores
+func LoadScores(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read score file %s: %w", path, err)
+	}
+	scores := map[string]float64{}
+	if err := yaml.Unmarshal(data, &scores); err != nil {
+		return nil, fmt.Errorf("fail to parse score file %s: %w", path, err)
+	}
+	return scores, nil
+}
+
+func (s *WeightedScore) Rank(_ context.Context, _ Params, items []unmarshaler.PartnerResp) []unmarshaler.PartnerResp {
+	res := slices.Clone(items)
+	slices.SortStableFunc(res, func(a, b unmarshaler.PartnerResp) int {
+		return cmp.Compare(s.Scores[b.ProductID], s.Scores[a.ProductID])
+	})
+	return res
+}
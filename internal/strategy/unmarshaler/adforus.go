@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"strconv"
 
+	"rec-vendor-api/internal/redact"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -21,7 +23,7 @@ type Adforus struct{}
 func (s *Adforus) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
 	var resp []adforusResp
 	if err := json.Unmarshal(body, &resp); err != nil {
-		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", string(body))
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
 		return nil, newInvalidFormatError(body)
 	}
 	if len(resp) == 0 {
@@ -33,6 +35,7 @@ func (s *Adforus) UnmarshalResponse(ctx context.Context, body []byte) ([]Partner
 		res = append(res, PartnerResp{
 			ProductID:        item.ProductID,
 			ProductURL:       item.ProductURL,
+			ProductTitle:     item.ProductName,
 			ProductSalePrice: strconv.Itoa(item.ProductPrice),
 		})
 	}
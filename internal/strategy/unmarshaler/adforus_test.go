@@ -28,6 +28,7 @@ func TestAdforus_UnmarshalResponse(t *testing.T) {
 				{
 					ProductID:        "3288378",
 					ProductSalePrice: "241000",
+					ProductTitle:     "추석 이벤트/ 세렌티 1200 모듈 수납장",
 					ProductURL:       "https://api.linkmine.co.kr/ck.html?app_code=zbkj6Sirtt&sid=39562&deep_link=https%3A%2F%2Flink.ohou.se%2F%40ohouse%2Faffiliate%3Fchannel%3Daffiliate",
 				},
 			},
@@ -54,11 +55,13 @@ func TestAdforus_UnmarshalResponse(t *testing.T) {
 				{
 					ProductID:        "3288378",
 					ProductSalePrice: "241000",
+					ProductTitle:     "product1",
 					ProductURL:       "url1",
 				},
 				{
 					ProductID:        "1019809",
 					ProductSalePrice: "15740",
+					ProductTitle:     "product2",
 					ProductURL:       "url2",
 				},
 			},
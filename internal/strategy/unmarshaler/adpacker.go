@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"strconv"
 
+	"rec-vendor-api/internal/redact"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,7 +19,7 @@ type Adpacker struct{}
 func (s *Adpacker) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
 	resp := &adpackerResp{}
 	if err := json.Unmarshal(body, resp); err != nil {
-		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", string(body))
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
 		return nil, newInvalidFormatError(body)
 	}
 	if len(resp.Data) == 0 {
@@ -30,6 +32,7 @@ func (s *Adpacker) UnmarshalResponse(ctx context.Context, body []byte) ([]Partne
 			ProductID:    strconv.Itoa(item.ProductID),
 			ProductURL:   item.ProductURL,
 			ProductImage: item.ProductImage,
+			ProductTitle: item.ProductName,
 		})
 	}
 	if len(res) == 1 && res[0].ProductID == "0" {
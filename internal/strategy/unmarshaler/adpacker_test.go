@@ -17,8 +17,8 @@ func TestAdpacker(t *testing.T) {
 	}{
 		{
 			name:  "GIVEN valid JSON THEN return the expected struct",
-			input: []byte(`{"data":[{"productId":1,"productUrl":"url1","productImage":"img1"},{"productId":2,"productUrl":"url2","productImage":"img2"}]}`),
-			want:  []PartnerResp{{ProductID: "1", ProductImage: "img1", ProductURL: "url1"}, {ProductID: "2", ProductImage: "img2", ProductURL: "url2"}},
+			input: []byte(`{"data":[{"productId":1,"productName":"name1","productUrl":"url1","productImage":"img1"},{"productId":2,"productName":"name2","productUrl":"url2","productImage":"img2"}]}`),
+			want:  []PartnerResp{{ProductID: "1", ProductImage: "img1", ProductURL: "url1", ProductTitle: "name1"}, {ProductID: "2", ProductImage: "img2", ProductURL: "url2", ProductTitle: "name2"}},
 		},
 		{
 			name:        "GIVEN invalid JSON THEN return an error",
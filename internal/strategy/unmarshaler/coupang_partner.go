@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"strconv"
 
+	"rec-vendor-api/internal/redact"
+
 	log "github.com/sirupsen/logrus"
 )
 
 type coupangResp struct {
 	ProductID    int    `json:"productId"`
+	ProductName  string `json:"productName"`
 	ProductURL   string `json:"productUrl"`
 	ProductImage string `json:"productImage"`
 }
@@ -19,7 +22,7 @@ type CoupangPartner struct{}
 func (s *CoupangPartner) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
 	var resp []coupangResp
 	if err := json.Unmarshal(body, &resp); err != nil {
-		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", string(body))
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
 		return nil, newInvalidFormatError(body)
 	}
 
@@ -29,6 +32,7 @@ func (s *CoupangPartner) UnmarshalResponse(ctx context.Context, body []byte) ([]
 			ProductID:    strconv.Itoa(item.ProductID),
 			ProductURL:   item.ProductURL,
 			ProductImage: item.ProductImage,
+			ProductTitle: item.ProductName,
 		})
 	}
 	if len(res) == 1 && res[0].ProductID == "0" {
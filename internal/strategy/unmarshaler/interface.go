@@ -9,12 +9,14 @@ import (
 var (
 	ErrNoProducts       = errors.New("no products were returned")
 	ErrInvalidProductID = errors.New("only a product with ID 0 was returned")
+	ErrInvalidFormat    = errors.New("invalid format")
 )
 
 type PartnerResp struct {
 	ProductID        string
 	ProductURL       string
 	ProductImage     string
+	ProductTitle     string
 	ProductPrice     string
 	ProductSalePrice string
 	ProductCurrency  string
@@ -33,5 +35,5 @@ func newInvalidFormatError(b []byte) error {
 	if len(runes) > 20 {
 		s = string(runes[:20]) + "..."
 	}
-	return fmt.Errorf("invalid format. body: %s", s)
+	return fmt.Errorf("%w. body: %s", ErrInvalidFormat, s)
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"rec-vendor-api/internal/redact"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -27,12 +29,14 @@ type keetaItem struct {
 	Currency  string `json:"currency"`
 }
 
+// Keeta responses have no product name, so the keeta products have no title and the blocklist keywords never
+// match them
 type Keeta struct{}
 
 func (s *Keeta) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
 	var resp keetaResp
 	if err := json.Unmarshal(body, &resp); err != nil {
-		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", string(body))
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
 		return nil, newInvalidFormatError(body)
 	}
 	if resp.Code != 0 {
@@ -0,0 +1,80 @@
+package unmarshaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/redact"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Mapping Strategy: parse the products with the JSON paths of the vendor response config
+type Mapping struct {
+	Response config.Response
+}
+
+func (s *Mapping) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
+		return nil, newInvalidFormatError(body)
+	}
+
+	items, ok := lookup(doc, s.Response.Items).([]any)
+	if !ok {
+		return nil, newInvalidFormatError(body)
+	}
+	if len(items) == 0 {
+		return nil, ErrNoProducts
+	}
+
+	res := make([]PartnerResp, 0, len(items))
+	for _, item := range items {
+		res = append(res, PartnerResp{
+			ProductID:        lookupString(item, s.Response.ID),
+			ProductURL:       lookupString(item, s.Response.URL),
+			ProductImage:     lookupString(item, s.Response.Image),
+			ProductTitle:     lookupString(item, s.Response.Title),
+			ProductPrice:     lookupString(item, s.Response.Price),
+			ProductSalePrice: lookupString(item, s.Response.SalePrice),
+			ProductCurrency:  lookupString(item, s.Response.Currency),
+		})
+	}
+	return res, nil
+}
+
+// lookup returns the value at the dot-separated path of the document, the document itself for an empty path
+func lookup(doc any, path string) any {
+	if path == "" {
+		return doc
+	}
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil
+		}
+		doc = obj[key]
+	}
+	return doc
+}
+
+// lookupString returns the string or number at the path, and "" for an unset path or another value
+func lookupString(doc any, path string) string {
+	if path == "" {
+		return ""
+	}
+	switch v := lookup(doc, path).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,81 @@
+package unmarshaler
+
+import (
+	"context"
+	"testing"
+
+	"rec-vendor-api/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapping_UnmarshalResponse(t *testing.T) {
+	wrapped := config.Response{Items: "data.items", ID: "id", URL: "link.deeplink", Price: "price", Currency: "currency"}
+	topLevel := config.Response{ID: "productId", URL: "productUrl", Image: "productImage", Title: "productName"}
+
+	tests := []struct {
+		name        string
+		response    config.Response
+		body        []byte
+		want        []PartnerResp
+		wantedError error
+	}{
+		{
+			name:     "GIVEN products under a nested path THEN return the mapped fields",
+			response: wrapped,
+			body: []byte(`{
+				"code": 0,
+				"data": {
+					"items": [
+						{"id": 123, "link": {"deeplink": "https://shop/123"}, "price": 10.5, "currency": "KRW"},
+						{"id": "abc", "link": {"deeplink": "https://shop/abc"}, "price": "990"}
+					]
+				}
+			}`),
+			want: []PartnerResp{
+				{ProductID: "123", ProductURL: "https://shop/123", ProductPrice: "10.5", ProductCurrency: "KRW"},
+				{ProductID: "abc", ProductURL: "https://shop/abc", ProductPrice: "990"},
+			},
+		},
+		{
+			name:     "GIVEN a top-level product list THEN return the mapped fields",
+			response: topLevel,
+			body:     []byte(`[{"productId": "1", "productUrl": "url1", "productImage": "image1.jpg", "productName": "product1"}]`),
+			want:     []PartnerResp{{ProductID: "1", ProductURL: "url1", ProductImage: "image1.jpg", ProductTitle: "product1"}},
+		},
+		{
+			name:        "GIVEN an empty product list THEN return no products error",
+			response:    wrapped,
+			body:        []byte(`{"data": {"items": []}}`),
+			wantedError: ErrNoProducts,
+		},
+		{
+			name:        "GIVEN no product list at the path THEN return invalid format error",
+			response:    wrapped,
+			body:        []byte(`{"data": {"products": []}}`),
+			wantedError: ErrInvalidFormat,
+		},
+		{
+			name:        "GIVEN invalid JSON THEN return invalid format error",
+			response:    topLevel,
+			body:        []byte(`invalid json`),
+			wantedError: ErrInvalidFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unmarshaler := &Mapping{Response: tt.response}
+
+			result, err := unmarshaler.UnmarshalResponse(context.Background(), tt.body)
+
+			if tt.wantedError != nil {
+				require.ErrorIs(t, err, tt.wantedError)
+				require.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, result)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strconv"
 
+	"rec-vendor-api/internal/redact"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -24,7 +26,7 @@ type Replace struct{}
 func (s *Replace) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
 	rResp := &replaceResp{}
 	if err := json.Unmarshal(body, rResp); err != nil {
-		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", string(body))
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
 		return nil, newInvalidFormatError(body)
 	}
 	if rResp.RCode != "0" {
@@ -37,6 +39,7 @@ func (s *Replace) UnmarshalResponse(ctx context.Context, body []byte) ([]Partner
 			ProductID:    strconv.Itoa(item.ProductID),
 			ProductURL:   item.ProductURL,
 			ProductImage: item.ProductImage,
+			ProductTitle: item.ProductName,
 		})
 	}
 	if len(res) == 1 && res[0].ProductID == "0" {
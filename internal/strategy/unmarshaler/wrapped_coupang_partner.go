@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strconv"
 
+	"rec-vendor-api/internal/redact"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -20,7 +22,7 @@ type WrappedCoupangPartner struct{}
 func (s *WrappedCoupangPartner) UnmarshalResponse(ctx context.Context, body []byte) ([]PartnerResp, error) {
 	rResp := &wrappedResp{}
 	if err := json.Unmarshal(body, rResp); err != nil {
-		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", string(body))
+		log.WithContext(ctx).Errorf("fail to unmarshal response body: %s", redact.Body(ctx, body))
 		return nil, newInvalidFormatError(body)
 	}
 	if rResp.RCode != "0" {
@@ -33,6 +35,7 @@ func (s *WrappedCoupangPartner) UnmarshalResponse(ctx context.Context, body []by
 			ProductID:    strconv.Itoa(item.ProductID),
 			ProductURL:   item.ProductURL,
 			ProductImage: item.ProductImage,
+			ProductTitle: item.ProductName,
 		})
 	}
 	if len(res) == 1 && res[0].ProductID == "0" {
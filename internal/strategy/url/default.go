@@ -1,7 +1,9 @@
 package url
 
 import (
+	"fmt"
 	urlpkg "net/url"
+	"rec-vendor-api/internal/clickseal"
 	"rec-vendor-api/internal/config"
 	"rec-vendor-api/internal/controller/errors"
 	"rec-vendor-api/internal/strategy/utils"
@@ -12,10 +14,20 @@ import (
 
 var (
 	MacroRegExp = regexp.MustCompile(`\{[^}]*\}`)
+
+	// Macros are the macros of GetMacroValue, listed for the config schema
+	Macros = []string{
+		"{width}", "{height}", "{user_id_lower}", "{user_id_case_by_os}", "{click_id_base64}", "{click_id_sealed}",
+		"{web_host}", "{bundle_id}", "{adtype}", "{partner_id}", "{subid}", "{product_url}", "{keeta_campaign_id}",
+		"{click_id}", "{client_ip}", "{latitude}", "{longitude}",
+	}
 )
 
 // Default Strategy: Replace macros in URL and query values with values from Params
-type Default struct{}
+type Default struct {
+	// Sealer encrypts the {click_id_sealed} macro, the macro fails without it
+	Sealer *clickseal.Sealer
+}
 
 func (s *Default) GenerateURL(urlPattern config.URLPattern, params Params) (string, error) {
 	url := urlPattern.URL
@@ -95,6 +107,14 @@ func (s *Default) GetMacroValue(macro string, params Params) (string, error) {
 			return "", errors.BadRequestErrorf("ClickID not provided")
 		}
 		return utils.EncodeClickID(params.ClickID), nil
+	case "{click_id_sealed}":
+		if params.ClickID == "" {
+			return "", errors.BadRequestErrorf("ClickID not provided")
+		}
+		if s.Sealer == nil {
+			return "", fmt.Errorf("%s requires the click seal_keys", macro)
+		}
+		return s.Sealer.Seal(params.ClickID)
 	case "{web_host}":
 		return params.WebHost, nil
 	case "{bundle_id}":
@@ -1,7 +1,14 @@
 package url
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	urlpkg "net/url"
+	"rec-vendor-api/internal/clickseal"
 	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/controller/errors"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -271,3 +278,49 @@ func TestDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestMacros(t *testing.T) {
+	// the macros are the cases of GetMacroValue
+	f, err := parser.ParseFile(token.NewFileSet(), "default.go", nil, 0)
+	require.NoError(t, err)
+	var cases []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		if fn, ok := n.(*ast.FuncDecl); ok && fn.Name.Name != "GetMacroValue" {
+			return false
+		}
+		if c, ok := n.(*ast.CaseClause); ok {
+			for _, expr := range c.List {
+				lit, ok := expr.(*ast.BasicLit)
+				require.True(t, ok)
+				value, err := strconv.Unquote(lit.Value)
+				require.NoError(t, err)
+				cases = append(cases, value)
+			}
+		}
+		return true
+	})
+	require.ElementsMatch(t, cases, Macros)
+
+	for _, macro := range Macros {
+		_, err := (&Default{}).GetMacroValue(macro, Params{})
+		require.NotErrorIs(t, err, errors.ErrUnknownMacro, macro)
+	}
+}
+
+func TestSealedClickID(t *testing.T) {
+	sealer, err := clickseal.NewSealer([]clickseal.Key{{ID: "k1", Key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}})
+	require.NoError(t, err)
+	pattern := config.URLPattern{URL: "https://vendor.com/trk", Queries: []config.Query{{Key: "sub", Value: "{click_id_sealed}"}}}
+
+	gotURL, err := (&Default{Sealer: sealer}).GenerateURL(pattern, Params{ClickID: "click-1"})
+	require.NoError(t, err)
+	parsedURL, err := urlpkg.Parse(gotURL)
+	require.NoError(t, err)
+	require.NotContains(t, gotURL, "click-1")
+	clickID, err := sealer.Open(parsedURL.Query().Get("sub"))
+	require.NoError(t, err)
+	require.Equal(t, "click-1", clickID)
+
+	_, err = (&Default{}).GenerateURL(pattern, Params{ClickID: "click-1"})
+	require.EqualError(t, err, "{click_id_sealed} requires the click seal_keys")
+}
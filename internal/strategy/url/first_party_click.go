@@ -0,0 +1,48 @@
+package url
+
+import (
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/tracktoken"
+)
+
+// ClickPath is the path prefix of the first-party click redirect endpoint
+const ClickPath = "/c/"
+
+// FirstPartyClick Strategy: Generate the vendor tracking URL with Tracking, then wrap it into a signed token
+// pointing at our own click redirect endpoint. The click ID of the token is sealed when a Sealer is set.
+type FirstPartyClick struct {
+	Tracking   Strategy
+	Signer     *tracktoken.Signer
+	Sealer     *clickseal.Sealer
+	BaseURL    string
+	VendorName string
+}
+
+func (s *FirstPartyClick) GenerateURL(urlPattern config.URLPattern, params Params) (string, error) {
+	trackingURL, err := s.Tracking.GenerateURL(urlPattern, params)
+	if err != nil {
+		return "", err
+	}
+
+	claims := tracktoken.Claims{
+		Vendor:    s.VendorName,
+		ClickID:   params.ClickID,
+		UserID:    params.UserID,
+		ProductID: params.ProductID,
+		SiteID:    params.SiteID,
+		URL:       trackingURL,
+	}
+	if s.Sealer != nil && claims.ClickID != "" {
+		if claims.ClickID, err = s.Sealer.Seal(claims.ClickID); err != nil {
+			return "", err
+		}
+		claims.Sealed = true
+	}
+
+	token, err := s.Signer.Sign(claims)
+	if err != nil {
+		return "", err
+	}
+	return s.BaseURL + ClickPath + token, nil
+}
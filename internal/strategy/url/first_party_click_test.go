@@ -0,0 +1,83 @@
+package url
+
+import (
+	"errors"
+	"rec-vendor-api/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/clickseal"
+	"rec-vendor-api/internal/tracktoken"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFirstPartyClick(t *testing.T) {
+	urlPattern := config.URLPattern{URL: "https://tracker.com/trk"}
+	params := Params{ClickID: "click-1", UserID: "user-1", ProductID: "p1", SiteID: "site-1", ProductURL: "https://shop.com/p1"}
+
+	tt := []struct {
+		name        string
+		setupMock   func(m *MockStrategy)
+		expectedErr string
+	}{
+		{
+			name: "GIVEN a tracking URL THEN return a signed first-party click URL",
+			setupMock: func(m *MockStrategy) {
+				m.EXPECT().GenerateURL(urlPattern, params).Return("https://tracker.com/trk?url=x", nil)
+			},
+		},
+		{
+			name: "GIVEN the tracking strategy fails THEN return the error",
+			setupMock: func(m *MockStrategy) {
+				m.EXPECT().GenerateURL(urlPattern, params).Return("", errors.New("unknown macro"))
+			},
+			expectedErr: "unknown macro",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tracking := NewMockStrategy(gomock.NewController(t))
+			tc.setupMock(tracking)
+			signer := tracktoken.NewSigner("secret", time.Hour)
+			s := &FirstPartyClick{Tracking: tracking, Signer: signer, BaseURL: "https://rec.example.com", VendorName: "linkmine"}
+
+			got, err := s.GenerateURL(urlPattern, params)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			token, found := strings.CutPrefix(got, "https://rec.example.com"+ClickPath)
+			require.True(t, found)
+			claims, err := signer.Verify(token)
+			require.NoError(t, err)
+			require.Equal(t, "linkmine", claims.Vendor)
+			require.Equal(t, "click-1", claims.ClickID)
+			require.Equal(t, "user-1", claims.UserID)
+			require.Equal(t, "p1", claims.ProductID)
+			require.Equal(t, "site-1", claims.SiteID)
+			require.Equal(t, "https://tracker.com/trk?url=x", claims.URL)
+		})
+	}
+}
+
+func TestFirstPartyClickSealed(t *testing.T) {
+	sealer, err := clickseal.NewSealer([]clickseal.Key{{ID: "k1", Key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}})
+	require.NoError(t, err)
+	signer := tracktoken.NewSigner("secret", time.Hour)
+	s := &FirstPartyClick{Tracking: &NoURL{}, Signer: signer, Sealer: sealer, BaseURL: "https://rec.example.com", VendorName: "linkmine"}
+
+	got, err := s.GenerateURL(config.URLPattern{}, Params{ClickID: "click-1"})
+	require.NoError(t, err)
+	claims, err := signer.Verify(strings.TrimPrefix(got, "https://rec.example.com"+ClickPath))
+	require.NoError(t, err)
+	require.True(t, claims.Sealed)
+	clickID, err := sealer.Open(claims.ClickID)
+	require.NoError(t, err)
+	require.Equal(t, "click-1", clickID)
+}
@@ -0,0 +1,30 @@
+package url
+
+import (
+	"rec-vendor-api/internal/config"
+	"rec-vendor-api/internal/tracktoken"
+)
+
+// ImpressionPath is the path prefix of the impression beacon endpoint
+const ImpressionPath = "/i/"
+
+// ImpressionBeacon Strategy: Generate a signed URL to our own impression beacon endpoint, the URL pattern is not used
+type ImpressionBeacon struct {
+	Signer     *tracktoken.Signer
+	BaseURL    string
+	VendorName string
+}
+
+func (s *ImpressionBeacon) GenerateURL(_ config.URLPattern, params Params) (string, error) {
+	token, err := s.Signer.Sign(tracktoken.Claims{
+		Vendor:    s.VendorName,
+		ClickID:   params.ClickID,
+		UserID:    params.UserID,
+		ProductID: params.ProductID,
+		SiteID:    params.SiteID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.BaseURL + ImpressionPath + token, nil
+}
@@ -0,0 +1,29 @@
+package url
+
+import (
+	"rec-vendor-api/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	"rec-vendor-api/internal/tracktoken"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpressionBeacon(t *testing.T) {
+	signer := tracktoken.NewSigner("secret", time.Hour)
+	s := &ImpressionBeacon{Signer: signer, BaseURL: "https://rec.example.com", VendorName: "linkmine"}
+
+	got, err := s.GenerateURL(config.URLPattern{}, Params{ClickID: "click-1", UserID: "user-1", ProductID: "p1", SiteID: "site-1"})
+	require.NoError(t, err)
+
+	token, found := strings.CutPrefix(got, "https://rec.example.com"+ImpressionPath)
+	require.True(t, found)
+	claims, err := signer.Verify(token)
+	require.NoError(t, err)
+	require.Equal(t, "linkmine", claims.Vendor)
+	require.Equal(t, "p1", claims.ProductID)
+	require.Equal(t, "site-1", claims.SiteID)
+	require.Empty(t, claims.URL)
+}
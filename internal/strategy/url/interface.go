@@ -21,6 +21,8 @@ type Params struct {
 
 	// tracking
 	ProductURL string
+	ProductID  string
+	SiteID     string
 
 	// decide to use upper case or lower case user id
 	OS string
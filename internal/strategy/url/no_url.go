@@ -0,0 +1,12 @@
+package url
+
+import (
+	"rec-vendor-api/internal/config"
+)
+
+// NoURL Strategy: Generate no URL, for optional URLs that are disabled
+type NoURL struct{}
+
+func (s *NoURL) GenerateURL(_ config.URLPattern, _ Params) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,14 @@
+package url
+
+import (
+	"rec-vendor-api/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoURL(t *testing.T) {
+	got, err := (&NoURL{}).GenerateURL(config.URLPattern{URL: "https://tracker.com"}, Params{})
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
@@ -11,6 +11,15 @@ func EncodeClickID(clickID string) string {
 	return strings.TrimRight(encoded, "=")
 }
 
+// DecodeClickID reverses EncodeClickID, padded input is accepted as well
+func DecodeClickID(encoded string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
 // GetSortedStringKeys returns the sorted keys of a string map in ascending order.
 func GetSortedStringKeys(m map[string]string) []string {
 	keys := make([]string, 0, len(m))
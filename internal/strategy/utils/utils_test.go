@@ -30,6 +30,41 @@ func TestEncodeClickID(t *testing.T) {
 	}
 }
 
+func TestDecodeClickID(t *testing.T) {
+	tt := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "GIVEN an encoded click ID THEN expect the original click ID",
+			input:    EncodeClickID("click-id?1"),
+			expected: "click-id?1",
+		},
+		{
+			name:     "GIVEN a padded click ID THEN expect the original click ID",
+			input:    "YWI=",
+			expected: "ab",
+		},
+		{
+			name:    "GIVEN an invalid base64 string THEN expect error",
+			input:   "not base64!",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		result, err := DecodeClickID(tc.input)
+		if tc.wantErr {
+			require.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, result)
+	}
+}
+
 func TestSortedStringKeys(t *testing.T) {
 	cases := []struct {
 		name  string
@@ -0,0 +1,92 @@
+package tape
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/plaxieappier/rec-go-kit/httpkit"
+	log "github.com/sirupsen/logrus"
+)
+
+// Recorder forwards the requests to the wrapped client and appends the scrubbed exchanges to the tape.
+// Requests without a Request in the context, and network errors without a response, are not recorded.
+type Recorder struct {
+	httpkit.Client
+	tape     *Tape
+	scrubber *Scrubber
+}
+
+func NewRecorder(client httpkit.Client, tape *Tape, scrubber *Scrubber) *Recorder {
+	return &Recorder{Client: client, tape: tape, scrubber: scrubber}
+}
+
+func (r *Recorder) Get(ctx context.Context, req *httpkit.Request, timeout time.Duration, statuses []int) (*httpkit.Response, error) {
+	resp, err := r.Client.Get(ctx, req, timeout, statuses)
+	r.record(ctx, resp)
+	return resp, err
+}
+
+func (r *Recorder) Post(ctx context.Context, req *httpkit.Request, timeout time.Duration, statuses []int) (*httpkit.Response, error) {
+	resp, err := r.Client.Post(ctx, req, timeout, statuses)
+	r.record(ctx, resp)
+	return resp, err
+}
+
+func (r *Recorder) record(ctx context.Context, resp *httpkit.Response) {
+	req, ok := RequestFromContext(ctx)
+	if !ok || resp == nil {
+		return
+	}
+	recorded, err := r.scrubber.Request(req)
+	if err != nil {
+		log.WithContext(ctx).Warnf("Fail to scrub the recorded request. err: %v", err)
+		return
+	}
+	ex := Exchange{Request: recorded, Response: r.scrubber.Response(resp.StatusCode, resp.Body, req.UserID)}
+	if err := r.tape.Append(ex); err != nil {
+		log.WithContext(ctx).Warnf("Fail to record the exchange. err: %v", err)
+	}
+}
+
+// Replayer answers the requests from the tape without any network call. It only implements Get and Post,
+// the other httpkit.Client methods are not used by the vendor clients.
+type Replayer struct {
+	httpkit.Client
+	tape     *Tape
+	scrubber *Scrubber
+}
+
+func NewReplayer(tape *Tape, scrubber *Scrubber) *Replayer {
+	return &Replayer{tape: tape, scrubber: scrubber}
+}
+
+func (r *Replayer) Get(ctx context.Context, _ *httpkit.Request, _ time.Duration, statuses []int) (*httpkit.Response, error) {
+	return r.replay(ctx, statuses)
+}
+
+func (r *Replayer) Post(ctx context.Context, _ *httpkit.Request, _ time.Duration, statuses []int) (*httpkit.Response, error) {
+	return r.replay(ctx, statuses)
+}
+
+func (r *Replayer) replay(ctx context.Context, statuses []int) (*httpkit.Response, error) {
+	req, ok := RequestFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("tape: no request in context")
+	}
+	recorded, err := r.scrubber.Request(req)
+	if err != nil {
+		return nil, err
+	}
+	ex, ok := r.tape.Match(recorded)
+	if !ok {
+		return nil, fmt.Errorf("tape: no exchange recorded for %s %s", recorded.Method, recorded.URL)
+	}
+
+	resp := &httpkit.Response{StatusCode: ex.Response.StatusCode, Body: ex.Response.Bytes()}
+	if len(statuses) > 0 && !slices.Contains(statuses, resp.StatusCode) {
+		return resp, fmt.Errorf("tape: unexpected status code %d", resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,95 @@
+package tape
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	SecretPlaceholder    = "<secret>"
+	UserIDPlaceholder    = "<user_id>"
+	SignaturePlaceholder = "<signature>"
+)
+
+// signatureHeaders change on every request and are replaced as a whole
+var signatureHeaders = map[string]bool{
+	"Authorization":  true,
+	"S-Ca-Signature": true,
+	"Cookie":         true,
+}
+
+// Scrubber removes the vendor secrets and the user IDs from the recorded exchanges
+type Scrubber struct {
+	secrets []string
+}
+
+func NewScrubber(secrets ...string) *Scrubber {
+	s := &Scrubber{}
+	for _, secret := range secrets {
+		if secret != "" {
+			s.secrets = append(s.secrets, secret)
+		}
+	}
+	// longest first, so a secret containing another one is replaced as a whole
+	sort.Slice(s.secrets, func(i, j int) bool { return len(s.secrets[i]) > len(s.secrets[j]) })
+	return s
+}
+
+func (s *Scrubber) Request(req Request) (RecordedRequest, error) {
+	replacer := s.replacer(req.UserID)
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    replacer.Replace(req.URL),
+	}
+	if len(req.Headers) > 0 {
+		recorded.Headers = make(map[string]string, len(req.Headers))
+		for k, v := range req.Headers {
+			if signatureHeaders[http.CanonicalHeaderKey(k)] {
+				recorded.Headers[k] = SignaturePlaceholder
+				continue
+			}
+			recorded.Headers[k] = replacer.Replace(v)
+		}
+	}
+	if req.Body != nil {
+		b, err := json.Marshal(req.Body)
+		if err != nil {
+			return RecordedRequest{}, err
+		}
+		recorded.Body = json.RawMessage(replacer.Replace(string(b)))
+	}
+	return recorded, nil
+}
+
+// Response scrubs the response as well, since some vendors echo the user ID back
+func (s *Scrubber) Response(statusCode int, body []byte, userID string) RecordedResponse {
+	scrubbed := s.replacer(userID).Replace(string(body))
+	if json.Valid([]byte(scrubbed)) {
+		return RecordedResponse{StatusCode: statusCode, Body: json.RawMessage(scrubbed)}
+	}
+	return RecordedResponse{StatusCode: statusCode, BodyText: scrubbed}
+}
+
+// replacer replaces the secrets and the user ID, both raw and URL-encoded, and the user ID in the lower
+// and upper cases used by the {user_id_lower} and {user_id_case_by_os} macros
+func (s *Scrubber) replacer(userID string) *strings.Replacer {
+	var pairs []string
+	add := func(value, placeholder string) {
+		pairs = append(pairs, value, placeholder)
+		if escaped := url.QueryEscape(value); escaped != value {
+			pairs = append(pairs, escaped, placeholder)
+		}
+	}
+	for _, secret := range s.secrets {
+		add(secret, SecretPlaceholder)
+	}
+	if userID != "" {
+		for _, v := range []string{userID, strings.ToLower(userID), strings.ToUpper(userID)} {
+			add(v, UserIDPlaceholder)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}
@@ -0,0 +1,160 @@
+// Package tape records vendor request/response pairs to fixture files and replays them in tests
+package tape
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Request describes a vendor request. httpkit.Request does not expose its URL, headers and body, so the
+// vendor client passes them to the recorder and replayer along with the context.
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    any
+	UserID  string
+}
+
+type requestKey struct{}
+
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+func RequestFromContext(ctx context.Context) (Request, bool) {
+	req, ok := ctx.Value(requestKey{}).(Request)
+	return req, ok
+}
+
+// Exchange is a scrubbed request/response pair saved in a fixture file
+type Exchange struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// RecordedResponse keeps JSON bodies as is for readable fixtures, and any other body as text
+type RecordedResponse struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	BodyText   string          `json:"body_text,omitempty"`
+}
+
+func (r RecordedResponse) Bytes() []byte {
+	if len(r.Body) > 0 {
+		return r.Body
+	}
+	return []byte(r.BodyText)
+}
+
+// Tape is the fixture file of one vendor, <dir>/<vendor>.json
+type Tape struct {
+	path         string
+	maxExchanges int
+	mu           sync.RWMutex
+	exchanges    []Exchange
+}
+
+// Open loads the fixture file of the vendor, a missing file is an empty tape. Recording stops once the tape
+// holds maxExchanges, 0 records without limit.
+func Open(dir, vendorName string, maxExchanges int) (*Tape, error) {
+	t := &Tape{path: Path(dir, vendorName), maxExchanges: maxExchanges}
+	b, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &t.exchanges); err != nil {
+		return nil, fmt.Errorf("invalid tape %s: %w", t.path, err)
+	}
+	return t, nil
+}
+
+// New returns an in-memory tape holding the exchanges, it is never written to a file
+func New(exchanges ...Exchange) *Tape {
+	return &Tape{exchanges: exchanges}
+}
+
+func Path(dir, vendorName string) string {
+	return filepath.Join(dir, vendorName+".json")
+}
+
+func (t *Tape) Exchanges() []Exchange {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]Exchange(nil), t.exchanges...)
+}
+
+// Append adds the exchange and rewrites the fixture file, it is a no-op once the tape is full
+func (t *Tape) Append(ex Exchange) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.maxExchanges > 0 && len(t.exchanges) >= t.maxExchanges {
+		return nil
+	}
+	t.exchanges = append(t.exchanges, ex)
+	if t.path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(t.exchanges, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, append(b, '\n'), 0o644)
+}
+
+// Match returns the exchange recorded for the same method and URL, or else the first one recorded for the
+// same method, host and path since query parameters such as click IDs differ between requests, or else the
+// first one recorded for the same method since a tape only holds the exchanges of one vendor endpoint,
+// whose path may still hold parameters such as the subid
+func (t *Tape) Match(req RecordedRequest) (Exchange, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, ex := range t.exchanges {
+		if ex.Request.Method == req.Method && ex.Request.URL == req.URL {
+			return ex, true
+		}
+	}
+	for _, ex := range t.exchanges {
+		if ex.Request.Method == req.Method && samePath(ex.Request.URL, req.URL) {
+			return ex, true
+		}
+	}
+	for _, ex := range t.exchanges {
+		if ex.Request.Method == req.Method {
+			return ex, true
+		}
+	}
+	return Exchange{}, false
+}
+
+func samePath(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Host == ub.Host && ua.Path == ub.Path
+}
@@ -0,0 +1,164 @@
+package tape
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubberRequest(t *testing.T) {
+	scrubber := NewScrubber("channel-token", "", "s3cr3t")
+
+	tests := []struct {
+		name string
+		req  Request
+		want RecordedRequest
+	}{
+		{
+			name: "GIVEN secrets and user IDs in the URL THEN replace them, raw and URL-encoded",
+			req: Request{
+				Method: "GET",
+				URL:    "https://vendor.test/reco?device_id=abc-def&adid=ABC-DEF&token=channel-token&email=a%2Bb%40c",
+				UserID: "ABC-def",
+			},
+			want: RecordedRequest{Method: "GET", URL: "https://vendor.test/reco?device_id=<user_id>&adid=<user_id>&token=<secret>&email=a%2Bb%40c"},
+		},
+		{
+			name: "GIVEN signature headers THEN replace them as a whole",
+			req: Request{
+				Method:  "GET",
+				URL:     "https://vendor.test/reco",
+				Headers: map[string]string{"Idfa": "u1", "S-Ca-Signature": "sig", "authorization": "CEA signature=x", "S-Ca-App": "app"},
+				UserID:  "u1",
+			},
+			want: RecordedRequest{
+				Method:  "GET",
+				URL:     "https://vendor.test/reco",
+				Headers: map[string]string{"Idfa": "<user_id>", "S-Ca-Signature": "<signature>", "authorization": "<signature>", "S-Ca-App": "app"},
+			},
+		},
+		{
+			name: "GIVEN a body THEN record it as scrubbed JSON",
+			req: Request{
+				Method: "POST",
+				URL:    "https://vendor.test/reco",
+				Body:   map[string]any{"device": map[string]string{"id": "u1"}, "key": "s3cr3t"},
+				UserID: "U1",
+			},
+			want: RecordedRequest{Method: "POST", URL: "https://vendor.test/reco", Body: json.RawMessage(`{"device":{"id":"<user_id>"},"key":"<secret>"}`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scrubber.Request(tt.req)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScrubberResponse(t *testing.T) {
+	scrubber := NewScrubber()
+
+	got := scrubber.Response(200, []byte(`{"user":"u1","data":[]}`), "u1")
+	require.Equal(t, RecordedResponse{StatusCode: 200, Body: json.RawMessage(`{"user":"<user_id>","data":[]}`)}, got)
+	require.Equal(t, []byte(`{"user":"<user_id>","data":[]}`), got.Bytes())
+
+	got = scrubber.Response(502, []byte("Bad Gateway"), "u1")
+	require.Equal(t, RecordedResponse{StatusCode: 502, BodyText: "Bad Gateway"}, got)
+	require.Equal(t, []byte("Bad Gateway"), got.Bytes())
+}
+
+func TestTape(t *testing.T) {
+	dir := t.TempDir()
+	tape, err := Open(dir, "vendor", 2)
+	require.NoError(t, err)
+	require.Empty(t, tape.Exchanges())
+
+	first := Exchange{
+		Request:  RecordedRequest{Method: "GET", URL: "https://vendor.test/reco?click_id=c1"},
+		Response: RecordedResponse{StatusCode: 200, Body: json.RawMessage(`[1]`)},
+	}
+	second := Exchange{
+		Request:  RecordedRequest{Method: "GET", URL: "https://vendor.test/reco?click_id=c2"},
+		Response: RecordedResponse{StatusCode: 200, Body: json.RawMessage(`[2]`)},
+	}
+	require.NoError(t, tape.Append(first))
+	require.NoError(t, tape.Append(second))
+	// the tape is full
+	require.NoError(t, tape.Append(first))
+
+	reopened, err := Open(dir, "vendor", 0)
+	require.NoError(t, err)
+	exchanges := reopened.Exchanges()
+	require.Len(t, exchanges, 2)
+	for i, want := range []Exchange{first, second} {
+		require.Equal(t, want.Request, exchanges[i].Request)
+		require.JSONEq(t, string(want.Response.Body), string(exchanges[i].Response.Body))
+	}
+
+	tests := []struct {
+		name    string
+		req     RecordedRequest
+		wantURL string
+		wantOK  bool
+	}{
+		{
+			name:    "GIVEN the same URL THEN return its exchange",
+			req:     RecordedRequest{Method: "GET", URL: "https://vendor.test/reco?click_id=c2"},
+			wantURL: "https://vendor.test/reco?click_id=c2",
+			wantOK:  true,
+		},
+		{
+			name:    "GIVEN other query parameters THEN return the first exchange of the path",
+			req:     RecordedRequest{Method: "GET", URL: "https://vendor.test/reco?click_id=c3"},
+			wantURL: "https://vendor.test/reco?click_id=c1",
+			wantOK:  true,
+		},
+		{
+			name:    "GIVEN another path THEN return the first exchange of the method",
+			req:     RecordedRequest{Method: "GET", URL: "https://vendor.test/reco/other-subid"},
+			wantURL: "https://vendor.test/reco?click_id=c1",
+			wantOK:  true,
+		},
+		{
+			name: "GIVEN another method THEN return no exchange",
+			req:  RecordedRequest{Method: "POST", URL: "https://vendor.test/reco?click_id=c1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := reopened.Match(tt.req)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantURL, got.Request.URL)
+		})
+	}
+}
+
+func TestOpenInvalidTape(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(Path(dir, "vendor"), []byte("not json"), 0o644))
+
+	_, err := Open(dir, "vendor", 0)
+	require.Error(t, err)
+}
+
+func TestRequestContext(t *testing.T) {
+	_, ok := RequestFromContext(context.Background())
+	require.False(t, ok)
+
+	req := Request{Method: "GET", URL: "https://vendor.test/reco", UserID: "u1"}
+	got, ok := RequestFromContext(WithRequest(context.Background(), req))
+	require.True(t, ok)
+	require.Equal(t, req, got)
+}
+
+func TestInMemoryTape(t *testing.T) {
+	ex := Exchange{Request: RecordedRequest{Method: "GET", URL: "https://vendor.test/reco"}, Response: RecordedResponse{StatusCode: 200}}
+	tape := New(ex)
+	require.NoError(t, tape.Append(ex))
+	require.Equal(t, []Exchange{ex, ex}, tape.Exchanges())
+}
@@ -13,6 +13,8 @@ type RequestInfo struct {
 	BidObjID   string
 	ReqID      string
 	MethodName string
+	// Caller is the authenticated caller, empty if the authentication is disabled or the path exempt
+	Caller string
 }
 
 type reqInfoKey struct{}
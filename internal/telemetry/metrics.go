@@ -21,6 +21,24 @@ type PromMetrics struct {
 	RestApiDurationSeconds *prometheus.HistogramVec
 	RestApiErrorTotal      *prometheus.CounterVec
 	RestApiAnomalyTotal    *prometheus.CounterVec
+
+	PostProcessDroppedTotal *prometheus.CounterVec
+	BlocklistFilteredTotal  *prometheus.CounterVec
+	URLRejectedTotal        *prometheus.CounterVec
+
+	ClickTotal      *prometheus.CounterVec
+	ImpressionTotal *prometheus.CounterVec
+
+	ConversionTotal        *prometheus.CounterVec
+	ConversionRevenueTotal *prometheus.CounterVec
+
+	EventDroppedTotal *prometheus.CounterVec
+
+	StoreEvictedTotal *prometheus.CounterVec
+
+	HeaderDroppedTotal *prometheus.CounterVec
+
+	AuthTotal *prometheus.CounterVec
 }
 
 func NewPromMetrics() PromMetrics {
@@ -47,6 +65,84 @@ func NewPromMetrics() PromMetrics {
 			Help:      "Anomaly count when calling Rest API",
 		}, []string{"vendor", "site", "oid", "reason"},
 	)
+	m.PostProcessDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "post_process_dropped_total",
+			Help:      "Count of vendor products dropped by post-processing stages",
+		}, []string{"vendor", "stage"},
+	)
+	m.BlocklistFilteredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "blocklist_filtered_total",
+			Help:      "Count of vendor products filtered by the brand-safety blocklists",
+		}, []string{"vendor", "site", "reason"},
+	)
+	m.URLRejectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "url_rejected_total",
+			Help:      "Count of vendor products dropped for a product or image URL refused by the URL policy",
+		}, []string{"vendor", "field", "reason"},
+	)
+	m.ClickTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "click_total",
+			Help:      "Count of first-party click redirects",
+		}, []string{"vendor", "status"},
+	)
+	// no product label, the product IDs are unbounded, the impression events count the products instead
+	m.ImpressionTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "impression_total",
+			Help:      "Count of impression beacons",
+		}, []string{"vendor", "site", "status"},
+	)
+	m.ConversionTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "conversion_total",
+			Help:      "Count of vendor conversion postbacks",
+		}, []string{"vendor", "status"},
+	)
+	m.ConversionRevenueTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "conversion_revenue_total",
+			Help:      "Revenue of the accepted vendor conversion postbacks",
+		}, []string{"vendor", "currency"},
+	)
+	m.EventDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "event_dropped_total",
+			Help:      "Count of events dropped before reaching the event sink",
+		}, []string{"type", "reason"},
+	)
+	m.StoreEvictedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "store_evicted_total",
+			Help:      "Count of entries evicted from a full memory store before they expired",
+		}, []string{"store"},
+	)
+	m.HeaderDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "header_dropped_total",
+			Help:      "Count of gRPC response header metadata left out for exceeding the header budget",
+		}, []string{"key"},
+	)
+	m.AuthTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: systemName,
+			Name:      "auth_total",
+			Help:      "Count of authenticated requests by caller",
+		}, []string{"caller", "status"},
+	)
 	return m
 }
 
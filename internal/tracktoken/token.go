@@ -0,0 +1,96 @@
+// Package tracktoken encodes first-party tracking data into compact signed URL tokens
+package tracktoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// signatureSize truncates the HMAC-SHA256 signature to keep the tokens short
+const signatureSize = 16
+
+var (
+	ErrMalformed        = errors.New("malformed token")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrExpired          = errors.New("token expired")
+)
+
+// Claims is the tracking data carried by a token, the short JSON keys keep the tokens compact
+type Claims struct {
+	Vendor    string `json:"v"`
+	ClickID   string `json:"c"`
+	UserID    string `json:"u"`
+	ProductID string `json:"p"`
+	SiteID    string `json:"s,omitempty"`
+	URL       string `json:"l,omitempty"`
+	IssuedAt  int64  `json:"t"`
+	// Sealed reports whether the ClickID is sealed, to be opened by the click sealer
+	Sealed bool `json:"x,omitempty"`
+}
+
+type Signer struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewSigner returns a Signer using key for HMAC-SHA256, tokens older than ttl are rejected unless ttl is 0
+func NewSigner(key string, ttl time.Duration) *Signer {
+	return &Signer{key: []byte(key), ttl: ttl, now: time.Now}
+}
+
+// Sign returns the token "<base64url payload>.<base64url signature>" of the claims, IssuedAt is set to now
+func (s *Signer) Sign(claims Claims) (string, error) {
+	claims.IssuedAt = s.now().Unix()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// Verify checks the signature and age of the token and returns its claims
+func (s *Signer) Verify(token string) (Claims, error) {
+	encoded, sig, found := strings.Cut(token, ".")
+	if !found {
+		return Claims{}, ErrMalformed
+	}
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if !hmac.Equal(gotSig, s.sign(encoded)) {
+		return Claims{}, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if s.ttl > 0 && s.now().Sub(time.Unix(claims.IssuedAt, 0)) > s.ttl {
+		return Claims{}, ErrExpired
+	}
+	return claims, nil
+}
+
+// ID identifies a verified token by its signature, which is shorter than the token
+func ID(token string) string {
+	_, sig, _ := strings.Cut(token, ".")
+	return sig
+}
+
+func (s *Signer) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)[:signatureSize]
+}
@@ -0,0 +1,89 @@
+package tracktoken
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	claims := Claims{Vendor: "linkmine", ClickID: "click-1", UserID: "user-1", ProductID: "p1", URL: "https://vendor.com/trk?id=1"}
+	issuedAt := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		tamper  func(token string) string
+		now     time.Time
+		signer  *Signer
+		wantErr error
+	}{
+		{
+			name:   "GIVEN a valid token THEN expect the original claims",
+			tamper: func(token string) string { return token },
+			now:    issuedAt.Add(time.Minute),
+		},
+		{
+			name:    "GIVEN a token without signature THEN expect a malformed error",
+			tamper:  func(token string) string { return token[:len(token)-23] },
+			now:     issuedAt,
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "GIVEN a tampered payload THEN expect an invalid signature error",
+			tamper:  func(token string) string { return "x" + token },
+			now:     issuedAt,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "GIVEN a token signed with another key THEN expect an invalid signature error",
+			tamper:  func(token string) string { return token },
+			now:     issuedAt,
+			signer:  NewSigner("other-key", time.Hour),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "GIVEN an expired token THEN expect an expired error",
+			tamper:  func(token string) string { return token },
+			now:     issuedAt.Add(2 * time.Hour),
+			wantErr: ErrExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := NewSigner("secret", time.Hour)
+			signer.now = func() time.Time { return issuedAt }
+			token, err := signer.Sign(claims)
+			require.NoError(t, err)
+
+			verifier := signer
+			if tt.signer != nil {
+				verifier = tt.signer
+			}
+			verifier.now = func() time.Time { return tt.now }
+
+			got, err := verifier.Verify(tt.tamper(token))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			want := claims
+			want.IssuedAt = issuedAt.Unix()
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestID(t *testing.T) {
+	signer := NewSigner("secret", 0)
+	token, err := signer.Sign(Claims{Vendor: "linkmine", ProductID: "p1"})
+	require.NoError(t, err)
+	other, err := signer.Sign(Claims{Vendor: "linkmine", ProductID: "p2"})
+	require.NoError(t, err)
+
+	require.Len(t, ID(token), 22)
+	require.True(t, strings.HasSuffix(token, "."+ID(token)))
+	require.NotEqual(t, ID(token), ID(other))
+}
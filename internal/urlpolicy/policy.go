@@ -0,0 +1,152 @@
+// Package urlpolicy checks the vendor product and image URLs before they are handed to the clients, against
+// the allowlist of the vendor and a global denylist of internal hosts
+package urlpolicy
+
+import (
+	"fmt"
+	"net/netip"
+	urlpkg "net/url"
+	"slices"
+	"strings"
+)
+
+// Reasons of a refused URL
+const (
+	ReasonInvalid      = "invalid"
+	ReasonScheme       = "scheme"
+	ReasonPrivateIP    = "private_ip"
+	ReasonDeniedDomain = "denied_domain"
+	ReasonDomain       = "domain"
+)
+
+var (
+	// defaultSchemes are the schemes allowed without a vendor allowlist, which can restrict them or allow the
+	// app schemes of deep links
+	defaultSchemes = []string{"http", "https"}
+	// deniedSchemes run code or read local content in the clients, they are refused even if allowed
+	deniedSchemes = []string{"javascript", "vbscript", "data", "file", "blob", "about"}
+
+	// defaultDeniedPrefixes are the private, loopback, link-local and unspecified ranges
+	defaultDeniedPrefixes = []string{
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+		"192.168.0.0/16", "::/128", "::1/128", "fc00::/7", "fe80::/10",
+	}
+	defaultDeniedDomains = []string{"localhost"}
+)
+
+// Rule is the allowlist of a URL field of a vendor, empty lists allow http and https, and any domain.
+// The domains only apply to the http and https URLs, the host of a deep link is defined by its app.
+type Rule struct {
+	Schemes []string
+	Domains []string
+}
+
+// Policy refuses the URLs of the denylist, and those not allowed by the rule of their field
+type Policy struct {
+	deniedPrefixes []netip.Prefix
+	deniedDomains  []string
+}
+
+// NewPolicy returns the policy denying the CIDRs and domains on top of the defaults
+func NewPolicy(cidrs, domains []string) (*Policy, error) {
+	p := &Policy{deniedDomains: append(normalizeDomains(defaultDeniedDomains), normalizeDomains(domains)...)}
+	for _, cidr := range append(slices.Clone(defaultDeniedPrefixes), cidrs...) {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url denylist CIDR %s: %w", cidr, err)
+		}
+		p.deniedPrefixes = append(p.deniedPrefixes, prefix.Masked())
+	}
+	return p, nil
+}
+
+// Check returns the reason the URL is refused, empty if it is allowed
+func (p *Policy) Check(rawURL string, rule Rule) string {
+	parsedURL, err := urlpkg.Parse(rawURL)
+	if err != nil || parsedURL.Scheme == "" {
+		return ReasonInvalid
+	}
+
+	scheme := strings.ToLower(parsedURL.Scheme)
+	allowed := defaultSchemes
+	if len(rule.Schemes) > 0 {
+		allowed = rule.Schemes
+	}
+	if slices.Contains(deniedSchemes, scheme) || !slices.Contains(allowed, scheme) {
+		return ReasonScheme
+	}
+	if !slices.Contains(defaultSchemes, scheme) {
+		// a deep link, such as app://product/1 or app:product/1
+		if parsedURL.Host == "" && parsedURL.Opaque == "" && parsedURL.Path == "" {
+			return ReasonInvalid
+		}
+		return ""
+	}
+	if parsedURL.Host == "" {
+		return ReasonInvalid
+	}
+
+	host := normalizeDomain(parsedURL.Hostname())
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap()
+		for _, prefix := range p.deniedPrefixes {
+			if prefix.Contains(addr) {
+				return ReasonPrivateIP
+			}
+		}
+	} else if isNumericHost(host) {
+		// IPv4 shorthands, such as 2130706433 or 0x7f.1, are resolved by the browsers
+		return ReasonPrivateIP
+	}
+
+	if matchDomain(host, p.deniedDomains) {
+		return ReasonDeniedDomain
+	}
+	if len(rule.Domains) > 0 && !matchDomain(host, normalizeDomains(rule.Domains)) {
+		return ReasonDomain
+	}
+	return ""
+}
+
+// WebSchemes returns the http and https schemes among the schemes, the image URLs are never deep links
+func WebSchemes(schemes []string) []string {
+	var web []string
+	for _, s := range schemes {
+		if slices.Contains(defaultSchemes, s) {
+			web = append(web, s)
+		}
+	}
+	return web
+}
+
+// isNumericHost reports whether the host is not a domain, since a top-level domain is never numeric
+func isNumericHost(host string) bool {
+	labels := strings.Split(host, ".")
+	last := labels[len(labels)-1]
+	if strings.HasPrefix(last, "0x") {
+		return true
+	}
+	return last != "" && strings.Trim(last, "0123456789") == ""
+}
+
+// matchDomain reports whether the host is one of the domains or their subdomains
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDomains(domains []string) []string {
+	res := make([]string, 0, len(domains))
+	for _, d := range domains {
+		res = append(res, normalizeDomain(d))
+	}
+	return res
+}
+
+func normalizeDomain(d string) string {
+	return strings.TrimSuffix(strings.ToLower(d), ".")
+}
@@ -0,0 +1,136 @@
+package urlpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	p, err := NewPolicy([]string{"203.0.113.0/24"}, []string{"Internal.Example.com"})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name string
+		url  string
+		rule Rule
+		want string
+	}{
+		{
+			name: "GIVEN a public URL and no rule THEN expect it allowed",
+			url:  "https://shop.com/p/1?utm=a",
+		},
+		{
+			name: "GIVEN a relative or unparsable URL THEN expect it invalid",
+			url:  "/p/1",
+			want: ReasonInvalid,
+		},
+		{
+			name: "GIVEN a javascript URL THEN expect the scheme refused",
+			url:  "javascript://shop.com/%0aalert(1)",
+			want: ReasonScheme,
+		},
+		{
+			name: "GIVEN an http URL and an https only rule THEN expect the scheme refused",
+			url:  "http://shop.com/p/1",
+			rule: Rule{Schemes: []string{"https"}},
+			want: ReasonScheme,
+		},
+		{
+			name: "GIVEN a deep link without rule THEN expect the scheme refused",
+			url:  "keeta://product/1",
+			want: ReasonScheme,
+		},
+		{
+			name: "GIVEN a deep link of an allowed app scheme THEN expect it allowed regardless of the domains",
+			url:  "keeta://product/1?from=rec",
+			rule: Rule{Schemes: []string{"https", "keeta"}, Domains: []string{"shop.com"}},
+		},
+		{
+			name: "GIVEN an opaque deep link of an allowed app scheme THEN expect it allowed",
+			url:  "keeta:product/1",
+			rule: Rule{Schemes: []string{"keeta"}},
+		},
+		{
+			name: "GIVEN an empty deep link THEN expect it invalid",
+			url:  "keeta:",
+			rule: Rule{Schemes: []string{"keeta"}},
+			want: ReasonInvalid,
+		},
+		{
+			name: "GIVEN an https URL and an app scheme only rule THEN expect the scheme refused",
+			url:  "https://shop.com/p/1",
+			rule: Rule{Schemes: []string{"keeta"}},
+			want: ReasonScheme,
+		},
+		{
+			name: "GIVEN a javascript URL allowed by the rule THEN expect the scheme still refused",
+			url:  "javascript:alert(1)",
+			rule: Rule{Schemes: []string{"javascript"}},
+			want: ReasonScheme,
+		},
+		{
+			name: "GIVEN a private IP THEN expect it refused",
+			url:  "http://10.1.2.3/admin",
+			want: ReasonPrivateIP,
+		},
+		{
+			name: "GIVEN the metadata endpoint THEN expect it refused",
+			url:  "http://169.254.169.254/latest/meta-data",
+			want: ReasonPrivateIP,
+		},
+		{
+			name: "GIVEN an IPv4-mapped loopback THEN expect it refused",
+			url:  "http://[::ffff:127.0.0.1]:8080/",
+			want: ReasonPrivateIP,
+		},
+		{
+			name: "GIVEN a decimal IPv4 shorthand THEN expect it refused",
+			url:  "http://2130706433/",
+			want: ReasonPrivateIP,
+		},
+		{
+			name: "GIVEN a configured CIDR THEN expect it refused",
+			url:  "https://203.0.113.7/p/1",
+			want: ReasonPrivateIP,
+		},
+		{
+			name: "GIVEN localhost THEN expect the domain refused",
+			url:  "http://LOCALHOST.:8080/",
+			want: ReasonDeniedDomain,
+		},
+		{
+			name: "GIVEN a subdomain of a configured domain THEN expect it refused",
+			url:  "https://api.internal.example.com/",
+			want: ReasonDeniedDomain,
+		},
+		{
+			name: "GIVEN a subdomain of an allowed domain THEN expect it allowed",
+			url:  "https://link.shop.com/p/1",
+			rule: Rule{Domains: []string{"shop.com"}},
+		},
+		{
+			name: "GIVEN a lookalike of an allowed domain THEN expect the domain refused",
+			url:  "https://evilshop.com/p/1",
+			rule: Rule{Domains: []string{"shop.com"}},
+			want: ReasonDomain,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, p.Check(tc.url, tc.rule))
+		})
+	}
+}
+
+func TestNewPolicyInvalidCIDR(t *testing.T) {
+	_, err := NewPolicy([]string{"10.0.0.0"}, nil)
+	require.Error(t, err)
+}
+
+func TestWebSchemes(t *testing.T) {
+	require.Equal(t, []string{"https"}, WebSchemes([]string{"https", "keeta"}))
+	require.Empty(t, WebSchemes([]string{"keeta"}))
+	require.Empty(t, WebSchemes(nil))
+}